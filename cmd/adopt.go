@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/metal-toolbox/auditevent"
+	"github.com/spf13/cobra"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/auctx"
+	"github.com/metal-toolbox/gov-slack-addon/internal/configs"
+	"github.com/metal-toolbox/gov-slack-addon/internal/reconciler"
+	"github.com/metal-toolbox/gov-slack-addon/internal/slack"
+)
+
+// adoptCmd binds an existing slack user group to a governor group
+var adoptCmd = &cobra.Command{
+	Use:   "adopt",
+	Short: "brings an existing slack user group under management for a governor group",
+	Long: `Binds an existing, manually created, slack user group to a governor group. The membership
+changes are shown before asking for confirmation, then the user group is renamed to the managed
+naming scheme and its members are synced with the governor group.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return adopt(cmd.Context(), cmd.InOrStdin(), cmd.OutOrStdout())
+	},
+}
+
+var (
+	adoptGroup      string
+	adoptWorkspace  string
+	adoptUserGroup  string
+	adoptKeepHandle bool
+	adoptYes        bool
+)
+
+func init() {
+	rootCmd.AddCommand(adoptCmd)
+
+	flags := adoptCmd.Flags()
+
+	flags.StringVar(&adoptGroup, "group", "", "governor group id")
+	flags.StringVar(&adoptWorkspace, "workspace", "", "slack workspace name")
+	flags.StringVar(&adoptUserGroup, "usergroup", "", "slack user group id or handle")
+	flags.BoolVar(&adoptKeepHandle, "keep-handle", false, "keep the user group handle instead of renaming it")
+	flags.BoolVarP(&adoptYes, "yes", "y", false, "don't ask for confirmation")
+
+	for _, f := range []string{"group", "workspace", "usergroup"} {
+		if err := adoptCmd.MarkFlagRequired(f); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func adopt(ctx context.Context, in io.Reader, out io.Writer) error {
+	if err := validateClientFlags(); err != nil {
+		return err
+	}
+
+	gc, err := newGovernorClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	sc := newSlackClient()
+
+	ir, err := newIdentityResolver(sc, gc, nil)
+	if err != nil {
+		return err
+	}
+
+	namer, err := newUserGroupNamer()
+	if err != nil {
+		return err
+	}
+
+	st, closeStore, err := newCommandStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer closeStore()
+
+	aw, closeAudit, err := newCommandAuditWriter()
+	if err != nil {
+		return err
+	}
+
+	defer closeAudit()
+
+	rec := reconciler.New(
+		reconciler.WithAuditEventWriter(aw),
+		reconciler.WithClient(sc),
+		reconciler.WithGovernorClient(gc, nil),
+		reconciler.WithIdentityResolver(ir),
+		reconciler.WithUserGroupNamer(namer),
+		reconciler.WithStore(st),
+		reconciler.WithLogger(logger.Desugar()),
+		reconciler.WithUserGroupPrefix(configs.AppConfig.Slack.UsergroupPrefix),
+		reconciler.WithOrgID(configs.AppConfig.Slack.OrgID),
+		reconciler.WithApplicationType(configs.AppConfig.Governor.ApplicationType),
+		reconciler.WithDryRun(configs.AppConfig.DryRun),
+	)
+
+	apps, err := rec.SlackApplications(ctx)
+	if err != nil {
+		return err
+	}
+
+	appID := ""
+
+	for _, app := range apps {
+		if app.Name == adoptWorkspace {
+			appID = app.ID
+			break
+		}
+	}
+
+	if appID == "" {
+		return fmt.Errorf("%w: %s", ErrUnknownWorkspace, adoptWorkspace)
+	}
+
+	a, err := rec.PlanAdoption(ctx, adoptGroup, appID, adoptUserGroup)
+	if err != nil {
+		return err
+	}
+
+	a.KeepHandle = adoptKeepHandle
+
+	printAdoption(ctx, out, sc, a)
+
+	if !adoptYes && !confirm(in, out, "Adopt this user group?") {
+		fmt.Fprintln(out, "aborted")
+		return nil
+	}
+
+	ctx = auctx.WithAuditEvent(ctx, auditevent.NewAuditEvent(
+		"", // eventType to be populated later
+		auditevent.EventSource{
+			Type:  "local",
+			Value: "AdoptCommand",
+		},
+		auditevent.OutcomeSucceeded,
+		map[string]string{
+			"event": "adopt",
+		},
+		"gov-slack-addon",
+	))
+
+	return rec.Adopt(ctx, a)
+}
+
+// printAdoption prints the changes the adoption will make to the user group
+func printAdoption(ctx context.Context, out io.Writer, sc *slack.Client, a *reconciler.Adoption) {
+	fmt.Fprintf(out, "Slack user group %s in %s, for governor group %s\n\n", a.UserGroup.ID, a.Workspace, a.GovernorGroupSlug)
+	fmt.Fprintf(out, "  name:        %q -> %q\n", a.UserGroup.Name, a.Name)
+
+	if !a.KeepHandle {
+		fmt.Fprintf(out, "  handle:      %q -> %q\n", a.UserGroup.Handle, a.Handle)
+	}
+
+	fmt.Fprintf(out, "  description: %q -> %q\n", a.UserGroup.Description, a.Description)
+
+	fmt.Fprintf(out, "\nMembers to add (%d):\n", len(a.Add))
+
+	for _, id := range a.Add {
+		fmt.Fprintf(out, "  + %s\n", slackUserLabel(ctx, sc, id))
+	}
+
+	fmt.Fprintf(out, "\nMembers to remove (%d):\n", len(a.Remove))
+
+	for _, id := range a.Remove {
+		fmt.Fprintf(out, "  - %s\n", slackUserLabel(ctx, sc, id))
+	}
+
+	if len(a.Unmatched) > 0 {
+		fmt.Fprintf(out, "\nGovernor members that can't be added (%d):\n", len(a.Unmatched))
+
+		for _, m := range a.Unmatched {
+			fmt.Fprintf(out, "  ! %s: %s\n", m.Email, m.Reason)
+		}
+	}
+
+	fmt.Fprintln(out)
+}
+
+// slackUserLabel returns a readable label for the slack user id
+func slackUserLabel(ctx context.Context, sc *slack.Client, id string) string {
+	u, err := sc.GetUser(ctx, id)
+	if err != nil || u.Profile.Email == "" {
+		return id
+	}
+
+	return fmt.Sprintf("%s (%s)", u.Profile.Email, id)
+}
+
+// confirm asks the question and returns true if the answer is yes
+func confirm(in io.Reader, out io.Writer, question string) bool {
+	fmt.Fprintf(out, "%s [y/N] ", question)
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// newCommandAuditWriter returns an audit event writer for the one-off commands, writing to the
+// configured audit log file if any. Unlike serve, it doesn't wait for the file to exist.
+func newCommandAuditWriter() (*auditevent.EventWriter, func(), error) {
+	if configs.AppConfig.Audit.LogPath == "" {
+		logger.Warn("no audit log path configured, audit events will be discarded")
+		return auditevent.NewDefaultAuditEventWriter(io.Discard), func() {}, nil
+	}
+
+	f, err := os.OpenFile(configs.AppConfig.Audit.LogPath, os.O_APPEND|os.O_WRONLY, 0) //nolint:gosec
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return auditevent.NewDefaultAuditEventWriter(f), func() { f.Close() }, nil
+}
@@ -19,4 +19,24 @@ var (
 	ErrGovernorClientAudienceRequired = errors.New("governor oauth client audience is required and cannot be empty")
 	// ErrSlackTokenRequired is returned when a slack token is missing
 	ErrSlackTokenRequired = errors.New("slack token is required and cannot be empty")
+	// ErrIdentityProfileFieldRequired is returned when the profile-field resolver is enabled without a field id
+	ErrIdentityProfileFieldRequired = errors.New("identity profile field id is required for the profile-field resolver")
+	// ErrUnknownGovernorAttribute is returned when an unsupported governor user attribute is configured
+	ErrUnknownGovernorAttribute = errors.New("unknown governor user attribute")
+	// ErrUnknownWorkspace is returned when no slack application is found for a workspace name
+	ErrUnknownWorkspace = errors.New("no slack application found for workspace")
+	// ErrUnknownOutputFormat is returned when an unsupported output format is requested
+	ErrUnknownOutputFormat = errors.New("unknown output format")
+	// ErrInvalidFlag is returned when a flag has an invalid value
+	ErrInvalidFlag = errors.New("invalid flag value")
+	// ErrSamePrefix is returned when migrating from the currently configured user group prefix
+	ErrSamePrefix = errors.New("old prefix is the same as the configured user group prefix")
+	// ErrMigrationFailed is returned when some user groups failed to migrate
+	ErrMigrationFailed = errors.New("failed to migrate")
+	// ErrUnknownAuditSink is returned when an unsupported audit sink is configured
+	ErrUnknownAuditSink = errors.New("unknown audit sink")
+	// ErrAuditLogPathRequired is returned when the file audit sink is enabled without an audit log path
+	ErrAuditLogPathRequired = errors.New("audit log path is required for the file audit sink")
+	// ErrAuditWebhookURLRequired is returned when the webhook audit sink is enabled without a url
+	ErrAuditWebhookURLRequired = errors.New("audit webhook url is required for the webhook audit sink")
 )
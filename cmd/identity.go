@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+
+	governor "github.com/metal-toolbox/governor-api/pkg/client"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/configs"
+	"github.com/metal-toolbox/gov-slack-addon/internal/identity"
+	"github.com/metal-toolbox/gov-slack-addon/internal/slack"
+)
+
+// newIdentityResolver builds the chain of identity resolvers from the app config. The
+// override bucket is only used when a NATS connection is available.
+func newIdentityResolver(sc *slack.Client, gc *governor.Client, nc *nats.Conn) (identity.Resolver, error) {
+	cfg := configs.AppConfig.Identity
+
+	resolvers := []identity.Resolver{}
+
+	for _, name := range cfg.Resolvers {
+		switch name {
+		case identity.ResolverEmail:
+			resolvers = append(resolvers, identity.NewEmailResolver(sc))
+
+		case identity.ResolverOverride:
+			opts := []identity.OverrideOption{}
+
+			if cfg.OverrideBucket != "" {
+				if nc == nil {
+					logger.Warnw("no NATS connection, identity override bucket will not be used", "bucket", cfg.OverrideBucket)
+				} else {
+					kv, err := keyValueBucket(nc, cfg.OverrideBucket)
+					if err != nil {
+						return nil, err
+					}
+
+					opts = append(opts, identity.WithOverrideKeyValue(kv))
+				}
+			}
+
+			resolvers = append(resolvers, identity.NewOverrideResolver(sc, cfg.Overrides, opts...))
+
+		case identity.ResolverRewrite:
+			resolvers = append(resolvers, identity.NewRewriteResolver(sc, cfg.Rewrite.Domains, cfg.Rewrite.StripPlus))
+
+		case identity.ResolverProfileField:
+			if cfg.ProfileField.ID == "" {
+				return nil, ErrIdentityProfileFieldRequired
+			}
+
+			attr, err := governorAttribute(gc, cfg.ProfileField.GovernorAttribute)
+			if err != nil {
+				return nil, err
+			}
+
+			resolvers = append(resolvers, identity.NewProfileFieldResolver(sc, cfg.ProfileField.ID, attr, cfg.ProfileField.Refresh))
+
+		default:
+			return nil, fmt.Errorf("%w: %s", identity.ErrUnknownResolver, name)
+		}
+	}
+
+	if len(resolvers) == 0 {
+		resolvers = append(resolvers, identity.NewEmailResolver(sc))
+	}
+
+	return identity.NewCache(identity.NewChain(logger.Desugar(), resolvers...), cfg.CacheTTL), nil
+}
+
+// governorAttribute returns the function to read the named governor user attribute
+func governorAttribute(gc *governor.Client, name string) (identity.AttributeFunc, error) {
+	switch name {
+	case "id":
+		return identity.GovernorUserID, nil
+	case "email-local-part":
+		return identity.EmailLocalPart, nil
+	case "external-id":
+		return func(ctx context.Context, u identity.User) (string, error) {
+			user, err := gc.User(ctx, u.ID, false)
+			if err != nil {
+				return "", err
+			}
+
+			return user.ExternalID.String, nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownGovernorAttribute, name)
+	}
+}
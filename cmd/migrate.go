@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/metal-toolbox/auditevent"
+	"github.com/spf13/cobra"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/auctx"
+	"github.com/metal-toolbox/gov-slack-addon/internal/configs"
+	"github.com/metal-toolbox/gov-slack-addon/internal/reconciler"
+)
+
+// migratePrefixCmd renames the slack user groups after a change of the user group prefix
+var migratePrefixCmd = &cobra.Command{
+	Use:   "migrate-prefix",
+	Short: "renames the managed slack user groups after a change of the user group prefix",
+	Long: `Finds the slack user groups named with the old user group prefix, or recorded in the
+user group mappings, and renames them to the current naming scheme, so the addon doesn't create
+duplicates. Renames are throttled and the progress is saved to the checkpoint file, if any, so an
+interrupted migration can be resumed. Use --dry-run to only print the renames.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return migratePrefix(cmd.Context(), cmd.OutOrStdout())
+	},
+}
+
+var (
+	migrateOldPrefix  string
+	migrateWorkspace  string
+	migrateRate       int
+	migrateCheckpoint string
+)
+
+func init() {
+	rootCmd.AddCommand(migratePrefixCmd)
+
+	flags := migratePrefixCmd.Flags()
+
+	flags.StringVar(&migrateOldPrefix, "old-prefix", "", "the user group prefix the user groups were created with")
+	flags.StringVar(&migrateWorkspace, "workspace", "", "only migrate the user groups of the given slack workspace name")
+	flags.IntVar(&migrateRate, "rate", 20, "maximum number of user groups renamed per minute") //nolint:mnd
+	flags.StringVar(&migrateCheckpoint, "checkpoint", "", "file recording the migrated user groups, used to resume an interrupted migration")
+
+	if err := migratePrefixCmd.MarkFlagRequired("old-prefix"); err != nil {
+		panic(err)
+	}
+}
+
+func migratePrefix(ctx context.Context, out io.Writer) error {
+	if err := validateClientFlags(); err != nil {
+		return err
+	}
+
+	if migrateOldPrefix == configs.AppConfig.Slack.UsergroupPrefix {
+		return ErrSamePrefix
+	}
+
+	if migrateRate <= 0 {
+		return fmt.Errorf("%w: rate must be positive", ErrInvalidFlag)
+	}
+
+	done, err := readCheckpoint(migrateCheckpoint)
+	if err != nil {
+		return err
+	}
+
+	gc, err := newGovernorClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	sc := newSlackClient()
+
+	namer, err := newUserGroupNamer()
+	if err != nil {
+		return err
+	}
+
+	st, closeStore, err := newCommandStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer closeStore()
+
+	aw, closeAudit, err := newCommandAuditWriter()
+	if err != nil {
+		return err
+	}
+
+	defer closeAudit()
+
+	rec := reconciler.New(
+		reconciler.WithAuditEventWriter(aw),
+		reconciler.WithClient(sc),
+		reconciler.WithGovernorClient(gc, nil),
+		reconciler.WithUserGroupNamer(namer),
+		reconciler.WithStore(st),
+		reconciler.WithLogger(logger.Desugar()),
+		reconciler.WithUserGroupPrefix(configs.AppConfig.Slack.UsergroupPrefix),
+		reconciler.WithOrgID(configs.AppConfig.Slack.OrgID),
+		reconciler.WithApplicationType(configs.AppConfig.Governor.ApplicationType),
+		reconciler.WithDryRun(configs.AppConfig.DryRun),
+	)
+
+	ctx = auctx.WithAuditEvent(ctx, auditevent.NewAuditEvent(
+		"", // eventType to be populated later
+		auditevent.EventSource{
+			Type:  "local",
+			Value: "MigratePrefixCommand",
+		},
+		auditevent.OutcomeSucceeded,
+		map[string]string{
+			"event": "migrate-prefix",
+		},
+		"gov-slack-addon",
+	))
+
+	apps, err := rec.SlackApplications(ctx)
+	if err != nil {
+		return err
+	}
+
+	throttle := time.NewTicker(time.Minute / time.Duration(migrateRate))
+	defer throttle.Stop()
+
+	var renamed, skipped, failed int
+
+	for _, app := range apps {
+		if migrateWorkspace != "" && app.Name != migrateWorkspace {
+			continue
+		}
+
+		groups, err := gc.ApplicationGroups(ctx, app.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, g := range groups {
+			key := app.ID + "/" + g.ID
+
+			if done[key] {
+				continue
+			}
+
+			m, err := rec.MigrateUserGroupName(ctx, g.ID, app.ID, migrateOldPrefix)
+			if err != nil {
+				if errors.Is(err, reconciler.ErrSlackUserGroupNotFound) {
+					fmt.Fprintf(out, "%s\t%s\tnot found, skipped\n", app.Name, g.Slug)
+
+					skipped++
+
+					continue
+				}
+
+				logger.Warnw("failed to migrate user group", "governor.group.slug", g.Slug, "slack.workspace.name", app.Name, "error", err)
+				fmt.Fprintf(out, "%s\t%s\tfailed: %s\n", app.Name, g.Slug, err)
+
+				failed++
+
+				continue
+			}
+
+			if m == nil {
+				continue
+			}
+
+			if !m.Renamed {
+				fmt.Fprintf(out, "%s\t%s\t%q up to date\n", app.Name, g.Slug, m.NewName)
+			} else {
+				renamed++
+
+				if configs.AppConfig.DryRun {
+					fmt.Fprintf(out, "%s\t%s\twould rename %q to %q\n", app.Name, g.Slug, m.OldName, m.NewName)
+					continue
+				}
+
+				fmt.Fprintf(out, "%s\t%s\trenamed %q to %q\n", app.Name, g.Slug, m.OldName, m.NewName)
+			}
+
+			done[key] = true
+
+			if err := writeCheckpoint(migrateCheckpoint, done); err != nil {
+				return err
+			}
+
+			if m.Renamed {
+				select {
+				case <-throttle.C:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+	}
+
+	fmt.Fprintf(out, "\n%d renamed, %d not found, %d failed\n", renamed, skipped, failed)
+
+	if failed > 0 {
+		return fmt.Errorf("%w: %d user groups", ErrMigrationFailed, failed)
+	}
+
+	return nil
+}
+
+// readCheckpoint returns the keys of the user groups already migrated, from the checkpoint file if any
+func readCheckpoint(path string) (map[string]bool, error) {
+	done := map[string]bool{}
+
+	if path == "" {
+		return done, nil
+	}
+
+	b, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return done, nil
+		}
+
+		return nil, err
+	}
+
+	keys := []string{}
+
+	if err := json.Unmarshal(b, &keys); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint file %s: %w", path, err)
+	}
+
+	for _, k := range keys {
+		done[k] = true
+	}
+
+	return done, nil
+}
+
+// writeCheckpoint saves the keys of the user groups already migrated to the checkpoint file if any
+func writeCheckpoint(path string, done map[string]bool) error {
+	if path == "" {
+		return nil
+	}
+
+	keys := make([]string, 0, len(done))
+
+	for k := range done {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	b, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+
+	if err := os.WriteFile(tmp, b, 0o600); err != nil { //nolint:mnd
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
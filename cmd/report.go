@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/configs"
+	"github.com/metal-toolbox/gov-slack-addon/internal/reconciler"
+)
+
+// reportCmd groups the reporting commands
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "reports on the state of governor groups in slack",
+}
+
+// reportUnmatchedCmd reports the governor group members that cannot be matched to slack users
+var reportUnmatchedCmd = &cobra.Command{
+	Use:   "unmatched",
+	Short: "lists governor group members that cannot be matched to slack users",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return reportUnmatched(cmd.Context())
+	},
+}
+
+var (
+	reportWorkspace string
+	reportOutput    string
+)
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.AddCommand(reportUnmatchedCmd)
+
+	flags := reportUnmatchedCmd.Flags()
+
+	flags.StringVar(&reportWorkspace, "workspace", "", "only report on the given slack workspace name")
+	flags.StringVarP(&reportOutput, "output", "o", "text", "output format, one of: text, json")
+}
+
+func reportUnmatched(ctx context.Context) error {
+	if err := validateClientFlags(); err != nil {
+		return err
+	}
+
+	if reportOutput != "text" && reportOutput != "json" {
+		return fmt.Errorf("%w: %s", ErrUnknownOutputFormat, reportOutput)
+	}
+
+	gc, err := newGovernorClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	sc := newSlackClient()
+
+	ir, err := newIdentityResolver(sc, gc, nil)
+	if err != nil {
+		return err
+	}
+
+	namer, err := newUserGroupNamer()
+	if err != nil {
+		return err
+	}
+
+	rec := reconciler.New(
+		reconciler.WithClient(sc),
+		reconciler.WithGovernorClient(gc, nil),
+		reconciler.WithIdentityResolver(ir),
+		reconciler.WithUserGroupNamer(namer),
+		reconciler.WithLogger(logger.Desugar()),
+		reconciler.WithUserGroupPrefix(configs.AppConfig.Slack.UsergroupPrefix),
+		reconciler.WithOrgID(configs.AppConfig.Slack.OrgID),
+		reconciler.WithApplicationType(configs.AppConfig.Governor.ApplicationType),
+		reconciler.WithDryRun(true),
+	)
+
+	apps, err := rec.SlackApplications(ctx)
+	if err != nil {
+		return err
+	}
+
+	reports := []reconciler.UnmatchedReport{}
+
+	for _, app := range apps {
+		if reportWorkspace != "" && app.Name != reportWorkspace {
+			continue
+		}
+
+		groups, err := gc.ApplicationGroups(ctx, app.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, g := range groups {
+			report, err := rec.UnmatchedMembers(ctx, g.ID, app.ID)
+			if err != nil {
+				logger.Warnw("failed to get unmatched members", "governor.group.slug", g.Slug, "slack.workspace.name", app.Name, "error", err)
+				continue
+			}
+
+			if report != nil && len(report.Members) > 0 {
+				reports = append(reports, *report)
+			}
+		}
+	}
+
+	if reportOutput == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(reports)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:mnd
+
+	fmt.Fprintln(w, "WORKSPACE\tGROUP\tEMAIL\tREASON")
+
+	for _, r := range reports {
+		for _, m := range r.Members {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Workspace, r.GovernorGroupSlug, m.Email, m.Reason)
+		}
+	}
+
+	return w.Flush()
+}
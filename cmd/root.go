@@ -46,6 +46,17 @@ func init() {
 	sdkcfg.MustLoggingFlags(v, flags)
 	sdkcfg.MustTracingFlags(v, flags)
 	sdkcfg.MustAuditFlags(v, flags)
+
+	// slack and governor flags are shared by the serve command and the
+	// commands that talk to slack and governor directly
+	configs.MustSlackFlags(v, flags)
+	configs.MustGovernorFlags(v, flags)
+	configs.MustIdentityFlags(v, flags)
+
+	// the state store is shared with the commands that bind user groups, so the
+	// addon sees their mappings
+	sdkcfg.MustNATSFlags(v, flags)
+	configs.MustStoreFlags(v, flags)
 }
 
 // initConfig reads in config file and ENV variables if set.
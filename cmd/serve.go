@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -11,7 +12,6 @@ import (
 	"time"
 
 	"github.com/metal-toolbox/auditevent"
-	audithelpers "github.com/metal-toolbox/auditevent/helpers"
 	"github.com/nats-io/nats.go"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -23,14 +23,24 @@ import (
 	sdkcfg "github.com/metal-toolbox/governor-extension-sdk/pkg/configs"
 	extserver "github.com/metal-toolbox/governor-extension-sdk/pkg/server"
 
+	"github.com/metal-toolbox/gov-slack-addon/internal/apisrv"
+	"github.com/metal-toolbox/gov-slack-addon/internal/auditsink"
 	"github.com/metal-toolbox/gov-slack-addon/internal/configs"
+	"github.com/metal-toolbox/gov-slack-addon/internal/govapi"
 	"github.com/metal-toolbox/gov-slack-addon/internal/natslock"
 	"github.com/metal-toolbox/gov-slack-addon/internal/natssrv"
+	"github.com/metal-toolbox/gov-slack-addon/internal/notify"
+	"github.com/metal-toolbox/gov-slack-addon/internal/ops"
 	"github.com/metal-toolbox/gov-slack-addon/internal/reconciler"
 	"github.com/metal-toolbox/gov-slack-addon/internal/slack"
+	"github.com/metal-toolbox/gov-slack-addon/internal/store"
 )
 
-const govClientTimeout = 10 * time.Second
+const (
+	govClientTimeout = 10 * time.Second
+	// auditFlushTimeout is how long the buffered audit events are flushed for on shutdown
+	auditFlushTimeout = 10 * time.Second
+)
 
 // serveCmd starts the gov-slack-addon service
 var serveCmd = &cobra.Command{
@@ -48,10 +58,13 @@ func init() {
 	flags := serveCmd.Flags()
 
 	sdkcfg.MustServerFlags(v, flags)
-	sdkcfg.MustNATSFlags(v, flags)
-	configs.MustSlackFlags(v, flags)
-	configs.MustGovernorFlags(v, flags)
 	configs.MustReconcilerFlags(v, flags)
+	configs.MustChannelsFlags(v, flags)
+	configs.MustReportsFlags(v, flags)
+	configs.MustNotificationsFlags(v, flags)
+	configs.MustOpsFlags(v, flags)
+	configs.MustAPIFlags(v, flags)
+	configs.MustAuditingFlags(v, flags)
 }
 
 func serve(cmdCtx context.Context) error {
@@ -85,20 +98,6 @@ func serve(cmdCtx context.Context) error {
 		}
 	}()
 
-	auditpath := configs.AppConfig.Audit.LogPath
-
-	if auditpath == "" {
-		logger.Fatal("failed starting server. Audit log file path can't be empty")
-	}
-
-	// WARNING: This will block until the file is available;
-	// make sure an initContainer creates the file
-	auf, auerr := audithelpers.OpenAuditLogFileUntilSuccess(auditpath)
-	if auerr != nil {
-		logger.Fatalw("couldn't open audit file.", "error", auerr)
-	}
-	defer auf.Close()
-
 	// NATS connection
 	nc, err := configs.AppConfig.NATSConn(ctx, appName, govcfg.WithLogger(logger.Desugar()))
 	if err != nil {
@@ -107,6 +106,22 @@ func serve(cmdCtx context.Context) error {
 
 	defer nc.Close()
 
+	auditWriter, err := newAuditDispatcher(ctx, nc)
+	if err != nil {
+		logger.Fatalw("failed creating audit sinks", "error", err)
+	}
+
+	defer func() {
+		logger.Info("flushing audit events")
+
+		flushCtx, flushCancel := context.WithTimeout(context.Background(), auditFlushTimeout)
+		defer flushCancel()
+
+		if err := auditWriter.Close(flushCtx); err != nil {
+			logger.Desugar().Error("failed to flush audit events", zap.Error(err), zap.Any("dropped", auditWriter.Dropped()))
+		}
+	}()
+
 	natsClient, err := extserver.NewNATSClient(
 		extserver.WithNATSLogger(logger.Desugar()),
 		extserver.WithNATSConn(nc),
@@ -118,32 +133,74 @@ func serve(cmdCtx context.Context) error {
 		logger.Fatalw("failed creating new NATS client", "error", err)
 	}
 
-	gc, err := configs.NewGovernorClient(
-		ctx,
-		governor.WithLogger(logger.Desugar()),
-		governor.WithHTTPClient(&http.Client{
-			Timeout:   govClientTimeout,
-			Transport: otelhttp.NewTransport(http.DefaultTransport),
-		}),
-	)
+	gc, err := newGovernorClient(ctx)
 	if err != nil {
 		logger.Fatalw("failed creating governor client", "error", err)
 	}
 
-	sc := slack.NewClient(
-		slack.WithLogger(logger.Desugar()),
-		slack.WithToken(configs.AppConfig.Slack.Token),
-	)
+	sc := newSlackClient(slack.WithTracer(tracer))
+
+	ir, err := newIdentityResolver(sc, gc, nc)
+	if err != nil {
+		logger.Fatalw("failed creating identity resolver", "error", err)
+	}
+
+	namer, err := newUserGroupNamer()
+	if err != nil {
+		logger.Fatalw("failed parsing user group templates", "error", err)
+	}
+
+	st, err := newStore(nc)
+	if err != nil {
+		logger.Fatalw("failed creating state store", "error", err)
+	}
+
+	ga, err := newGovAPIClient(ctx)
+	if err != nil {
+		logger.Fatalw("failed creating governor api client", "error", err)
+	}
+
+	// the group settings are only available once the addon is registered as a governor extension
+	var extensions *govapi.Client
+	if configs.AppConfig.Governor.ExtensionID != "" {
+		extensions = ga
+	}
+
+	notifier, err := newNotifier(sc)
+	if err != nil {
+		logger.Fatalw("failed parsing notification templates", "error", err)
+	}
 
 	rec := reconciler.New(
-		reconciler.WithAuditEventWriter(auditevent.NewDefaultAuditEventWriter(auf)),
+		reconciler.WithAuditEventWriter(auditevent.NewDefaultAuditEventWriter(auditWriter)),
 		reconciler.WithClient(sc),
-		reconciler.WithGovernorClient(gc),
+		reconciler.WithTracer(tracer),
+		reconciler.WithGovernorClient(gc, ga),
+		reconciler.WithIdentityResolver(ir),
+		reconciler.WithUserGroupNamer(namer),
+		reconciler.WithStore(st),
+		reconciler.WithAdoptRules(adoptRules()),
+		reconciler.WithExclusionRules(exclusionRules()),
 		reconciler.WithLogger(logger.Desugar()),
 		reconciler.WithInterval(configs.AppConfig.Reconciler.Interval),
+		reconciler.WithRetention(configs.AppConfig.Reconciler.Retention),
+		reconciler.WithWorkers(configs.AppConfig.Reconciler.Workers, configs.AppConfig.Reconciler.WorkspaceWorkers),
+		reconciler.WithPassTimeout(configs.AppConfig.Reconciler.PassTimeout),
+		reconciler.WithDeepInterval(configs.AppConfig.Reconciler.DeepInterval),
 		reconciler.WithUserGroupPrefix(configs.AppConfig.Slack.UsergroupPrefix),
+		reconciler.WithOrgID(configs.AppConfig.Slack.OrgID),
 		reconciler.WithDryRun(configs.AppConfig.DryRun),
 		reconciler.WithApplicationType(configs.AppConfig.Governor.ApplicationType),
+		reconciler.WithGovernorUIURL(configs.AppConfig.Governor.UIURL),
+		reconciler.WithMembershipRequests(configs.AppConfig.Governor.MembershipRequests),
+		reconciler.WithExtension(extensions, configs.AppConfig.Governor.ExtensionSlug),
+		reconciler.WithNotifier(notifier),
+		reconciler.WithOpsReporter(newOpsReporter(sc)),
+		reconciler.WithChannelLinks(channelLinks()),
+		reconciler.WithChannelKick(configs.AppConfig.Channels.Kick),
+		reconciler.WithDefaultChannels(defaultChannels()),
+		reconciler.WithUnmatchedReportChannel(configs.AppConfig.Reports.UnmatchedChannel),
+		reconciler.WithUnmatchedReportInterval(configs.AppConfig.Reports.UnmatchedInterval),
 	)
 
 	if configs.AppConfig.Reconciler.Locking {
@@ -163,14 +220,19 @@ func serve(cmdCtx context.Context) error {
 		natssrv.WithTracer(tracer),
 	)
 
-	// gov-slack-addon is a conventional (non-interactive) governor addon that
-	// only processes events, so no extension ID or ERDs are registered.
+	// without an extension ID gov-slack-addon is a conventional (non-interactive) governor
+	// addon that only processes events, and no ERDs are registered.
+	erdDir := ""
+	if configs.AppConfig.Governor.ExtensionID != "" {
+		erdDir = configs.AppConfig.Governor.ERDDir
+	}
+
 	server := extserver.NewServer(
 		configs.AppConfig.Server.Listen,
-		"", // extensionID
-		"", // erdDir
+		configs.AppConfig.Governor.ExtensionID,
+		erdDir,
 		extserver.WithEventProcessor(proc),
-		extserver.WithAuditFileWriter(auf),
+		extserver.WithAuditFileWriter(auditWriter),
 		extserver.WithLogger(logger.Desugar()),
 		extserver.WithDebug(configs.AppConfig.Logging.Debug),
 		extserver.WithGovernorClient(gc),
@@ -189,6 +251,20 @@ func serve(cmdCtx context.Context) error {
 	// periodic reconciler loop alongside it
 	go rec.Run(ctx)
 
+	api := apisrv.NewServer(
+		configs.AppConfig.API.Listen,
+		rec,
+		apisrv.WithLogger(logger.Desugar().With(zap.String("component", "api"))),
+		apisrv.WithToken(configs.AppConfig.API.Token),
+		apisrv.WithSlackSigningSecret(configs.AppConfig.API.SlackSigningSecret),
+	)
+
+	go func() {
+		if err := api.Run(ctx); err != nil {
+			logger.Fatalw("failed starting api server", "error", err)
+		}
+	}()
+
 	if err := server.Run(ctx); err != nil {
 		logger.Fatalw("failed starting server", "error", err)
 	}
@@ -198,6 +274,227 @@ func serve(cmdCtx context.Context) error {
 	return nil
 }
 
+// newGovernorClient creates a governor api client from the app config
+func newGovernorClient(ctx context.Context) (*governor.Client, error) {
+	return configs.NewGovernorClient(
+		ctx,
+		governor.WithLogger(logger.Desugar()),
+		governor.WithHTTPClient(&http.Client{
+			Timeout:   govClientTimeout,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		}),
+	)
+}
+
+// newGovAPIClient creates the governor client for the calls the governor-api client doesn't provide, such
+// as filing membership requests and reading the extension resources
+func newGovAPIClient(ctx context.Context) (*govapi.Client, error) {
+	ts, err := configs.NewGovernorTokenSource(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return govapi.NewClient(
+		configs.AppConfig.Governor.URL,
+		ts,
+		govapi.WithLogger(logger.Desugar()),
+		govapi.WithHTTPClient(&http.Client{
+			Timeout:   govClientTimeout,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		}),
+	), nil
+}
+
+// newSlackClient creates a slack api client from the app config, its requests are instrumented like
+// the governor api requests
+func newSlackClient(opts ...slack.Option) *slack.Client {
+	return slack.NewClient(append([]slack.Option{
+		slack.WithLogger(logger.Desugar()),
+		slack.WithToken(configs.AppConfig.Slack.Token),
+		slack.WithHTTPClient(&http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}),
+	}, opts...)...)
+}
+
+// newStore returns the addon state store, backed by a jetstream key-value bucket if one is configured
+func newStore(nc *nats.Conn) (store.Store, error) {
+	if configs.AppConfig.Store.Bucket == "" {
+		logger.Warn("no state store bucket configured, the addon state will be lost on restart")
+		return store.NewMemory(), nil
+	}
+
+	kv, err := keyValueBucket(nc, configs.AppConfig.Store.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.NewKeyValue(kv), nil
+}
+
+// newCommandStore opens the configured state store for the commands run alongside the addon, so they
+// see and update the same user group mappings. The returned function closes the NATS connection.
+func newCommandStore(ctx context.Context) (store.Store, func(), error) {
+	if configs.AppConfig.Store.Bucket == "" {
+		logger.Warn("no state store bucket configured, the user group mappings of the addon won't be used")
+		return store.NewMemory(), func() {}, nil
+	}
+
+	nc, err := configs.AppConfig.NATSConn(ctx, appName, govcfg.WithLogger(logger.Desugar()))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	st, err := newStore(nc)
+	if err != nil {
+		nc.Close()
+		return nil, nil, err
+	}
+
+	return st, nc.Close, nil
+}
+
+// newUserGroupNamer parses the configured user group templates
+func newUserGroupNamer() (*reconciler.UserGroupNamer, error) {
+	overrides := make(map[string]reconciler.UserGroupTemplates, len(configs.AppConfig.Slack.ApplicationTemplates))
+
+	for app, t := range configs.AppConfig.Slack.ApplicationTemplates {
+		overrides[app] = reconciler.UserGroupTemplates(t)
+	}
+
+	return reconciler.NewUserGroupNamer(reconciler.UserGroupTemplates(configs.AppConfig.Slack.Templates), overrides)
+}
+
+// newNotifier returns the notifier for user group membership changes, or nil if notifications are disabled
+func newNotifier(sc *slack.Client) (*notify.Notifier, error) {
+	if !configs.AppConfig.Notifications.Enabled {
+		return nil, nil
+	}
+
+	return notify.New(
+		sc,
+		notify.Templates(configs.AppConfig.Notifications.Templates),
+		notify.WithLogger(logger.Desugar().With(zap.String("component", "notify"))),
+		notify.WithDryRun(configs.AppConfig.DryRun),
+	)
+}
+
+// newOpsReporter returns the reporter posting reconciliation summaries and alerts to the ops channels
+func newOpsReporter(sc *slack.Client) *ops.Reporter {
+	return ops.New(
+		sc,
+		ops.WithLogger(logger.Desugar().With(zap.String("component", "ops"))),
+		ops.WithDryRun(configs.AppConfig.DryRun),
+		ops.WithChannel(configs.AppConfig.Ops.Channel),
+		ops.WithWorkspaceChannels(configs.AppConfig.Ops.WorkspaceChannels),
+		ops.WithRateLimit(configs.AppConfig.Ops.RateLimit),
+		ops.WithRepeatInterval(configs.AppConfig.Ops.RepeatInterval),
+	)
+}
+
+// adoptRules converts the configured adopt rules for the reconciler
+func adoptRules() []reconciler.AdoptRule {
+	rules := make([]reconciler.AdoptRule, 0, len(configs.AppConfig.Adopt))
+
+	for _, a := range configs.AppConfig.Adopt {
+		rules = append(rules, reconciler.AdoptRule(a))
+	}
+
+	return rules
+}
+
+// exclusionRules converts the configured exclusion rules for the reconciler
+func exclusionRules() []reconciler.ExclusionRule {
+	rules := make([]reconciler.ExclusionRule, 0, len(configs.AppConfig.Exclusions))
+
+	for _, e := range configs.AppConfig.Exclusions {
+		rules = append(rules, reconciler.ExclusionRule(e))
+	}
+
+	return rules
+}
+
+// channelLinks converts the configured channel links for the reconciler
+func channelLinks() []reconciler.ChannelLink {
+	links := make([]reconciler.ChannelLink, 0, len(configs.AppConfig.Channels.Links))
+
+	for _, l := range configs.AppConfig.Channels.Links {
+		links = append(links, reconciler.ChannelLink{
+			Group:     l.Group,
+			Workspace: l.Workspace,
+			Channels:  l.Channels,
+		})
+	}
+
+	return links
+}
+
+// defaultChannels converts the configured default channels rules for the reconciler
+func defaultChannels() []reconciler.DefaultChannels {
+	rules := make([]reconciler.DefaultChannels, 0, len(configs.AppConfig.Channels.Defaults))
+
+	for _, d := range configs.AppConfig.Channels.Defaults {
+		rules = append(rules, reconciler.DefaultChannels{
+			Group:       d.Group,
+			Application: d.Application,
+			Channels:    d.Channels,
+		})
+	}
+
+	return rules
+}
+
+// newAuditDispatcher creates the dispatcher writing the audit events to the configured sinks. The file
+// sink is reopened on SIGHUP so the audit log can be rotated.
+func newAuditDispatcher(ctx context.Context, nc *nats.Conn) (*auditsink.Dispatcher, error) {
+	cfg := configs.AppConfig.Auditing
+	sinks := []auditsink.Sink{}
+
+	for _, name := range cfg.Sinks {
+		switch strings.TrimSpace(name) {
+		case "file":
+			if configs.AppConfig.Audit.LogPath == "" {
+				return nil, ErrAuditLogPathRequired
+			}
+
+			fs := auditsink.NewFileSink(configs.AppConfig.Audit.LogPath)
+			fs.ReopenOnSignal(ctx, logger.Desugar(), syscall.SIGHUP)
+
+			sinks = append(sinks, fs)
+		case "jetstream":
+			jets, err := nc.JetStream()
+			if err != nil {
+				return nil, err
+			}
+
+			if err := auditsink.EnsureStream(jets, cfg.JetStream.Stream, cfg.JetStream.Subject); err != nil {
+				return nil, err
+			}
+
+			sinks = append(sinks, auditsink.NewJetStreamSink(jets, cfg.JetStream.Subject))
+		case "webhook":
+			if cfg.Webhook.URL == "" {
+				return nil, ErrAuditWebhookURLRequired
+			}
+
+			sinks = append(sinks, auditsink.NewWebhookSink(
+				cfg.Webhook.URL,
+				auditsink.WithWebhookToken(cfg.Webhook.Token),
+				auditsink.WithWebhookClient(&http.Client{
+					Timeout:   cfg.Webhook.Timeout,
+					Transport: otelhttp.NewTransport(http.DefaultTransport),
+				}),
+			))
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnknownAuditSink, name)
+		}
+	}
+
+	return auditsink.NewDispatcher(
+		sinks,
+		auditsink.WithLogger(logger.Desugar().With(zap.String("component", "audit"))),
+		auditsink.WithBufferSize(cfg.BufferSize),
+	), nil
+}
+
 // newNATSLocker creates a new NATS jetstream locker from a NATS connection
 func newNATSLocker(nc *nats.Conn) (*natslock.Locker, error) {
 	jets, err := nc.JetStream()
@@ -227,6 +524,11 @@ func validateMandatoryFlags() error {
 		return fmt.Errorf("invalid config: %w", err)
 	}
 
+	return validateClientFlags()
+}
+
+// validateClientFlags collects the validation of the flags needed to talk to slack and governor
+func validateClientFlags() error {
 	errs := []string{}
 
 	if configs.AppConfig.Slack.Token == "" {
@@ -261,3 +563,22 @@ func validateMandatoryFlags() error {
 
 	return fmt.Errorf("%s", strings.Join(errs, "\n")) //nolint:govet,err113,staticcheck
 }
+
+// keyValueBucket returns the jetstream key-value bucket, creating it if it doesn't exist
+func keyValueBucket(nc *nats.Conn, bucket string) (nats.KeyValue, error) {
+	jets, err := nc.JetStream()
+	if err != nil {
+		return nil, err
+	}
+
+	kv, err := jets.KeyValue(bucket)
+	if err == nil {
+		return kv, nil
+	}
+
+	if !errors.Is(err, nats.ErrBucketNotFound) {
+		return nil, err
+	}
+
+	return jets.CreateKeyValue(&nats.KeyValueConfig{Bucket: bucket})
+}
@@ -0,0 +1,3 @@
+// Package apisrv provides the addon's own HTTP API, which serves admin and reporting endpoints
+// and receives the slack events api callbacks and slash commands
+package apisrv
@@ -0,0 +1,15 @@
+package apisrv
+
+import "errors"
+
+var (
+	// ErrUnauthorized is returned when a request to an admin endpoint doesn't carry the expected token,
+	// or a slack request isn't signed with the signing secret
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrInvalidSlackEvent is returned when a slack events api request can't be parsed
+	ErrInvalidSlackEvent = errors.New("invalid slack event")
+	// ErrInvalidSlackCommand is returned when a slack slash command request can't be parsed
+	ErrInvalidSlackCommand = errors.New("invalid slack command")
+	// ErrInvalidSlackInteraction is returned when a slack interaction request can't be parsed
+	ErrInvalidSlackInteraction = errors.New("invalid slack interaction")
+)
@@ -0,0 +1,31 @@
+package apisrv
+
+import (
+	"net/http"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/reconciler"
+)
+
+// unmatchedReports returns the governor group members that couldn't be matched to slack users,
+// as recorded by the latest reconciliation. The results can be filtered with the `workspace`
+// and `group` (governor group id or slug) query parameters.
+func (s *Server) unmatchedReports(w http.ResponseWriter, req *http.Request) {
+	workspace := req.URL.Query().Get("workspace")
+	group := req.URL.Query().Get("group")
+
+	reports := []reconciler.UnmatchedReport{}
+
+	for _, r := range s.reconciler.UnmatchedReports() {
+		if workspace != "" && r.Workspace != workspace {
+			continue
+		}
+
+		if group != "" && r.GovernorGroupID != group && r.GovernorGroupSlug != group {
+			continue
+		}
+
+		reports = append(reports, r)
+	}
+
+	writeJSON(w, http.StatusOK, reports)
+}
@@ -0,0 +1,149 @@
+package apisrv
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/reconciler"
+)
+
+const (
+	readHeaderTimeout = 5 * time.Second
+	shutdownTimeout   = 10 * time.Second
+)
+
+// Server is the addon API server
+type Server struct {
+	listen     string
+	logger     *zap.Logger
+	reconciler *reconciler.Reconciler
+	token      string
+
+	slackSigningSecret string
+
+	// respond posts a message to a slack response url
+	respond func(ctx context.Context, url string, msg *slack.WebhookMessage) error
+}
+
+// Option is a function that configures a Server
+type Option func(*Server)
+
+// NewServer creates a new addon API server
+func NewServer(listen string, rec *reconciler.Reconciler, opts ...Option) *Server {
+	s := &Server{
+		listen:     listen,
+		logger:     zap.NewNop(),
+		reconciler: rec,
+		respond:    slack.PostWebhookContext,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// WithLogger configures the logger for the Server
+func WithLogger(logger *zap.Logger) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// WithToken sets the bearer token required by the admin endpoints
+func WithToken(token string) Option {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// WithSlackSigningSecret sets the slack app signing secret, used to authenticate the slack events
+// api, slash command and interaction requests. The slack endpoints are disabled if it's empty.
+func WithSlackSigningSecret(secret string) Option {
+	return func(s *Server) {
+		s.slackSigningSecret = secret
+	}
+}
+
+// Handler returns the http handler with all the API routes
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.Handle("GET /api/v1/reports/unmatched", s.adminMiddleware(http.HandlerFunc(s.unmatchedReports)))
+
+	if s.slackSigningSecret != "" {
+		mux.HandleFunc("POST /api/v1/slack/events", s.slackEvents)
+		mux.HandleFunc("POST /api/v1/slack/commands", s.slackCommands)
+		mux.HandleFunc("POST /api/v1/slack/interactions", s.slackInteractions)
+	}
+
+	return mux
+}
+
+// Run starts the API server and shuts it down when the context is canceled
+func (s *Server) Run(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:              s.listen,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			s.logger.Error("failed to shutdown api server", zap.Error(err))
+		}
+	}()
+
+	if s.token == "" {
+		s.logger.Warn("no api token configured, the admin endpoints are disabled")
+	}
+
+	s.logger.Info("starting api server", zap.String("address", s.listen))
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// adminMiddleware requires the configured bearer token. The admin endpoints serve governor member
+// details, so every request is rejected if no token is configured.
+func (s *Server) adminMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if s.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, ErrUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+// writeJSON writes the response body as json
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// writeError writes an error response
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,77 @@
+package apisrv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/reconciler"
+)
+
+func TestServer_unmatchedReports(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      string
+		authHeader string
+		wantStatus int
+	}{
+		{
+			name:       "no token configured",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "no token configured with empty bearer",
+			authHeader: "Bearer ",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "valid token",
+			token:      "s3cr3t",
+			authHeader: "Bearer s3cr3t",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid token",
+			token:      "s3cr3t",
+			authHeader: "Bearer nope",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "missing token",
+			token:      "s3cr3t",
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer("", reconciler.New(), WithToken(tt.token))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/reports/unmatched", nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			reports := []reconciler.UnmatchedReport{}
+			if err := json.NewDecoder(rec.Body).Decode(&reports); err != nil {
+				t.Fatalf("unexpected error decoding response: %s", err)
+			}
+
+			if len(reports) != 0 {
+				t.Errorf("expected no reports, got %v", reports)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package apisrv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/reconciler"
+)
+
+const (
+	// slackCommandUsage is the help text of the slash command
+	slackCommandUsage = "Usage: `%[1]s @user-group` shows the governor group behind a user group, its members and admins, and how to join it."
+
+	// maxSectionTextLength is the maximum length of the text of a slack section block
+	maxSectionTextLength = 3000
+
+	// commandTimeout bounds the background handling of a slash command
+	commandTimeout = 30 * time.Second
+)
+
+// slackCommands answers the `/governor` slash command. The requests are authenticated with the
+// slack signing secret, and the answer is only visible to the user who ran the command. Slack
+// expects an answer within 3 seconds, so the group is looked up in the background and the answer
+// is sent to the response url.
+func (s *Server) slackCommands(w http.ResponseWriter, req *http.Request) {
+	body, err := s.verifySlackRequest(req)
+	if err != nil {
+		s.logger.Warn("rejected slack command request", zap.Error(err))
+		writeError(w, http.StatusUnauthorized, ErrUnauthorized)
+
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrInvalidSlackCommand)
+		return
+	}
+
+	command := form.Get("command")
+	teamID := form.Get("team_id")
+	text := strings.TrimSpace(form.Get("text"))
+
+	logger := s.logger.With(
+		zap.String("slack.command", command),
+		zap.String("slack.workspace.id", teamID),
+		zap.String("slack.user.id", form.Get("user_id")),
+	)
+
+	if text == "" || text == "help" {
+		writeSlackMessage(w, fmt.Sprintf(slackCommandUsage, command))
+		return
+	}
+
+	responseURL := form.Get("response_url")
+	if responseURL == "" {
+		writeError(w, http.StatusBadRequest, ErrInvalidSlackCommand)
+		return
+	}
+
+	go s.groupInfo(logger, teamID, text, responseURL)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// groupInfo looks up the governor group behind the user group and sends it to the response url
+func (s *Server) groupInfo(logger *zap.Logger, teamID, text, responseURL string) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	msg := &slack.WebhookMessage{ResponseType: slack.ResponseTypeEphemeral}
+
+	info, err := s.reconciler.GroupInfo(ctx, teamID, strings.Fields(text)[0])
+
+	switch {
+	case err == nil:
+		msg.Text = reconciler.FormatGroupInfo(info)
+
+		if s.reconciler.MembershipRequestsEnabled() {
+			blocks := groupInfoBlocks(msg.Text, info.GovernorGroupID)
+			msg.Blocks = &blocks
+		}
+	case errors.Is(err, reconciler.ErrUserGroupNotManaged):
+		msg.Text = fmt.Sprintf("%s isn't managed by governor.", text)
+	default:
+		logger.Error("failed to get governor group info", zap.String("slack.command.text", text), zap.Error(err))
+		msg.Text = "Sorry, something went wrong looking up the governor group, please try again later."
+	}
+
+	if err := s.respond(ctx, responseURL, msg); err != nil {
+		logger.Error("failed to respond to slack command", zap.Error(err))
+	}
+}
+
+// groupInfoBlocks returns the group info message with a button to request to join the group
+func groupInfoBlocks(text, groupID string) slack.Blocks {
+	if len(text) > maxSectionTextLength {
+		text = text[:maxSectionTextLength-len("…")] + "…"
+	}
+
+	return slack.Blocks{BlockSet: []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil),
+		slack.NewActionBlock(requestMembershipBlockID,
+			slack.NewButtonBlockElement(requestMembershipActionID, groupID, slack.NewTextBlockObject(slack.PlainTextType, "Request to join", false, false)),
+		),
+	}}
+}
+
+// writeSlackMessage answers a slack slash command with a message only visible to the user
+func writeSlackMessage(w http.ResponseWriter, text string) {
+	writeJSON(w, http.StatusOK, slack.Msg{
+		ResponseType: slack.ResponseTypeEphemeral,
+		Text:         text,
+	})
+}
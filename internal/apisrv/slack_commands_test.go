@@ -0,0 +1,109 @@
+package apisrv
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/reconciler"
+)
+
+func TestServer_slackCommands(t *testing.T) {
+	const secret = "signing-s3cr3t"
+
+	tests := []struct {
+		name       string
+		body       string
+		signWith   string
+		wantStatus int
+		wantText   string
+		wantAsync  bool
+	}{
+		{
+			name:       "help",
+			body:       "command=%2Fgovernor&team_id=T0001&user_id=U0001&text=",
+			signWith:   secret,
+			wantStatus: http.StatusOK,
+			wantText:   "Usage: `/governor @user-group`",
+		},
+		{
+			name:       "unmanaged user group",
+			body:       "command=%2Fgovernor&team_id=T0001&user_id=U0001&text=%40nope&response_url=https%3A%2F%2Fhooks.slack.test%2Fr",
+			signWith:   secret,
+			wantStatus: http.StatusOK,
+			wantText:   "@nope isn't managed by governor.",
+			wantAsync:  true,
+		},
+		{
+			name:       "missing response url",
+			body:       "command=%2Fgovernor&team_id=T0001&user_id=U0001&text=%40nope",
+			signWith:   secret,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "bad signature",
+			body:       "command=%2Fgovernor&team_id=T0001&user_id=U0001&text=",
+			signWith:   "nope",
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			responses := make(chan *slack.WebhookMessage, 1)
+
+			s := NewServer("", reconciler.New(), WithSlackSigningSecret(secret))
+			s.respond = func(_ context.Context, _ string, msg *slack.WebhookMessage) error {
+				responses <- msg
+				return nil
+			}
+
+			req := signedSlackRequest(t, tt.signWith, tt.body, time.Now())
+			req.URL.Path = "/api/v1/slack/commands"
+
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+
+			if tt.wantText == "" {
+				return
+			}
+
+			// the lookups are answered on the response url, the command is only acknowledged
+			if tt.wantAsync {
+				if rec.Body.Len() != 0 {
+					t.Errorf("expected an empty acknowledgement, got %s", rec.Body.String())
+				}
+
+				select {
+				case msg := <-responses:
+					if msg.ResponseType != slack.ResponseTypeEphemeral || !strings.HasPrefix(msg.Text, tt.wantText) {
+						t.Errorf("unexpected response %+v", msg)
+					}
+				case <-time.After(time.Second):
+					t.Fatal("expected a response to the command")
+				}
+
+				return
+			}
+
+			msg := slack.Msg{}
+			if err := json.NewDecoder(rec.Body).Decode(&msg); err != nil {
+				t.Fatalf("unexpected error decoding response: %s", err)
+			}
+
+			if msg.ResponseType != slack.ResponseTypeEphemeral || !strings.HasPrefix(msg.Text, tt.wantText) {
+				t.Errorf("unexpected response %+v", msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package apisrv
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/zap"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/reconciler"
+)
+
+// maxSlackEventSize is the maximum size of a slack events api request body
+const maxSlackEventSize = 1 << 20
+
+// slackEvents receives the slack events api callbacks. The requests are authenticated with the
+// slack signing secret, and changes to managed user groups are queued to be reverted.
+func (s *Server) slackEvents(w http.ResponseWriter, req *http.Request) {
+	body, err := s.verifySlackRequest(req)
+	if err != nil {
+		s.logger.Warn("rejected slack events request", zap.Error(err))
+		writeError(w, http.StatusUnauthorized, ErrUnauthorized)
+
+		return
+	}
+
+	event, err := slackevents.ParseEvent(json.RawMessage(body), slackevents.OptionNoVerifyToken())
+	if err != nil {
+		s.logger.Warn("failed to parse slack event", zap.Error(err))
+		writeError(w, http.StatusBadRequest, ErrInvalidSlackEvent)
+
+		return
+	}
+
+	switch event.Type {
+	case slackevents.URLVerification:
+		v, ok := event.Data.(*slackevents.EventsAPIURLVerificationEvent)
+		if !ok {
+			writeError(w, http.StatusBadRequest, ErrInvalidSlackEvent)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+
+		_, _ = w.Write([]byte(v.Challenge))
+
+		return
+	case slackevents.CallbackEvent:
+		s.handleSlackCallback(req, event)
+	default:
+		s.logger.Debug("ignoring slack event", zap.String("slack.event.type", event.Type))
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleSlackCallback queues the user group changes for the reconciler. Slack expects an answer
+// within 3 seconds, so the reconcile itself happens in the background.
+func (s *Server) handleSlackCallback(req *http.Request, event slackevents.EventsAPIEvent) {
+	var d reconciler.Drift
+
+	switch ev := event.InnerEvent.Data.(type) {
+	case *slackevents.SubteamUpdatedEvent:
+		d = reconciler.Drift{
+			EventType:   ev.Type,
+			TeamID:      ev.Subteam.TeamID,
+			UserGroupID: ev.Subteam.ID,
+			Actor:       ev.Subteam.UpdatedBy,
+		}
+	case *slackevents.SubteamMembersChangedEvent:
+		d = reconciler.Drift{
+			EventType:   ev.Type,
+			TeamID:      ev.TeamID,
+			UserGroupID: ev.SubteamID,
+			Added:       ev.AddedUsers,
+			Removed:     ev.RemovedUsers,
+		}
+	default:
+		s.logger.Debug("ignoring slack event", zap.String("slack.event.type", event.InnerEvent.Type))
+		return
+	}
+
+	if _, err := s.reconciler.QueueDrift(req.Context(), d); err != nil {
+		s.logger.Error("failed to queue user group reconcile", zap.String("slack.usergroup.id", d.UserGroupID), zap.Error(err))
+	}
+}
+
+// verifySlackRequest checks the slack signature of the request and returns its body
+func (s *Server) verifySlackRequest(req *http.Request) ([]byte, error) {
+	sv, err := slack.NewSecretsVerifier(req.Header, s.slackSigningSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxSlackEventSize))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := sv.Write(body); err != nil {
+		return nil, err
+	}
+
+	if err := sv.Ensure(); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
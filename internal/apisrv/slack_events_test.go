@@ -0,0 +1,110 @@
+package apisrv
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/reconciler"
+)
+
+func signedSlackRequest(t *testing.T, secret, body string, ts time.Time) *http.Request {
+	t.Helper()
+
+	timestamp := strconv.FormatInt(ts.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/slack/events", strings.NewReader(body))
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+
+	return req
+}
+
+func TestServer_slackEvents(t *testing.T) {
+	const secret = "signing-s3cr3t"
+
+	tests := []struct {
+		name       string
+		secret     string
+		body       string
+		signWith   string
+		signedAt   time.Time
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "url verification",
+			secret:     secret,
+			body:       `{"type":"url_verification","token":"x","challenge":"abc123"}`,
+			signWith:   secret,
+			signedAt:   time.Now(),
+			wantStatus: http.StatusOK,
+			wantBody:   "abc123",
+		},
+		{
+			name:       "unmanaged user group",
+			secret:     secret,
+			body:       `{"type":"event_callback","team_id":"T0001","event":{"type":"subteam_members_changed","subteam_id":"S0001","team_id":"T0001","added_users":["U0001"]}}`,
+			signWith:   secret,
+			signedAt:   time.Now(),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "bad signature",
+			secret:     secret,
+			body:       `{"type":"url_verification","token":"x","challenge":"abc123"}`,
+			signWith:   "nope",
+			signedAt:   time.Now(),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "expired timestamp",
+			secret:     secret,
+			body:       `{"type":"url_verification","token":"x","challenge":"abc123"}`,
+			signWith:   secret,
+			signedAt:   time.Now().Add(-time.Hour),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "invalid body",
+			secret:     secret,
+			body:       `not json`,
+			signWith:   secret,
+			signedAt:   time.Now(),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "endpoint disabled",
+			body:       `{"type":"url_verification","token":"x","challenge":"abc123"}`,
+			signWith:   secret,
+			signedAt:   time.Now(),
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer("", reconciler.New(), WithSlackSigningSecret(tt.secret))
+
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, signedSlackRequest(t, tt.signWith, tt.body, tt.signedAt))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("expected body %q, got %q", tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
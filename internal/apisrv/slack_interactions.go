@@ -0,0 +1,119 @@
+package apisrv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/metal-toolbox/auditevent"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/auctx"
+	"github.com/metal-toolbox/gov-slack-addon/internal/reconciler"
+)
+
+const (
+	requestMembershipBlockID  = "governor_membership"
+	requestMembershipActionID = "request_membership"
+
+	// interactionTimeout bounds the background handling of an interaction
+	interactionTimeout = 30 * time.Second
+)
+
+// slackInteractions receives the slack interactive components callbacks, such as the "Request to
+// join" button of the slash command answer. The requests are authenticated with the slack signing
+// secret. Slack expects an answer within 3 seconds, so the interactions are handled in the
+// background and their outcome is sent to the response url.
+func (s *Server) slackInteractions(w http.ResponseWriter, req *http.Request) {
+	body, err := s.verifySlackRequest(req)
+	if err != nil {
+		s.logger.Warn("rejected slack interaction request", zap.Error(err))
+		writeError(w, http.StatusUnauthorized, ErrUnauthorized)
+
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrInvalidSlackInteraction)
+		return
+	}
+
+	ic := slack.InteractionCallback{}
+	if err := json.Unmarshal([]byte(form.Get("payload")), &ic); err != nil {
+		s.logger.Warn("failed to parse slack interaction", zap.Error(err))
+		writeError(w, http.StatusBadRequest, ErrInvalidSlackInteraction)
+
+		return
+	}
+
+	if ic.Type == slack.InteractionTypeBlockActions {
+		for _, action := range ic.ActionCallback.BlockActions {
+			if action.ActionID != requestMembershipActionID {
+				continue
+			}
+
+			go s.requestMembership(ic.User.ID, action.Value, ic.ResponseURL)
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// requestMembership files the membership request of the slack user and tells them the outcome
+func (s *Server) requestMembership(slackUserID, groupID, responseURL string) {
+	ctx, cancel := context.WithTimeout(context.Background(), interactionTimeout)
+	defer cancel()
+
+	logger := s.logger.With(zap.String("slack.user.id", slackUserID), zap.String("governor.group.id", groupID))
+
+	ctx = auctx.WithAuditEvent(ctx, auditevent.NewAuditEvent(
+		"", // eventType to be populated later
+		auditevent.EventSource{
+			Type:  "slack",
+			Value: "SlackInteractions",
+		},
+		auditevent.OutcomeSucceeded,
+		map[string]string{
+			"event":         "membership-request",
+			"slack.user.id": slackUserID,
+		},
+		"gov-slack-addon",
+	))
+
+	var text string
+
+	_, err := s.reconciler.RequestMembership(ctx, slackUserID, groupID)
+
+	switch {
+	case err == nil:
+		text = "Your request to join the governor group has been filed, you'll be notified once the group admins approve or deny it."
+	case errors.Is(err, reconciler.ErrMembershipRequestPending):
+		text = "You already have a pending request to join this governor group."
+	case errors.Is(err, reconciler.ErrAlreadyGroupMember):
+		text = "You're already a member of this governor group."
+	case errors.Is(err, reconciler.ErrMembershipRequestWrongUser):
+		logger.Error("governor filed the membership request for another user", zap.Error(err))
+		text = "Sorry, the addon isn't allowed to request governor group memberships on your behalf, please request to join from governor."
+	case errors.Is(err, reconciler.ErrGovernorUserNotFound):
+		text = "You don't have a governor account matching your Slack email, please sign in to governor first."
+	default:
+		logger.Error("failed to request governor group membership", zap.Error(err))
+		text = "Sorry, something went wrong requesting to join the governor group, please try again later."
+	}
+
+	if responseURL == "" {
+		return
+	}
+
+	if err := s.respond(ctx, responseURL, &slack.WebhookMessage{
+		ResponseType: slack.ResponseTypeEphemeral,
+		Text:         text,
+	}); err != nil {
+		logger.Error("failed to respond to slack interaction", zap.Error(err))
+	}
+}
@@ -0,0 +1,85 @@
+package apisrv
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/reconciler"
+)
+
+func TestServer_slackInteractions(t *testing.T) {
+	const secret = "signing-s3cr3t"
+
+	payload := `{"type":"block_actions","user":{"id":"U0001"},"response_url":"https://hooks.slack.test/r",` +
+		`"actions":[{"action_id":"request_membership","block_id":"governor_membership","value":"group-1","type":"button"}]}`
+
+	tests := []struct {
+		name       string
+		body       string
+		signWith   string
+		wantStatus int
+		wantText   string
+	}{
+		{
+			name:       "request membership without a governor requests client",
+			body:       "payload=" + url.QueryEscape(payload),
+			signWith:   secret,
+			wantStatus: http.StatusOK,
+			wantText:   "Sorry, something went wrong",
+		},
+		{
+			name:       "invalid payload",
+			body:       "payload=nope",
+			signWith:   secret,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "bad signature",
+			body:       "payload=" + url.QueryEscape(payload),
+			signWith:   "nope",
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			responses := make(chan *slack.WebhookMessage, 1)
+
+			s := NewServer("", reconciler.New(), WithSlackSigningSecret(secret))
+			s.respond = func(_ context.Context, _ string, msg *slack.WebhookMessage) error {
+				responses <- msg
+				return nil
+			}
+
+			req := signedSlackRequest(t, tt.signWith, tt.body, time.Now())
+			req.URL.Path = "/api/v1/slack/interactions"
+
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+
+			if tt.wantText == "" {
+				return
+			}
+
+			select {
+			case msg := <-responses:
+				if msg.ResponseType != slack.ResponseTypeEphemeral || !strings.HasPrefix(msg.Text, tt.wantText) {
+					t.Errorf("unexpected response %+v", msg)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("expected a response to the interaction")
+			}
+		})
+	}
+}
@@ -3,19 +3,37 @@ package auctx
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"maps"
 
 	"github.com/metal-toolbox/auditevent"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type auditEventKeyType string
 
 const auditEventKey auditEventKeyType = "auditevent"
 
+// OutcomeDryRun is the outcome of an operation that would have been made if dry-run mode was disabled
+const OutcomeDryRun = "dryrun"
+
+// metadata keys of the audit events written for an operation
+const (
+	// ParentAuditIDKey is the id of the audit event in the context, e.g. the reconciliation pass or the
+	// governor event, that the operation is part of
+	ParentAuditIDKey = "parent_audit_id"
+	// TraceIDKey is the id of the trace of the operation
+	TraceIDKey = "trace_id"
+	// SpanIDKey is the id of the span of the operation
+	SpanIDKey = "span_id"
+)
+
 // ErrAuditEventKeyNotFound is returned when auditEventKey is not found in the context
 var ErrAuditEventKeyNotFound = fmt.Errorf("%s key not found in context", auditEventKey)
 
-// WithAuditEvent adds an audit event to the context
+// WithAuditEvent adds an audit event to the context. The event is the parent of the audit events
+// written for the operations made with the context, it's never written or changed itself.
 func WithAuditEvent(ctx context.Context, auevent *auditevent.AuditEvent) context.Context {
 	return context.WithValue(ctx, auditEventKey, auevent)
 }
@@ -31,14 +49,75 @@ func GetAuditEvent(ctx context.Context) *auditevent.AuditEvent {
 	return auEvent
 }
 
-// WriteAuditEvent assembles a complete audit event and writes it to the event writer
+// WriteAuditEvent assembles a complete audit event and writes it to the event writer, with the outcome
+// of the audit event in the context
 func WriteAuditEvent(ctx context.Context, evWriter *auditevent.EventWriter, evType string, evTarget map[string]string) error {
 	ae := GetAuditEvent(ctx)
 	if ae == nil {
 		return ErrAuditEventKeyNotFound
 	}
 
-	ae.Type = evType
+	return WriteAuditEventOutcome(ctx, evWriter, evType, ae.Outcome, evTarget, nil)
+}
+
+// Outcome returns the audit event outcome of an operation that returned err, or that was skipped
+// because of dry-run mode
+func Outcome(err error, dryrun bool) string {
+	switch {
+	case err != nil:
+		return auditevent.OutcomeFailed
+	case dryrun:
+		return OutcomeDryRun
+	default:
+		return auditevent.OutcomeSucceeded
+	}
+}
+
+// WriteAuditEventOutcome assembles a complete audit event with the given outcome and data, and writes it to
+// the event writer
+func WriteAuditEventOutcome(
+	ctx context.Context,
+	evWriter *auditevent.EventWriter,
+	evType, outcome string,
+	evTarget map[string]string,
+	data any,
+) error {
+	ae := GetAuditEvent(ctx)
+	if ae == nil {
+		return ErrAuditEventKeyNotFound
+	}
+
+	ev := newOperationEvent(ctx, ae, evType, outcome)
+
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+
+		ev.WithData((*json.RawMessage)(&raw))
+	}
+
+	return evWriter.Write(ev.WithTarget(evTarget))
+}
+
+// newOperationEvent returns a new audit event for an operation made as part of the parent event. It
+// has its own id and records the id of its parent and the trace of the operation, so the events
+// of operations that run concurrently can still be correlated.
+func newOperationEvent(ctx context.Context, parent *auditevent.AuditEvent, evType, outcome string) *auditevent.AuditEvent {
+	ev := auditevent.NewAuditEvent(evType, parent.Source, outcome, maps.Clone(parent.Subjects), parent.Component)
+
+	ev.Metadata.Extra = maps.Clone(parent.Metadata.Extra)
+	if ev.Metadata.Extra == nil {
+		ev.Metadata.Extra = map[string]any{}
+	}
+
+	ev.Metadata.Extra[ParentAuditIDKey] = parent.Metadata.AuditID
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		ev.Metadata.Extra[TraceIDKey] = sc.TraceID().String()
+		ev.Metadata.Extra[SpanIDKey] = sc.SpanID().String()
+	}
 
-	return evWriter.Write(ae.WithTarget(evTarget))
+	return ev
 }
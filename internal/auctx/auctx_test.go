@@ -0,0 +1,124 @@
+package auctx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/metal-toolbox/auditevent"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestOutcome(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		dryrun bool
+		want   string
+	}{
+		{name: "succeeded", want: auditevent.OutcomeSucceeded},
+		{name: "dry-run", dryrun: true, want: OutcomeDryRun},
+		{name: "failed", err: errors.New("boom"), want: auditevent.OutcomeFailed},                       //nolint:err113
+		{name: "failed dry-run", err: errors.New("boom"), dryrun: true, want: auditevent.OutcomeFailed}, //nolint:err113
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Outcome(tt.err, tt.dryrun); got != tt.want {
+				t.Errorf("Outcome() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteAuditEventOutcome(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := auditevent.NewDefaultAuditEventWriter(buf)
+
+	if err := WriteAuditEventOutcome(context.Background(), w, "Test", OutcomeDryRun, nil, nil); !errors.Is(err, ErrAuditEventKeyNotFound) {
+		t.Fatalf("expected ErrAuditEventKeyNotFound, got %v", err)
+	}
+
+	ae := auditevent.NewAuditEvent("", auditevent.EventSource{}, auditevent.OutcomeSucceeded, nil, "test")
+	ctx := WithAuditEvent(context.Background(), ae)
+
+	err := WriteAuditEventOutcome(ctx, w, "Test", auditevent.OutcomeFailed, map[string]string{"id": "1"}, map[string]string{"error": "boom"})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	got := auditevent.AuditEvent{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode audit event: %v", err)
+	}
+
+	if got.Type != "Test" || got.Outcome != auditevent.OutcomeFailed || got.Target["id"] != "1" {
+		t.Errorf("unexpected audit event %+v", got)
+	}
+
+	if got.Data == nil || string(*got.Data) != `{"error":"boom"}` {
+		t.Errorf("unexpected audit event data %v", got.Data)
+	}
+
+	if ae.Type != "" || ae.Outcome != auditevent.OutcomeSucceeded || ae.Target != nil {
+		t.Errorf("expected the context audit event to be unchanged, got %+v", ae)
+	}
+
+	if got.Metadata.AuditID == ae.Metadata.AuditID || got.Metadata.Extra[ParentAuditIDKey] != ae.Metadata.AuditID {
+		t.Errorf("expected a new audit event with the context event as parent, got %+v", got.Metadata)
+	}
+}
+
+func TestWriteAuditEvent_trace(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := auditevent.NewDefaultAuditEventWriter(buf)
+
+	traceID := trace.TraceID{1, 2, 3}
+	spanID := trace.SpanID{4, 5, 6}
+
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+
+	parent := auditevent.NewAuditEvent("", auditevent.EventSource{}, auditevent.OutcomeSucceeded, map[string]string{"event": "test"}, "test")
+	ctx = WithAuditEvent(ctx, parent)
+
+	if err := WriteAuditEvent(ctx, w, "First", nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if err := WriteAuditEvent(ctx, w, "Second", nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	dec := json.NewDecoder(buf)
+	ids := map[string]bool{}
+
+	for _, want := range []string{"First", "Second"} {
+		got := auditevent.AuditEvent{}
+		if err := dec.Decode(&got); err != nil {
+			t.Fatalf("failed to decode audit event: %v", err)
+		}
+
+		if got.Type != want || got.Subjects["event"] != "test" {
+			t.Errorf("unexpected audit event %+v", got)
+		}
+
+		if got.Metadata.Extra[TraceIDKey] != traceID.String() || got.Metadata.Extra[SpanIDKey] != spanID.String() {
+			t.Errorf("expected the trace of the operation, got %+v", got.Metadata.Extra)
+		}
+
+		ids[got.Metadata.AuditID] = true
+	}
+
+	if len(ids) != 2 {
+		t.Error("expected each operation to have its own audit id")
+	}
+
+	if parent.Type != "" {
+		t.Errorf("expected the parent audit event to be unchanged, got type %s", parent.Type)
+	}
+}
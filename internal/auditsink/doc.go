@@ -0,0 +1,3 @@
+// Package auditsink writes audit events to the configured destinations: a local file, a jetstream
+// stream or an http webhook. Each sink has a bounded buffer, so a failing sink never blocks the writer.
+package auditsink
@@ -0,0 +1,11 @@
+package auditsink
+
+import "errors"
+
+var (
+	// ErrWebhookNonSuccess is returned when the audit webhook returns a non-success status
+	ErrWebhookNonSuccess = errors.New("got a non-success response from the audit webhook")
+
+	// ErrDispatcherClosed is returned when writing to a closed dispatcher
+	ErrDispatcherClosed = errors.New("audit sink dispatcher is closed")
+)
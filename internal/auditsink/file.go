@@ -0,0 +1,115 @@
+package auditsink
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// FileSink appends audit events to a local file. The file is created if it doesn't exist, and it can
+// be reopened after it's been rotated.
+type FileSink struct {
+	path string
+
+	mu sync.Mutex
+	f  *os.File
+}
+
+// NewFileSink returns a sink appending to the file at path, the file is opened on the first write
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+// Name identifies the sink in logs
+func (s *FileSink) Name() string {
+	return "file"
+}
+
+// Write appends the audit event to the file. The file is closed on error, so it's opened again on the
+// next write.
+func (s *FileSink) Write(_ context.Context, event []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.f == nil {
+		if err := s.open(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.f.Write(event); err != nil {
+		s.f.Close()
+		s.f = nil
+
+		return err
+	}
+
+	return nil
+}
+
+// Reopen closes and opens the file again, e.g. after it's been rotated
+func (s *FileSink) Reopen() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.f != nil {
+		s.f.Close()
+		s.f = nil
+	}
+
+	return s.open()
+}
+
+// ReopenOnSignal reopens the file whenever one of the signals is received, until the context is done
+func (s *FileSink) ReopenOnSignal(ctx context.Context, logger *zap.Logger, sig ...os.Signal) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, sig...)
+
+	go func() {
+		defer signal.Stop(c)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-c:
+				if err := s.Reopen(); err != nil {
+					logger.Error("failed to reopen audit log file", zap.String("audit.path", s.path), zap.Error(err))
+					continue
+				}
+
+				logger.Info("reopened audit log file", zap.String("audit.path", s.path))
+			}
+		}
+	}()
+}
+
+// Close closes the file
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.f == nil {
+		return nil
+	}
+
+	err := s.f.Close()
+	s.f = nil
+
+	return err
+}
+
+// open opens the file for appending, it must be called with the lock held
+func (s *FileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) //nolint:gosec
+	if err != nil {
+		return err
+	}
+
+	s.f = f
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package auditsink
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	s := NewFileSink(path)
+
+	ctx := context.Background()
+
+	if err := s.Write(ctx, []byte("one\n")); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// rotate the file, the sink keeps writing to the rotated file until it's reopened
+	rotated := path + ".1"
+	if err := os.Rename(path, rotated); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Write(ctx, []byte("two\n")); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if err := s.Reopen(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if err := s.Write(ctx, []byte("three\n")); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	for file, want := range map[string]string{rotated: "one\ntwo\n", path: "three\n"} {
+		got, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", file, got, want)
+		}
+	}
+}
+
+func TestFileSink_openError(t *testing.T) {
+	s := NewFileSink(filepath.Join(t.TempDir(), "missing", "audit.log"))
+
+	if err := s.Write(context.Background(), []byte("one\n")); err == nil {
+		t.Error("expected an error opening the file")
+	}
+}
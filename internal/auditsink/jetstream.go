@@ -0,0 +1,60 @@
+package auditsink
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nats-io/nats.go"
+)
+
+// publisher publishes messages to a jetstream stream
+type publisher interface {
+	Publish(subj string, data []byte, opts ...nats.PubOpt) (*nats.PubAck, error)
+}
+
+// JetStreamSink publishes audit events to a jetstream stream
+type JetStreamSink struct {
+	js      publisher
+	subject string
+}
+
+// NewJetStreamSink returns a sink publishing to the subject, which must be captured by a stream
+func NewJetStreamSink(js publisher, subject string) *JetStreamSink {
+	return &JetStreamSink{js: js, subject: subject}
+}
+
+// Name identifies the sink in logs
+func (s *JetStreamSink) Name() string {
+	return "jetstream"
+}
+
+// Write publishes the audit event and waits for the stream to acknowledge it
+func (s *JetStreamSink) Write(ctx context.Context, event []byte) error {
+	_, err := s.js.Publish(s.subject, event, nats.Context(ctx))
+
+	return err
+}
+
+// Close does nothing, the nats connection is owned by the caller
+func (s *JetStreamSink) Close() error {
+	return nil
+}
+
+// EnsureStream creates the stream capturing the subject if it doesn't exist
+func EnsureStream(js nats.JetStreamManager, name, subject string) error {
+	_, err := js.StreamInfo(name)
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, nats.ErrStreamNotFound) {
+		return err
+	}
+
+	_, err = js.AddStream(&nats.StreamConfig{
+		Name:     name,
+		Subjects: []string{subject},
+	})
+
+	return err
+}
@@ -0,0 +1,45 @@
+package auditsink
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+)
+
+type mockPublisher struct {
+	subject string
+	data    []byte
+	err     error
+}
+
+func (m *mockPublisher) Publish(subj string, data []byte, _ ...nats.PubOpt) (*nats.PubAck, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	m.subject = subj
+	m.data = data
+
+	return &nats.PubAck{}, nil
+}
+
+func TestJetStreamSink_Write(t *testing.T) {
+	p := &mockPublisher{}
+	s := NewJetStreamSink(p, "audit.gov-slack-addon")
+
+	if err := s.Write(context.Background(), []byte(`{"type":"Test"}`)); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if p.subject != "audit.gov-slack-addon" || string(p.data) != `{"type":"Test"}` {
+		t.Errorf("unexpected publish to %s: %s", p.subject, p.data)
+	}
+
+	p.err = nats.ErrNoStreamResponse
+
+	if err := s.Write(context.Background(), []byte("{}")); !errors.Is(err, nats.ErrNoStreamResponse) {
+		t.Errorf("expected the publish error, got %v", err)
+	}
+}
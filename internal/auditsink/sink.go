@@ -0,0 +1,231 @@
+package auditsink
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultBufferSize is the default number of audit events buffered for each sink
+	DefaultBufferSize = 1000
+	// DefaultRetryInterval is the default initial interval between retries of a failed write
+	DefaultRetryInterval = time.Second
+
+	// maxRetryInterval is the longest interval between retries of a failed write
+	maxRetryInterval = time.Minute
+)
+
+// Sink writes encoded audit events to a destination
+type Sink interface {
+	// Name identifies the sink in logs
+	Name() string
+	// Write writes a single encoded audit event
+	Write(ctx context.Context, event []byte) error
+	// Close releases the resources of the sink
+	Close() error
+}
+
+// Dispatcher is an io.Writer that writes each audit event to all of its sinks. Every sink has its own
+// bounded buffer and goroutine: writes never block, and events are dropped for a sink whose buffer
+// is full while it's failing.
+type Dispatcher struct {
+	logger        *zap.Logger
+	bufferSize    int
+	retryInterval time.Duration
+
+	mu     sync.RWMutex
+	closed bool
+	queues []*queue
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// queue is the buffer of a sink
+type queue struct {
+	sink    Sink
+	events  chan []byte
+	dropped atomic.Uint64
+}
+
+// Option is a functional configuration option
+type Option func(d *Dispatcher)
+
+// WithLogger sets logger
+func WithLogger(l *zap.Logger) Option {
+	return func(d *Dispatcher) {
+		d.logger = l
+	}
+}
+
+// WithBufferSize sets the number of audit events buffered for each sink
+func WithBufferSize(n int) Option {
+	return func(d *Dispatcher) {
+		if n > 0 {
+			d.bufferSize = n
+		}
+	}
+}
+
+// WithRetryInterval sets the initial interval between retries of a failed write, it doubles on each
+// retry up to a minute
+func WithRetryInterval(i time.Duration) Option {
+	return func(d *Dispatcher) {
+		if i > 0 {
+			d.retryInterval = i
+		}
+	}
+}
+
+// NewDispatcher returns a dispatcher writing to the sinks, and starts writing
+func NewDispatcher(sinks []Sink, opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		logger:        zap.NewNop(),
+		bufferSize:    DefaultBufferSize,
+		retryInterval: DefaultRetryInterval,
+		done:          make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	for _, s := range sinks {
+		q := &queue{sink: s, events: make(chan []byte, d.bufferSize)}
+		d.queues = append(d.queues, q)
+
+		d.wg.Add(1)
+
+		go d.run(q)
+	}
+
+	return d
+}
+
+// Write queues the audit event for all the sinks, the event is dropped for the sinks whose buffer is full.
+// It's meant to be wrapped in an auditevent.EventWriter, which writes each event in a single call.
+func (d *Dispatcher) Write(p []byte) (int, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.closed {
+		return 0, ErrDispatcherClosed
+	}
+
+	for _, q := range d.queues {
+		select {
+		case q.events <- slices.Clone(p):
+		default:
+			if q.dropped.Add(1) == 1 {
+				d.logger.Warn("audit sink buffer is full, dropping audit events", zap.String("audit.sink", q.sink.Name()))
+			}
+		}
+	}
+
+	return len(p), nil
+}
+
+// Dropped returns the number of audit events dropped by each sink since its last successful write, by sink name
+func (d *Dispatcher) Dropped() map[string]uint64 {
+	out := make(map[string]uint64, len(d.queues))
+
+	for _, q := range d.queues {
+		out[q.sink.Name()] = q.dropped.Load()
+	}
+
+	return out
+}
+
+// Close stops accepting audit events and writes the buffered events until the context is done, then
+// closes the sinks
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+
+	d.closed = true
+
+	for _, q := range d.queues {
+		close(q.events)
+	}
+
+	d.mu.Unlock()
+
+	flushed := make(chan struct{})
+
+	go func() {
+		d.wg.Wait()
+		close(flushed)
+	}()
+
+	select {
+	case <-flushed:
+	case <-ctx.Done():
+		// stop retrying the failing sinks
+		close(d.done)
+		<-flushed
+	}
+
+	for _, q := range d.queues {
+		if err := q.sink.Close(); err != nil {
+			d.logger.Warn("failed to close audit sink", zap.String("audit.sink", q.sink.Name()), zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// run writes the buffered events to the sink, retrying a failed write until it succeeds or the
+// dispatcher gives up on closing
+func (d *Dispatcher) run(q *queue) {
+	defer d.wg.Done()
+
+	logger := d.logger.With(zap.String("audit.sink", q.sink.Name()))
+
+	for ev := range q.events {
+		if !d.write(logger, q, ev) {
+			dropped := 1
+			for range q.events {
+				dropped++
+			}
+
+			logger.Error("dropped buffered audit events, the sink is failing and the dispatcher is closing", zap.Int("audit.dropped", dropped))
+
+			return
+		}
+	}
+}
+
+// write writes the event to the sink, retrying with backoff. It returns false if the dispatcher
+// gave up before the write succeeded.
+func (d *Dispatcher) write(logger *zap.Logger, q *queue, ev []byte) bool {
+	interval := d.retryInterval
+
+	for {
+		err := q.sink.Write(context.Background(), ev)
+		if err == nil {
+			if dropped := q.dropped.Swap(0); dropped > 0 {
+				logger.Warn("audit sink recovered", zap.Uint64("audit.dropped", dropped))
+			}
+
+			return true
+		}
+
+		logger.Warn("failed to write audit event, retrying", zap.Duration("retry.interval", interval), zap.Error(err))
+
+		select {
+		case <-d.done:
+			return false
+		case <-time.After(interval):
+		}
+
+		interval = min(interval*2, maxRetryInterval)
+	}
+}
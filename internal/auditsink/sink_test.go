@@ -0,0 +1,172 @@
+package auditsink
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockSink struct {
+	name string
+
+	mu      sync.Mutex
+	events  []string
+	fail    bool
+	blocked chan struct{}
+	closed  bool
+}
+
+func (m *mockSink) Name() string {
+	return m.name
+}
+
+func (m *mockSink) Write(_ context.Context, event []byte) error {
+	if m.blocked != nil {
+		<-m.blocked
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.fail {
+		return errors.New("sink failed") //nolint:err113
+	}
+
+	m.events = append(m.events, string(event))
+
+	return nil
+}
+
+func (m *mockSink) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.closed = true
+
+	return nil
+}
+
+func (m *mockSink) setFail(f bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.fail = f
+}
+
+func (m *mockSink) written() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]string{}, m.events...)
+}
+
+func TestDispatcher_Write(t *testing.T) {
+	a := &mockSink{name: "a"}
+	b := &mockSink{name: "b"}
+
+	d := NewDispatcher([]Sink{a, b})
+
+	for _, ev := range []string{"one", "two"} {
+		if _, err := d.Write([]byte(ev)); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	for _, s := range []*mockSink{a, b} {
+		if got := s.written(); len(got) != 2 || got[0] != "one" || got[1] != "two" {
+			t.Errorf("sink %s got %v, want [one two]", s.name, got)
+		}
+
+		if !s.closed {
+			t.Errorf("expected sink %s to be closed", s.name)
+		}
+	}
+
+	if _, err := d.Write([]byte("three")); !errors.Is(err, ErrDispatcherClosed) {
+		t.Errorf("expected ErrDispatcherClosed, got %v", err)
+	}
+}
+
+func TestDispatcher_failingSink(t *testing.T) {
+	ok := &mockSink{name: "ok"}
+	failing := &mockSink{name: "failing", fail: true}
+
+	d := NewDispatcher([]Sink{ok, failing}, WithBufferSize(2), WithRetryInterval(time.Millisecond))
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for i := range 10 {
+			_, _ = d.Write([]byte("ev"))
+
+			// let the healthy sink keep up, so only the failing sink drops events
+			for len(ok.written()) <= i {
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("writes blocked on the failing sink")
+	}
+
+	if got := d.Dropped()["failing"]; got == 0 {
+		t.Error("expected events to be dropped for the failing sink")
+	}
+
+	failing.setFail(false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := d.Close(ctx); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if got := len(ok.written()); got != 10 {
+		t.Errorf("expected the healthy sink to get all the events, got %d", got)
+	}
+
+	if got := len(failing.written()); got == 0 || got > 3 {
+		t.Errorf("expected the recovered sink to get its buffered events, got %d", got)
+	}
+}
+
+func TestDispatcher_CloseGivesUp(t *testing.T) {
+	failing := &mockSink{name: "failing", fail: true}
+
+	d := NewDispatcher([]Sink{failing}, WithRetryInterval(time.Millisecond))
+
+	_, _ = d.Write([]byte("ev"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	closed := make(chan struct{})
+
+	go func() {
+		_ = d.Close(ctx)
+
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("close didn't give up on the failing sink")
+	}
+
+	if !failing.closed {
+		t.Error("expected the sink to be closed")
+	}
+}
@@ -0,0 +1,89 @@
+package auditsink
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultWebhookTimeout is the default timeout of a request to the audit webhook
+const DefaultWebhookTimeout = 10 * time.Second
+
+// WebhookSink posts each audit event as json to an http endpoint
+type WebhookSink struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// WebhookOption is a functional configuration option for the webhook sink
+type WebhookOption func(s *WebhookSink)
+
+// WithWebhookToken sets the bearer token sent to the webhook
+func WithWebhookToken(t string) WebhookOption {
+	return func(s *WebhookSink) {
+		s.token = t
+	}
+}
+
+// WithWebhookClient sets the http client used to call the webhook
+func WithWebhookClient(c *http.Client) WebhookOption {
+	return func(s *WebhookSink) {
+		s.client = c
+	}
+}
+
+// NewWebhookSink returns a sink posting to the url
+func NewWebhookSink(url string, opts ...WebhookOption) *WebhookSink {
+	s := &WebhookSink{
+		url:    url,
+		client: &http.Client{Timeout: DefaultWebhookTimeout},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Name identifies the sink in logs
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+// Write posts the audit event, any non-2xx response is an error
+func (s *WebhookSink) Write(ctx context.Context, event []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(event))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %d", ErrWebhookNonSuccess, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Close does nothing
+func (s *WebhookSink) Close() error {
+	return nil
+}
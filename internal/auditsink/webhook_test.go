@@ -0,0 +1,51 @@
+package auditsink
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookSink_Write(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr error
+	}{
+		{name: "accepted", status: http.StatusAccepted},
+		{name: "server error", status: http.StatusInternalServerError, wantErr: ErrWebhookNonSuccess},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				gotBody []byte
+				gotAuth string
+				gotType string
+			)
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotBody, _ = io.ReadAll(r.Body)
+				gotAuth = r.Header.Get("Authorization")
+				gotType = r.Header.Get("Content-Type")
+
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			s := NewWebhookSink(srv.URL, WithWebhookToken("secret"), WithWebhookClient(srv.Client()))
+
+			err := s.Write(context.Background(), []byte(`{"type":"Test"}`))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Write() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if string(gotBody) != `{"type":"Test"}` || gotAuth != "Bearer secret" || gotType != "application/json" {
+				t.Errorf("unexpected request: body %s, authorization %q, content type %q", gotBody, gotAuth, gotType)
+			}
+		})
+	}
+}
@@ -13,28 +13,68 @@ import (
 	"github.com/spf13/viper"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/reconciler"
 )
 
 const (
 	// DefaultReconcilerInterval is the default interval for the reconciler loop
 	DefaultReconcilerInterval = 1 * time.Hour
+	// DefaultReconcilerDeepInterval is the default interval between deep reconciliation passes
+	DefaultReconcilerDeepInterval = 6 * time.Hour
+	// DefaultReconcilerWorkers is the default number of groups reconciled concurrently
+	DefaultReconcilerWorkers = reconciler.DefaultWorkers
+	// DefaultReconcilerWorkspaceWorkers is the default number of groups reconciled concurrently in a workspace
+	DefaultReconcilerWorkspaceWorkers = reconciler.DefaultWorkspaceWorkers
 	// DefaultNATSQueueSize is the default queue size for load balancing NATS consumers
 	DefaultNATSQueueSize = 3
+	// DefaultUnmatchedReportInterval is the default interval for posting the unmatched members report
+	DefaultUnmatchedReportInterval = 24 * time.Hour
+	// DefaultIdentityCacheTTL is the default ttl of the governor to slack user identity cache
+	DefaultIdentityCacheTTL = 30 * time.Minute
+	// DefaultIdentityProfileFieldRefresh is the default interval for refreshing the slack profile field index
+	DefaultIdentityProfileFieldRefresh = 1 * time.Hour
+	// DefaultOpsRateLimit is the default number of messages posted to an ops channel per hour
+	DefaultOpsRateLimit = 20
+	// DefaultOpsRepeatInterval is the default minimum time between thread replies for a repeated alert
+	DefaultOpsRepeatInterval = 15 * time.Minute
+	// DefaultAPIListen is the default listen address for the addon API
+	DefaultAPIListen = "0.0.0.0:8001"
+	// DefaultStoreBucket is the default jetstream key-value bucket for the addon state
+	DefaultStoreBucket = "gov-slack-addon-state"
+	// DefaultAuditBufferSize is the default number of audit events buffered for each audit sink
+	DefaultAuditBufferSize = 1000
+	// DefaultAuditStream is the default jetstream stream for the audit events
+	DefaultAuditStream = "gov-slack-addon-audit"
+	// DefaultAuditSubject is the default subject the audit events are published to
+	DefaultAuditSubject = "gov-slack-addon.audit"
+	// DefaultAuditWebhookTimeout is the default timeout of a request to the audit webhook
+	DefaultAuditWebhookTimeout = 10 * time.Second
 )
 
 // AppConfig holds the application configuration
 var AppConfig struct {
 	govcfg.Configs `mapstructure:",squash"`
 
-	DryRun     bool `mapstructure:"dryrun"`
-	Audit      sdkcfg.Audit
-	Tracing    sdkcfg.Tracing
-	Logging    sdkcfg.Logging
-	Governor   Governor
-	Slack      Slack
-	Reconciler Reconciler
-	Server     sdkcfg.Server
-	NATS       sdkcfg.NATSConfig
+	DryRun        bool `mapstructure:"dryrun"`
+	Audit         sdkcfg.Audit
+	Auditing      Auditing
+	Tracing       sdkcfg.Tracing
+	Logging       sdkcfg.Logging
+	Governor      Governor
+	Slack         Slack
+	Identity      Identity
+	Channels      Channels
+	Adopt         []AdoptRule
+	Exclusions    []ExclusionRule
+	Reconciler    Reconciler
+	Store         Store
+	Reports       Reports
+	Notifications Notifications
+	Ops           Ops
+	API           API
+	Server        sdkcfg.Server
+	NATS          sdkcfg.NATSConfig
 }
 
 // Governor reuses the SDK's Governor config and adds the addon-specific
@@ -43,18 +83,167 @@ type Governor struct {
 	sdkcfg.Governor `mapstructure:",squash"`
 
 	ApplicationType string `mapstructure:"application-type"`
+	UIURL           string `mapstructure:"ui-url"`
+
+	MembershipRequests bool `mapstructure:"membership-requests"`
+
+	ExtensionID   string `mapstructure:"extension-id"`
+	ExtensionSlug string `mapstructure:"extension-slug"`
+	ERDDir        string `mapstructure:"erd-dir"`
 }
 
 // Slack holds Slack API configuration
 type Slack struct {
 	Token           string `mapstructure:"token"`
 	UsergroupPrefix string `mapstructure:"usergroup-prefix"`
+	OrgID           string `mapstructure:"org-id"`
+
+	Templates            UserGroupTemplates            `mapstructure:"templates"`
+	ApplicationTemplates map[string]UserGroupTemplates `mapstructure:"application-templates"`
+}
+
+// UserGroupTemplates holds the go text templates for the slack user group name, handle and description
+type UserGroupTemplates struct {
+	Name        string `mapstructure:"name"`
+	Handle      string `mapstructure:"handle"`
+	Description string `mapstructure:"description"`
+}
+
+// Identity holds the configuration for matching governor users to slack users
+type Identity struct {
+	Resolvers      []string             `mapstructure:"resolvers"`
+	CacheTTL       time.Duration        `mapstructure:"cache-ttl"`
+	Overrides      map[string]string    `mapstructure:"overrides"`
+	OverrideBucket string               `mapstructure:"override-bucket"`
+	Rewrite        IdentityRewrite      `mapstructure:"rewrite"`
+	ProfileField   IdentityProfileField `mapstructure:"profile-field"`
+}
+
+// IdentityRewrite holds the email rewrite rules for identity resolution
+type IdentityRewrite struct {
+	Domains   map[string]string `mapstructure:"domains"`
+	StripPlus bool              `mapstructure:"strip-plus"`
+}
+
+// IdentityProfileField holds the slack profile field matching configuration for identity resolution
+type IdentityProfileField struct {
+	ID                string        `mapstructure:"id"`
+	GovernorAttribute string        `mapstructure:"governor-attribute"`
+	Refresh           time.Duration `mapstructure:"refresh"`
+}
+
+// Channels holds the configuration for syncing governor group members into slack channels
+type Channels struct {
+	Kick     bool              `mapstructure:"kick"`
+	Links    []ChannelLink     `mapstructure:"links"`
+	Defaults []DefaultChannels `mapstructure:"defaults"`
+}
+
+// ChannelLink links a governor group to slack channels. The link applies to all the workspaces
+// the group is linked to if no workspace is set.
+type ChannelLink struct {
+	Group     string   `mapstructure:"group"`
+	Workspace string   `mapstructure:"workspace"`
+	Channels  []string `mapstructure:"channels"`
+}
+
+// DefaultChannels sets the default channels of the slack user groups of a governor group and/or
+// application (by id or name)
+type DefaultChannels struct {
+	Group       string   `mapstructure:"group"`
+	Application string   `mapstructure:"application"`
+	Channels    []string `mapstructure:"channels"`
+}
+
+// AdoptRule binds an existing slack user group (by id or handle) in a workspace to a governor group (by id or slug)
+type AdoptRule struct {
+	Group     string `mapstructure:"group"`
+	Workspace string `mapstructure:"workspace"`
+	UserGroup string `mapstructure:"usergroup"`
+}
+
+// ExclusionRule keeps slack users (by id, email or bots) in the user groups of a governor group
+// (by id or slug), workspace and/or slack user group (by id or handle), and excludes governor users
+// (by id or email) from being synced
+type ExclusionRule struct {
+	Group               string   `mapstructure:"group"`
+	Workspace           string   `mapstructure:"workspace"`
+	UserGroup           string   `mapstructure:"usergroup"`
+	KeepSlackUsers      []string `mapstructure:"keep-slack-users"`
+	KeepEmails          []string `mapstructure:"keep-emails"`
+	KeepBots            bool     `mapstructure:"keep-bots"`
+	IgnoreGovernorUsers []string `mapstructure:"ignore-governor-users"`
 }
 
 // Reconciler holds reconciler configuration
 type Reconciler struct {
-	Interval time.Duration `mapstructure:"interval"`
-	Locking  bool          `mapstructure:"locking"`
+	Interval         time.Duration `mapstructure:"interval"`
+	Locking          bool          `mapstructure:"locking"`
+	Retention        time.Duration `mapstructure:"retention"`
+	Workers          int           `mapstructure:"workers"`
+	WorkspaceWorkers int           `mapstructure:"workspace-workers"`
+	PassTimeout      time.Duration `mapstructure:"pass-timeout"`
+	DeepInterval     time.Duration `mapstructure:"deep-interval"`
+}
+
+// Store holds the addon state store configuration
+type Store struct {
+	Bucket string `mapstructure:"bucket"`
+}
+
+// Reports holds the reporting configuration
+type Reports struct {
+	UnmatchedChannel  string        `mapstructure:"unmatched-channel"`
+	UnmatchedInterval time.Duration `mapstructure:"unmatched-interval"`
+}
+
+// Notifications holds the configuration for notifying users of their user group membership changes
+type Notifications struct {
+	Enabled   bool                  `mapstructure:"enabled"`
+	Templates NotificationTemplates `mapstructure:"templates"`
+}
+
+// NotificationTemplates holds the go text templates for the membership notifications
+type NotificationTemplates struct {
+	Added          string `mapstructure:"added"`
+	Removed        string `mapstructure:"removed"`
+	NotInWorkspace string `mapstructure:"not-in-workspace"`
+}
+
+// Ops holds the configuration of the ops channels, where reconciliation summaries and alerts are posted
+type Ops struct {
+	Channel           string            `mapstructure:"channel"`
+	WorkspaceChannels map[string]string `mapstructure:"workspace-channels"`
+	RateLimit         int               `mapstructure:"rate-limit"`
+	RepeatInterval    time.Duration     `mapstructure:"repeat-interval"`
+}
+
+// Auditing holds the configuration of the audit sinks, the file sink writes to the audit log path
+type Auditing struct {
+	Sinks      []string       `mapstructure:"sinks"`
+	BufferSize int            `mapstructure:"buffer-size"`
+	JetStream  AuditJetStream `mapstructure:"jetstream"`
+	Webhook    AuditWebhook   `mapstructure:"webhook"`
+}
+
+// AuditJetStream holds the configuration of the jetstream audit sink
+type AuditJetStream struct {
+	Stream  string `mapstructure:"stream"`
+	Subject string `mapstructure:"subject"`
+}
+
+// AuditWebhook holds the configuration of the http webhook audit sink
+type AuditWebhook struct {
+	URL     string        `mapstructure:"url"`
+	Token   string        `mapstructure:"token"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// API holds the addon API server configuration
+type API struct {
+	Listen             string `mapstructure:"listen"`
+	Token              string `mapstructure:"token"`
+	SlackSigningSecret string `mapstructure:"slack-signing-secret"`
 }
 
 // MustSlackFlags registers Slack related flags and binds them to viper
@@ -66,6 +255,34 @@ func MustSlackFlags(v *viper.Viper, flags *pflag.FlagSet) {
 	viperBindFlag(v, "slack.token", flags.Lookup("slack-token"))
 	flags.String("slack-usergroup-prefix", "[Governor] ", "string to be prepended to slack usergroup names")
 	viperBindFlag(v, "slack.usergroup-prefix", flags.Lookup("slack-usergroup-prefix"))
+	flags.String("slack-usergroup-name-template", "", "go template for slack usergroup names, defaults to the prefix followed by the governor group name")
+	viperBindFlag(v, "slack.templates.name", flags.Lookup("slack-usergroup-name-template"))
+	flags.String("slack-usergroup-handle-template", "", "go template for slack usergroup handles, defaults to the governor group slug")
+	viperBindFlag(v, "slack.templates.handle", flags.Lookup("slack-usergroup-handle-template"))
+	flags.String("slack-usergroup-description-template", "", "go template for slack usergroup descriptions, defaults to the governor group description")
+	viperBindFlag(v, "slack.templates.description", flags.Lookup("slack-usergroup-description-template"))
+	flags.String("slack-org-id", "", "enterprise grid organization id, enables org-wide user groups shared by all the linked workspaces")
+	viperBindFlag(v, "slack.org-id", flags.Lookup("slack-org-id"))
+}
+
+// MustIdentityFlags registers identity resolution related flags and binds them to viper.
+// The override table and domain rewrites can only be set in the config file.
+// Panics on error
+func MustIdentityFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.StringSlice("identity-resolvers", []string{"email"}, "ordered list of resolvers to match governor users to slack users (override, rewrite, profile-field, email)")
+	viperBindFlag(v, "identity.resolvers", flags.Lookup("identity-resolvers"))
+	flags.Duration("identity-cache-ttl", DefaultIdentityCacheTTL, "how long to cache the slack user resolved for a governor user")
+	viperBindFlag(v, "identity.cache-ttl", flags.Lookup("identity-cache-ttl"))
+	flags.String("identity-override-bucket", "", "jetstream key-value bucket with identity overrides, keyed by base64url encoded governor email")
+	viperBindFlag(v, "identity.override-bucket", flags.Lookup("identity-override-bucket"))
+	flags.Bool("identity-rewrite-strip-plus", false, "strip plus-addressing tags from governor emails when rewriting")
+	viperBindFlag(v, "identity.rewrite.strip-plus", flags.Lookup("identity-rewrite-strip-plus"))
+	flags.String("identity-profile-field-id", "", "id of the slack custom profile field to match governor users on")
+	viperBindFlag(v, "identity.profile-field.id", flags.Lookup("identity-profile-field-id"))
+	flags.String("identity-profile-field-governor-attribute", "external-id", "governor user attribute to match the slack profile field against (external-id, id, email-local-part)")
+	viperBindFlag(v, "identity.profile-field.governor-attribute", flags.Lookup("identity-profile-field-governor-attribute"))
+	flags.Duration("identity-profile-field-refresh", DefaultIdentityProfileFieldRefresh, "interval for refreshing the slack profile field index")
+	viperBindFlag(v, "identity.profile-field.refresh", flags.Lookup("identity-profile-field-refresh"))
 }
 
 // MustGovernorFlags registers Governor related flags and binds them to viper
@@ -75,6 +292,16 @@ func MustGovernorFlags(v *viper.Viper, flags *pflag.FlagSet) {
 
 	flags.String("governor-application-type", "slack", "application type slug to be listening to events for")
 	viperBindFlag(v, "governor.application-type", flags.Lookup("governor-application-type"))
+	flags.String("governor-ui-url", "", "url of the governor ui, used to link to governor groups")
+	viperBindFlag(v, "governor.ui-url", flags.Lookup("governor-ui-url"))
+	flags.Bool("governor-membership-requests", false, "let slack users request to join governor groups from the slash command")
+	viperBindFlag(v, "governor.membership-requests", flags.Lookup("governor-membership-requests"))
+	flags.String("governor-extension-id", "", "id of the addon's governor extension, registers the addon as an interactive extension with its ERDs if set")
+	viperBindFlag(v, "governor.extension-id", flags.Lookup("governor-extension-id"))
+	flags.String("governor-extension-slug", "gov-slack-addon", "slug of the addon's governor extension, used to read and write its resources")
+	viperBindFlag(v, "governor.extension-slug", flags.Lookup("governor-extension-slug"))
+	flags.String("governor-erd-dir", "erds", "directory of the extension resource definitions registered with governor")
+	viperBindFlag(v, "governor.erd-dir", flags.Lookup("governor-erd-dir"))
 }
 
 // MustReconcilerFlags registers Reconciler related flags and binds them to viper
@@ -84,6 +311,95 @@ func MustReconcilerFlags(v *viper.Viper, flags *pflag.FlagSet) {
 	viperBindFlag(v, "reconciler.interval", flags.Lookup("reconciler-interval"))
 	flags.Bool("reconciler-locking", false, "enable reconciler locking and leader election")
 	viperBindFlag(v, "reconciler.locking", flags.Lookup("reconciler-locking"))
+	flags.Duration("reconciler-retention", 0, "how long user groups retired by the addon are kept before they're purged, never purged if zero")
+	viperBindFlag(v, "reconciler.retention", flags.Lookup("reconciler-retention"))
+	flags.Int("reconciler-workers", DefaultReconcilerWorkers, "number of groups reconciled concurrently")
+	viperBindFlag(v, "reconciler.workers", flags.Lookup("reconciler-workers"))
+	flags.Int("reconciler-workspace-workers", DefaultReconcilerWorkspaceWorkers, "number of groups reconciled concurrently in a workspace")
+	viperBindFlag(v, "reconciler.workspace-workers", flags.Lookup("reconciler-workspace-workers"))
+	flags.Duration("reconciler-pass-timeout", 0, "deadline of the group reconciliation of a pass, the groups not reconciled by then are skipped until the next pass, no deadline if zero")
+	viperBindFlag(v, "reconciler.pass-timeout", flags.Lookup("reconciler-pass-timeout"))
+	flags.Duration("reconciler-deep-interval", DefaultReconcilerDeepInterval, "interval between deep passes reconciling all the groups, the other passes skip unchanged groups, every pass is deep if zero")
+	viperBindFlag(v, "reconciler.deep-interval", flags.Lookup("reconciler-deep-interval"))
+}
+
+// MustChannelsFlags registers channel sync related flags and binds them to viper.
+// The channel links and default channels can only be set in the config file.
+// Panics on error
+func MustChannelsFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.Bool("channels-kick", false, "remove slack channel members that aren't in the linked governor group")
+	viperBindFlag(v, "channels.kick", flags.Lookup("channels-kick"))
+}
+
+// MustStoreFlags registers state store related flags and binds them to viper
+// Panics on error
+func MustStoreFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.String("store-bucket", DefaultStoreBucket, "jetstream key-value bucket for the addon state, kept in memory if empty")
+	viperBindFlag(v, "store.bucket", flags.Lookup("store-bucket"))
+}
+
+// MustReportsFlags registers reporting related flags and binds them to viper
+// Panics on error
+func MustReportsFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.String("reports-unmatched-channel", "", "slack channel id to post the unmatched members report to, disabled if empty")
+	viperBindFlag(v, "reports.unmatched-channel", flags.Lookup("reports-unmatched-channel"))
+	flags.Duration("reports-unmatched-interval", DefaultUnmatchedReportInterval, "interval for posting the unmatched members report")
+	viperBindFlag(v, "reports.unmatched-interval", flags.Lookup("reports-unmatched-interval"))
+}
+
+// MustNotificationsFlags registers membership notifications related flags and binds them to viper
+// Panics on error
+func MustNotificationsFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.Bool("notifications-enabled", false, "send slack users a direct message when they're added to or removed from user groups")
+	viperBindFlag(v, "notifications.enabled", flags.Lookup("notifications-enabled"))
+	flags.String("notifications-added-template", "", "go template for the message sent to users added to a user group")
+	viperBindFlag(v, "notifications.templates.added", flags.Lookup("notifications-added-template"))
+	flags.String("notifications-removed-template", "", "go template for the message sent to users removed from a user group")
+	viperBindFlag(v, "notifications.templates.removed", flags.Lookup("notifications-removed-template"))
+	flags.String("notifications-not-in-workspace-template", "", "go template for the message sent to users that can't be added to a user group until they join its workspace")
+	viperBindFlag(v, "notifications.templates.not-in-workspace", flags.Lookup("notifications-not-in-workspace-template"))
+}
+
+// MustOpsFlags registers ops channel related flags and binds them to viper.
+// The workspace channels can only be set in the config file.
+// Panics on error
+func MustOpsFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.String("ops-channel", "", "slack channel id to post reconciliation summaries and alerts to, disabled if empty")
+	viperBindFlag(v, "ops.channel", flags.Lookup("ops-channel"))
+	flags.Int("ops-rate-limit", DefaultOpsRateLimit, "maximum number of messages posted to an ops channel per hour")
+	viperBindFlag(v, "ops.rate-limit", flags.Lookup("ops-rate-limit"))
+	flags.Duration("ops-repeat-interval", DefaultOpsRepeatInterval, "minimum time between thread replies for a repeated alert")
+	viperBindFlag(v, "ops.repeat-interval", flags.Lookup("ops-repeat-interval"))
+}
+
+// MustAuditingFlags registers the audit sinks related flags and binds them to viper
+// Panics on error
+func MustAuditingFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.StringSlice("audit-sinks", []string{"file"}, "audit sinks the audit events are written to (file, jetstream, webhook)")
+	viperBindFlag(v, "auditing.sinks", flags.Lookup("audit-sinks"))
+	flags.Int("audit-buffer-size", DefaultAuditBufferSize, "number of audit events buffered for each sink while it's failing")
+	viperBindFlag(v, "auditing.buffer-size", flags.Lookup("audit-buffer-size"))
+	flags.String("audit-jetstream-stream", DefaultAuditStream, "jetstream stream for the audit events, created if it doesn't exist")
+	viperBindFlag(v, "auditing.jetstream.stream", flags.Lookup("audit-jetstream-stream"))
+	flags.String("audit-jetstream-subject", DefaultAuditSubject, "subject the audit events are published to")
+	viperBindFlag(v, "auditing.jetstream.subject", flags.Lookup("audit-jetstream-subject"))
+	flags.String("audit-webhook-url", "", "url the audit events are posted to")
+	viperBindFlag(v, "auditing.webhook.url", flags.Lookup("audit-webhook-url"))
+	flags.String("audit-webhook-token", "", "bearer token sent to the audit webhook")
+	viperBindFlag(v, "auditing.webhook.token", flags.Lookup("audit-webhook-token"))
+	flags.Duration("audit-webhook-timeout", DefaultAuditWebhookTimeout, "timeout of a request to the audit webhook")
+	viperBindFlag(v, "auditing.webhook.timeout", flags.Lookup("audit-webhook-timeout"))
+}
+
+// MustAPIFlags registers the addon API related flags and binds them to viper
+// Panics on error
+func MustAPIFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.String("api-listen", DefaultAPIListen, "address for the addon API to listen on")
+	viperBindFlag(v, "api.listen", flags.Lookup("api-listen"))
+	flags.String("api-token", "", "bearer token required to access the addon API admin endpoints")
+	viperBindFlag(v, "api.token", flags.Lookup("api-token"))
+	flags.String("api-slack-signing-secret", "", "slack app signing secret, enables the slack events endpoint if set")
+	viperBindFlag(v, "api.slack-signing-secret", flags.Lookup("api-slack-signing-secret"))
 }
 
 // viperBindFlag provides a wrapper around the viper bindings that handles error checks
@@ -95,26 +411,9 @@ func viperBindFlag(v *viper.Viper, name string, flag *pflag.Flag) {
 
 // NewGovernorClient returns a governor API client based on auth configs
 func NewGovernorClient(ctx context.Context, opts ...govclient.Option) (*govclient.Client, error) {
-	var (
-		ts  oauth2.TokenSource
-		err error
-	)
-
-	if AppConfig.Governor.WorkloadIdentity {
-		ts, err = AppConfig.WorkloadIdentity.ToTokenSource(ctx)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		cc := &clientcredentials.Config{
-			ClientID:       AppConfig.Governor.ClientID,
-			ClientSecret:   AppConfig.Governor.ClientSecret,
-			TokenURL:       AppConfig.Governor.TokenURL,
-			EndpointParams: url.Values{"audience": {AppConfig.Governor.Audience}},
-			Scopes:         AppConfig.Governor.Scopes,
-		}
-
-		ts = cc.TokenSource(ctx)
+	ts, err := NewGovernorTokenSource(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	opts = append(
@@ -125,3 +424,20 @@ func NewGovernorClient(ctx context.Context, opts ...govclient.Option) (*govclien
 
 	return govclient.NewClient(opts...)
 }
+
+// NewGovernorTokenSource returns the token source used to authenticate to the governor API
+func NewGovernorTokenSource(ctx context.Context) (oauth2.TokenSource, error) {
+	if AppConfig.Governor.WorkloadIdentity {
+		return AppConfig.WorkloadIdentity.ToTokenSource(ctx)
+	}
+
+	cc := &clientcredentials.Config{
+		ClientID:       AppConfig.Governor.ClientID,
+		ClientSecret:   AppConfig.Governor.ClientSecret,
+		TokenURL:       AppConfig.Governor.TokenURL,
+		EndpointParams: url.Values{"audience": {AppConfig.Governor.Audience}},
+		Scopes:         AppConfig.Governor.Scopes,
+	}
+
+	return cc.TokenSource(ctx), nil
+}
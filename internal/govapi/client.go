@@ -0,0 +1,110 @@
+package govapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+// Client is a governor API client for the calls the governor-api client doesn't provide
+type Client struct {
+	url        string
+	logger     *zap.Logger
+	httpClient *http.Client
+}
+
+// Option is a functional configuration option
+type Option func(c *Client)
+
+// WithLogger sets logger
+func WithLogger(l *zap.Logger) Option {
+	return func(c *Client) {
+		c.logger = l
+	}
+}
+
+// WithHTTPClient sets the http client used for the requests. Its transport is wrapped to
+// authenticate the requests.
+func WithHTTPClient(h *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = h
+	}
+}
+
+// NewClient returns a governor API client for the given governor url, authenticating with the token source
+func NewClient(u string, ts oauth2.TokenSource, opts ...Option) *Client {
+	c := &Client{
+		url:        strings.TrimSuffix(u, "/"),
+		logger:     zap.NewNop(),
+		httpClient: &http.Client{},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	hc := *c.httpClient
+	hc.Transport = &oauth2.Transport{Source: ts, Base: c.httpClient.Transport}
+	c.httpClient = &hc
+
+	return c
+}
+
+// do sends the request with the json encoding of body, if any, and decodes the json response into out
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	var reqBody io.Reader
+
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		reqBody = bytes.NewReader(b)
+	}
+
+	u := c.url + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	c.logger.Debug("governor api request", zap.String("method", method), zap.String("path", path))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %w: %s %s", ErrRequestNonSuccess, ErrNotFound, method, path)
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %s %s: %d", ErrRequestNonSuccess, method, path, resp.StatusCode)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,4 @@
+// Package govapi is a minimal client for the governor API calls the addon needs but the governor-api
+// client doesn't provide, such as filing group membership requests on behalf of users and managing the
+// resources of the addon's governor extension
+package govapi
@@ -0,0 +1,17 @@
+package govapi
+
+import "errors"
+
+var (
+	// ErrBadParameter is returned when bad parameters are passed to a request
+	ErrBadParameter = errors.New("bad parameters in request")
+
+	// ErrUserNotFound is returned when no governor user is found for an email
+	ErrUserNotFound = errors.New("governor user not found")
+
+	// ErrRequestNonSuccess is returned when a call to the governor API returns a non-success status
+	ErrRequestNonSuccess = errors.New("got a non-success response from governor")
+
+	// ErrNotFound is returned along with ErrRequestNonSuccess when the governor resource doesn't exist
+	ErrNotFound = errors.New("governor resource not found")
+)
@@ -0,0 +1,101 @@
+package govapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ExtensionResource is a system or group extension resource of a governor extension
+type ExtensionResource struct {
+	ID              string          `json:"id"`
+	Resource        json.RawMessage `json:"resource"`
+	ResourceVersion int64           `json:"resource_version"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
+// extensionResourcesPath returns the path of the resources of an extension resource definition
+func extensionResourcesPath(extension, erd, version string) string {
+	return "/api/v1alpha1/extension-resources/" + url.PathEscape(extension) + "/" + url.PathEscape(erd) + "/" + url.PathEscape(version)
+}
+
+// groupExtensionResourcesPath returns the path of the resources of a group scoped extension resource
+// definition for the governor group
+func groupExtensionResourcesPath(groupID, extension, erd, version string) string {
+	return "/api/v1alpha1/groups/" + url.PathEscape(groupID) + "/extension-resources/" + url.PathEscape(extension) + "/" + url.PathEscape(erd) + "/" + url.PathEscape(version)
+}
+
+// ExtensionResources returns the system resources of the extension resource definition, identified by
+// the extension slug, the plural slug of the definition and its version
+func (c *Client) ExtensionResources(ctx context.Context, extension, erd, version string) ([]*ExtensionResource, error) {
+	if extension == "" || erd == "" || version == "" {
+		return nil, ErrBadParameter
+	}
+
+	out := []*ExtensionResource{}
+
+	if err := c.do(ctx, http.MethodGet, extensionResourcesPath(extension, erd, version), nil, nil, &out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// GroupExtensionResources returns the resources of the governor group for a group scoped extension
+// resource definition, identified by the extension slug, the plural slug of the definition and its version
+func (c *Client) GroupExtensionResources(ctx context.Context, groupID, extension, erd, version string) ([]*ExtensionResource, error) {
+	if groupID == "" || extension == "" || erd == "" || version == "" {
+		return nil, ErrBadParameter
+	}
+
+	out := []*ExtensionResource{}
+
+	if err := c.do(ctx, http.MethodGet, groupExtensionResourcesPath(groupID, extension, erd, version), nil, nil, &out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// CreateExtensionResource creates a system resource of the extension resource definition. The resource
+// is validated by governor against the schema of the definition.
+func (c *Client) CreateExtensionResource(ctx context.Context, extension, erd, version string, resource any) (*ExtensionResource, error) {
+	if extension == "" || erd == "" || version == "" || resource == nil {
+		return nil, ErrBadParameter
+	}
+
+	out := &ExtensionResource{}
+
+	if err := c.do(ctx, http.MethodPost, extensionResourcesPath(extension, erd, version), nil, resource, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// UpdateExtensionResource replaces a system resource of the extension resource definition
+func (c *Client) UpdateExtensionResource(ctx context.Context, extension, erd, version, id string, resource any) (*ExtensionResource, error) {
+	if extension == "" || erd == "" || version == "" || id == "" || resource == nil {
+		return nil, ErrBadParameter
+	}
+
+	out := &ExtensionResource{}
+
+	if err := c.do(ctx, http.MethodPatch, extensionResourcesPath(extension, erd, version)+"/"+url.PathEscape(id), nil, resource, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// DeleteExtensionResource deletes a system resource of the extension resource definition
+func (c *Client) DeleteExtensionResource(ctx context.Context, extension, erd, version, id string) error {
+	if extension == "" || erd == "" || version == "" || id == "" {
+		return ErrBadParameter
+	}
+
+	return c.do(ctx, http.MethodDelete, extensionResourcesPath(extension, erd, version)+"/"+url.PathEscape(id), nil, nil, nil)
+}
@@ -0,0 +1,139 @@
+package govapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func extensionsTestServer(t *testing.T) *Client {
+	t.Helper()
+
+	const path = "/api/v1alpha1/extension-resources/gov-slack-addon/slack-usergroup-settings/v1alpha1"
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET "+path, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":"res-1","resource":{"group_id":"group-1"},"resource_version":2}]`))
+	})
+
+	mux.HandleFunc("GET /api/v1alpha1/groups/group-1/extension-resources/gov-slack-addon/slack-usergroup-settings/v1alpha1", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":"res-3","resource":{"handle":"team-x"},"resource_version":1}]`))
+	})
+
+	mux.HandleFunc("POST "+path, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.NewEncoder(w).Encode(ExtensionResource{ID: "res-2", Resource: body, ResourceVersion: 1})
+	})
+
+	mux.HandleFunc("PATCH "+path+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") != "res-1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		_ = json.NewEncoder(w).Encode(ExtensionResource{ID: "res-1", Resource: body, ResourceVersion: 3})
+	})
+
+	mux.HandleFunc("DELETE "+path+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") != "res-1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return NewClient(srv.URL, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "t0ken"}))
+}
+
+func TestClient_ExtensionResources(t *testing.T) {
+	c := extensionsTestServer(t)
+
+	got, err := c.ExtensionResources(context.Background(), "gov-slack-addon", "slack-usergroup-settings", "v1alpha1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 1 || got[0].ID != "res-1" || string(got[0].Resource) != `{"group_id":"group-1"}` {
+		t.Errorf("unexpected resources %+v", got)
+	}
+
+	if _, err := c.ExtensionResources(context.Background(), "", "slack-usergroup-settings", "v1alpha1"); !errors.Is(err, ErrBadParameter) {
+		t.Errorf("expected ErrBadParameter, got %v", err)
+	}
+}
+
+func TestClient_GroupExtensionResources(t *testing.T) {
+	c := extensionsTestServer(t)
+
+	got, err := c.GroupExtensionResources(context.Background(), "group-1", "gov-slack-addon", "slack-usergroup-settings", "v1alpha1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 1 || got[0].ID != "res-3" || string(got[0].Resource) != `{"handle":"team-x"}` {
+		t.Errorf("unexpected resources %+v", got)
+	}
+
+	if _, err := c.GroupExtensionResources(context.Background(), "", "gov-slack-addon", "slack-usergroup-settings", "v1alpha1"); !errors.Is(err, ErrBadParameter) {
+		t.Errorf("expected ErrBadParameter, got %v", err)
+	}
+}
+
+func TestClient_CreateExtensionResource(t *testing.T) {
+	c := extensionsTestServer(t)
+
+	got, err := c.CreateExtensionResource(context.Background(), "gov-slack-addon", "slack-usergroup-settings", "v1alpha1",
+		map[string]string{"group_id": "group-2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.ID != "res-2" || string(got.Resource) != `{"group_id":"group-2"}` {
+		t.Errorf("unexpected resource %+v", got)
+	}
+}
+
+func TestClient_UpdateExtensionResource(t *testing.T) {
+	c := extensionsTestServer(t)
+
+	got, err := c.UpdateExtensionResource(context.Background(), "gov-slack-addon", "slack-usergroup-settings", "v1alpha1", "res-1",
+		map[string]string{"group_id": "group-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.ResourceVersion != 3 {
+		t.Errorf("unexpected resource %+v", got)
+	}
+
+	_, err = c.UpdateExtensionResource(context.Background(), "gov-slack-addon", "slack-usergroup-settings", "v1alpha1", "nope",
+		map[string]string{"group_id": "group-1"})
+	if !errors.Is(err, ErrRequestNonSuccess) {
+		t.Errorf("expected ErrRequestNonSuccess, got %v", err)
+	}
+}
+
+func TestClient_DeleteExtensionResource(t *testing.T) {
+	c := extensionsTestServer(t)
+
+	if err := c.DeleteExtensionResource(context.Background(), "gov-slack-addon", "slack-usergroup-settings", "v1alpha1", "res-1"); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	err := c.DeleteExtensionResource(context.Background(), "gov-slack-addon", "slack-usergroup-settings", "v1alpha1", "nope")
+	if !errors.Is(err, ErrRequestNonSuccess) {
+		t.Errorf("expected ErrRequestNonSuccess, got %v", err)
+	}
+}
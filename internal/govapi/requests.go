@@ -0,0 +1,134 @@
+package govapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/metal-toolbox/governor-api/pkg/api/v1alpha1"
+)
+
+// MembershipRequestStatus is the status of a governor group membership request
+type MembershipRequestStatus string
+
+const (
+	// MembershipRequestPending is the status of a request waiting for the group admins
+	MembershipRequestPending MembershipRequestStatus = "pending"
+	// MembershipRequestApproved is the status of a request approved by the group admins
+	MembershipRequestApproved MembershipRequestStatus = "approved"
+	// MembershipRequestDenied is the status of a request denied by the group admins
+	MembershipRequestDenied MembershipRequestStatus = "denied"
+	// MembershipRequestRevoked is the status of a request withdrawn by the user
+	MembershipRequestRevoked MembershipRequestStatus = "revoked"
+)
+
+// MembershipRequest is a governor group membership request
+type MembershipRequest struct {
+	ID        string                  `json:"id"`
+	GroupID   string                  `json:"group_id"`
+	UserID    string                  `json:"user_id"`
+	IsAdmin   bool                    `json:"is_admin"`
+	Note      string                  `json:"note"`
+	Status    MembershipRequestStatus `json:"status,omitempty"`
+	CreatedAt time.Time               `json:"created_at"`
+}
+
+// requestPath returns the path of the membership requests of the group, or of one of its requests
+func requestPath(groupID string, requestID ...string) string {
+	p := "/api/v1alpha1/groups/" + url.PathEscape(groupID) + "/requests"
+
+	for _, id := range requestID {
+		p += "/" + url.PathEscape(id)
+	}
+
+	return p
+}
+
+// membershipRequestReq is the body of a membership request creation
+type membershipRequestReq struct {
+	UserID  string `json:"user_id"`
+	IsAdmin bool   `json:"is_admin"`
+	Note    string `json:"note"`
+}
+
+// UserByEmail returns the governor user with the given email
+func (c *Client) UserByEmail(ctx context.Context, email string) (*v1alpha1.User, error) {
+	if email == "" {
+		return nil, ErrBadParameter
+	}
+
+	users := []*v1alpha1.User{}
+
+	if err := c.do(ctx, http.MethodGet, "/api/v1alpha1/users", url.Values{"email": {email}}, nil, &users); err != nil {
+		return nil, err
+	}
+
+	for _, u := range users {
+		if strings.EqualFold(u.Email, email) {
+			return u, nil
+		}
+	}
+
+	return nil, ErrUserNotFound
+}
+
+// CreateGroupMembershipRequest files a request for the governor user to join the group, to be
+// approved by the group admins
+func (c *Client) CreateGroupMembershipRequest(ctx context.Context, groupID, userID, note string) (*MembershipRequest, error) {
+	if groupID == "" || userID == "" {
+		return nil, ErrBadParameter
+	}
+
+	out := &MembershipRequest{}
+
+	if err := c.do(ctx, http.MethodPost, requestPath(groupID), nil, membershipRequestReq{
+		UserID: userID,
+		Note:   note,
+	}, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// GroupMembershipRequests returns the pending membership requests of the group
+func (c *Client) GroupMembershipRequests(ctx context.Context, groupID string) ([]*MembershipRequest, error) {
+	if groupID == "" {
+		return nil, ErrBadParameter
+	}
+
+	out := []*MembershipRequest{}
+
+	if err := c.do(ctx, http.MethodGet, requestPath(groupID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// GroupMembershipRequest returns a membership request of the group, whatever its status. ErrNotFound
+// is returned if governor no longer has the request.
+func (c *Client) GroupMembershipRequest(ctx context.Context, groupID, requestID string) (*MembershipRequest, error) {
+	if groupID == "" || requestID == "" {
+		return nil, ErrBadParameter
+	}
+
+	out := &MembershipRequest{}
+
+	if err := c.do(ctx, http.MethodGet, requestPath(groupID, requestID), nil, nil, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// DeleteGroupMembershipRequest withdraws a membership request of the group
+func (c *Client) DeleteGroupMembershipRequest(ctx context.Context, groupID, requestID string) error {
+	if groupID == "" || requestID == "" {
+		return ErrBadParameter
+	}
+
+	return c.do(ctx, http.MethodDelete, requestPath(groupID, requestID), nil, nil, nil)
+}
@@ -0,0 +1,133 @@
+package govapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func testServer(t *testing.T) *Client {
+	t.Helper()
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/v1alpha1/users", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("email") == "ada@example.com" {
+			_, _ = w.Write([]byte(`[{"id":"user-1","email":"Ada@example.com"}]`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	mux.HandleFunc("POST /api/v1alpha1/groups/{id}/requests", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t0ken" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		req := membershipRequestReq{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(MembershipRequest{ID: "req-1", GroupID: r.PathValue("id"), UserID: req.UserID, Note: req.Note})
+	})
+
+	mux.HandleFunc("GET /api/v1alpha1/groups/{id}/requests", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") != "group-1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write([]byte(`[{"id":"req-1","group_id":"group-1","user_id":"user-1"}]`))
+	})
+
+	mux.HandleFunc("GET /api/v1alpha1/groups/{id}/requests/{rid}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("rid") != "req-1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"id":"req-1","group_id":"group-1","user_id":"user-1","status":"denied"}`))
+	})
+
+	mux.HandleFunc("DELETE /api/v1alpha1/groups/{id}/requests/{rid}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return NewClient(srv.URL+"/", oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "t0ken"}))
+}
+
+func TestClient_UserByEmail(t *testing.T) {
+	c := testServer(t)
+
+	u, err := c.UserByEmail(context.TODO(), "ada@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if u.ID != "user-1" {
+		t.Errorf("UserByEmail() = %+v", u)
+	}
+
+	if _, err := c.UserByEmail(context.TODO(), "bob@example.com"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("expected ErrUserNotFound, got %v", err)
+	}
+
+	if _, err := c.UserByEmail(context.TODO(), ""); !errors.Is(err, ErrBadParameter) {
+		t.Errorf("expected ErrBadParameter, got %v", err)
+	}
+}
+
+func TestClient_GroupMembershipRequests(t *testing.T) {
+	c := testServer(t)
+
+	req, err := c.CreateGroupMembershipRequest(context.TODO(), "group-1", "user-1", "requested from slack")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if req.ID != "req-1" || req.GroupID != "group-1" || req.UserID != "user-1" || req.Note != "requested from slack" {
+		t.Errorf("CreateGroupMembershipRequest() = %+v", req)
+	}
+
+	reqs, err := c.GroupMembershipRequests(context.TODO(), "group-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(reqs) != 1 || reqs[0].ID != "req-1" {
+		t.Errorf("GroupMembershipRequests() = %+v", reqs)
+	}
+
+	if _, err := c.GroupMembershipRequests(context.TODO(), "group-2"); !errors.Is(err, ErrRequestNonSuccess) || !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrRequestNonSuccess and ErrNotFound, got %v", err)
+	}
+
+	got, err := c.GroupMembershipRequest(context.TODO(), "group-1", "req-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got.Status != MembershipRequestDenied {
+		t.Errorf("GroupMembershipRequest() = %+v", got)
+	}
+
+	if _, err := c.GroupMembershipRequest(context.TODO(), "group-1", "req-2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	if err := c.DeleteGroupMembershipRequest(context.TODO(), "group-1", "req-1"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
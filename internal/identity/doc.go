@@ -0,0 +1,3 @@
+// Package identity resolves governor users to slack users. Resolvers can be chained so that
+// users whose slack email differs from their governor email can still be matched.
+package identity
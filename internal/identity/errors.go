@@ -0,0 +1,12 @@
+package identity
+
+import "errors"
+
+var (
+	// ErrUnknownResolver is returned when an unknown resolver name is configured
+	ErrUnknownResolver = errors.New("unknown identity resolver")
+
+	// ErrProfileIndexIncomplete is returned by the profile field resolver when a user isn't found
+	// before the slack users have all been indexed
+	ErrProfileIndexIncomplete = errors.New("slack profile field index incomplete")
+)
@@ -0,0 +1,179 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	slackgo "github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/slack"
+)
+
+// list of the supported resolver names
+const (
+	ResolverEmail        = "email"
+	ResolverOverride     = "override"
+	ResolverProfileField = "profile-field"
+	ResolverRewrite      = "rewrite"
+)
+
+// User is the governor user to be resolved to a slack user
+type User struct {
+	ID    string
+	Email string
+}
+
+// Resolver resolves a governor user to a slack user. Implementations return
+// slack.ErrSlackUserNotFound when they can't find a matching user, any other
+// error means the lookup itself failed.
+type Resolver interface {
+	Name() string
+	Resolve(ctx context.Context, u User) (*slackgo.User, error)
+}
+
+type slackClient interface {
+	GetUser(ctx context.Context, id string) (*slackgo.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*slackgo.User, error)
+	ListUsers(ctx context.Context) ([]slackgo.User, error)
+	GetUserProfile(ctx context.Context, id string) (*slackgo.UserProfile, error)
+}
+
+// Chain tries each of its resolvers in order and returns the first match
+type Chain struct {
+	logger    *zap.Logger
+	resolvers []Resolver
+}
+
+// NewChain returns a resolver that tries the given resolvers in order
+func NewChain(logger *zap.Logger, resolvers ...Resolver) *Chain {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	return &Chain{
+		logger:    logger,
+		resolvers: resolvers,
+	}
+}
+
+// Name returns the resolver name
+func (c *Chain) Name() string {
+	names := make([]string, 0, len(c.resolvers))
+	for _, r := range c.resolvers {
+		names = append(names, r.Name())
+	}
+
+	return strings.Join(names, ",")
+}
+
+// Resolve returns the slack user from the first resolver that finds a match. If any resolver
+// fails with an error other than the user not being found, the error is returned immediately.
+func (c *Chain) Resolve(ctx context.Context, u User) (*slackgo.User, error) {
+	for _, r := range c.resolvers {
+		su, err := r.Resolve(ctx, u)
+		if err == nil {
+			c.logger.Debug("resolved slack user",
+				zap.String("identity.resolver", r.Name()),
+				zap.String("governor.user.id", u.ID),
+				zap.String("user.email", u.Email),
+				zap.String("slack.user.id", su.ID),
+			)
+
+			return su, nil
+		}
+
+		if !errors.Is(err, slack.ErrSlackUserNotFound) {
+			return nil, err
+		}
+	}
+
+	return nil, slack.ErrSlackUserNotFound
+}
+
+type cacheEntry struct {
+	user    *slackgo.User
+	err     error
+	expires time.Time
+}
+
+// Cache caches the results of a resolver per governor user. Both matches and
+// misses are cached, lookup failures are not.
+type Cache struct {
+	resolver Resolver
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	now     func() time.Time
+}
+
+// NewCache returns a resolver that caches the results of the given resolver for the ttl
+func NewCache(r Resolver, ttl time.Duration) *Cache {
+	return &Cache{
+		resolver: r,
+		ttl:      ttl,
+		entries:  make(map[string]cacheEntry),
+		now:      time.Now,
+	}
+}
+
+// Name returns the resolver name
+func (c *Cache) Name() string {
+	return c.resolver.Name()
+}
+
+// Resolve returns the cached result for the user if it hasn't expired, otherwise it
+// calls the underlying resolver
+func (c *Cache) Resolve(ctx context.Context, u User) (*slackgo.User, error) {
+	key := u.ID
+	if key == "" {
+		key = strings.ToLower(u.Email)
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+
+	if ok && c.now().Before(entry.expires) {
+		return entry.user, entry.err
+	}
+
+	su, err := c.resolver.Resolve(ctx, u)
+	if err != nil && !errors.Is(err, slack.ErrSlackUserNotFound) {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[key] = cacheEntry{user: su, err: err, expires: c.now().Add(c.ttl)}
+	c.mu.Unlock()
+
+	return su, err
+}
+
+// EmailResolver matches users by their lowercased governor email
+type EmailResolver struct {
+	client slackClient
+}
+
+// NewEmailResolver returns a resolver that matches users by email
+func NewEmailResolver(client slackClient) *EmailResolver {
+	return &EmailResolver{client: client}
+}
+
+// Name returns the resolver name
+func (r *EmailResolver) Name() string {
+	return ResolverEmail
+}
+
+// Resolve looks up the slack user by the governor user's email
+func (r *EmailResolver) Resolve(ctx context.Context, u User) (*slackgo.User, error) {
+	if u.Email == "" {
+		return nil, slack.ErrSlackUserNotFound
+	}
+
+	return r.client.GetUserByEmail(ctx, strings.ToLower(u.Email))
+}
@@ -0,0 +1,361 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	slackgo "github.com/slack-go/slack"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/slack"
+)
+
+type mockSlackClient struct {
+	err          error
+	profileErr   error
+	calls        int
+	profileCalls int
+	byID         map[string]*slackgo.User
+	byMail       map[string]*slackgo.User
+	users        []slackgo.User
+	profiles     map[string]*slackgo.UserProfile
+}
+
+func (m *mockSlackClient) GetUser(_ context.Context, id string) (*slackgo.User, error) {
+	m.calls++
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	if u, ok := m.byID[id]; ok {
+		return u, nil
+	}
+
+	return nil, slack.ErrSlackUserNotFound
+}
+
+func (m *mockSlackClient) GetUserByEmail(_ context.Context, email string) (*slackgo.User, error) {
+	m.calls++
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	if u, ok := m.byMail[email]; ok {
+		return u, nil
+	}
+
+	return nil, slack.ErrSlackUserNotFound
+}
+
+func (m *mockSlackClient) ListUsers(_ context.Context) ([]slackgo.User, error) {
+	m.calls++
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return m.users, nil
+}
+
+func (m *mockSlackClient) GetUserProfile(_ context.Context, id string) (*slackgo.UserProfile, error) {
+	m.profileCalls++
+
+	if m.profileErr != nil {
+		return nil, m.profileErr
+	}
+
+	if p, ok := m.profiles[id]; ok {
+		return p, nil
+	}
+
+	return nil, slack.ErrSlackUserNotFound
+}
+
+func newMockSlackClient() *mockSlackClient {
+	return &mockSlackClient{
+		byID: map[string]*slackgo.User{
+			"U0001": {ID: "U0001"},
+		},
+		byMail: map[string]*slackgo.User{
+			"user1@example.com": {ID: "U0001"},
+			"user2@new.example": {ID: "U0002"},
+			"user3@example.com": {ID: "U0003"},
+		},
+	}
+}
+
+func TestChain_Resolve(t *testing.T) {
+	client := newMockSlackClient()
+
+	tests := []struct {
+		name    string
+		chain   *Chain
+		user    User
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "email match",
+			chain: NewChain(nil, NewEmailResolver(client)),
+			user:  User{ID: "gov-1", Email: "User1@Example.com"},
+			want:  "U0001",
+		},
+		{
+			name: "override before email",
+			chain: NewChain(nil,
+				NewOverrideResolver(client, map[string]string{"user3@example.com": "U0001"}),
+				NewEmailResolver(client),
+			),
+			user: User{ID: "gov-3", Email: "user3@example.com"},
+			want: "U0001",
+		},
+		{
+			name: "falls through to rewrite",
+			chain: NewChain(nil,
+				NewEmailResolver(client),
+				NewRewriteResolver(client, map[string]string{"old.example": "new.example"}, false),
+			),
+			user: User{ID: "gov-2", Email: "user2@old.example"},
+			want: "U0002",
+		},
+		{
+			name:    "not found",
+			chain:   NewChain(nil, NewEmailResolver(client)),
+			user:    User{ID: "gov-4", Email: "user4@example.com"},
+			wantErr: slack.ErrSlackUserNotFound,
+		},
+		{
+			name: "lookup error stops the chain",
+			chain: NewChain(nil,
+				NewEmailResolver(&mockSlackClient{err: slack.ErrSlackAPI}),
+				NewEmailResolver(client),
+			),
+			user:    User{ID: "gov-1", Email: "user1@example.com"},
+			wantErr: slack.ErrSlackAPI,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.chain.Resolve(context.TODO(), tt.user)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Chain.Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && got.ID != tt.want {
+				t.Errorf("Chain.Resolve() = %v, want %v", got.ID, tt.want)
+			}
+		})
+	}
+}
+
+func TestCache_Resolve(t *testing.T) {
+	client := newMockSlackClient()
+	now := time.Now()
+
+	c := NewCache(NewEmailResolver(client), time.Minute)
+	c.now = func() time.Time { return now }
+
+	for range 2 {
+		if _, err := c.Resolve(context.TODO(), User{ID: "gov-1", Email: "user1@example.com"}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if _, err := c.Resolve(context.TODO(), User{ID: "gov-4", Email: "user4@example.com"}); !errors.Is(err, slack.ErrSlackUserNotFound) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	}
+
+	if client.calls != 2 {
+		t.Errorf("expected 2 slack calls, got %d", client.calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+
+	if _, err := c.Resolve(context.TODO(), User{ID: "gov-1", Email: "user1@example.com"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if client.calls != 3 {
+		t.Errorf("expected expired entry to be resolved again, got %d calls", client.calls)
+	}
+}
+
+func TestRewriteResolver_candidates(t *testing.T) {
+	r := NewRewriteResolver(nil, map[string]string{"Old.Example": "new.example"}, true)
+
+	tests := []struct {
+		email string
+		want  []string
+	}{
+		{
+			email: "user+tag@old.example",
+			want:  []string{"user@new.example", "user+tag@new.example", "user@old.example"},
+		},
+		{
+			email: "user@old.example",
+			want:  []string{"user@new.example"},
+		},
+		{
+			email: "user@other.example",
+			want:  []string{},
+		},
+		{
+			email: "invalid",
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			got := r.candidates(tt.email)
+			if len(got) != len(tt.want) {
+				t.Fatalf("candidates() = %v, want %v", got, tt.want)
+			}
+
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("candidates() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestProfileFieldResolver_Resolve(t *testing.T) {
+	// slack doesn't list the custom profile fields, they're fetched with the user profiles
+	p1 := &slackgo.UserProfile{}
+	p1.SetFieldsMap(map[string]slackgo.UserProfileCustomField{"Xf0001": {Value: " E-123 "}})
+
+	// the fields returned with the listed users are used as is
+	u3 := slackgo.User{ID: "U0003"}
+	u3.Profile.SetFieldsMap(map[string]slackgo.UserProfileCustomField{"Xf0001": {Value: "E-789"}})
+
+	client := &mockSlackClient{
+		users: []slackgo.User{
+			{ID: "U0001"},
+			{ID: "U0002"},
+			u3,
+			{ID: "U0004", IsBot: true},
+		},
+		profiles: map[string]*slackgo.UserProfile{
+			"U0001": p1,
+			"U0002": {},
+		},
+	}
+
+	r := NewProfileFieldResolver(client, "Xf0001", func(_ context.Context, u User) (string, error) {
+		return u.ID, nil
+	}, time.Hour, WithProfileFetchInterval(0))
+
+	// the lookups don't wait for the index to be loaded
+	if _, err := r.Resolve(context.TODO(), User{ID: "e-123"}); err == nil || errors.Is(err, slack.ErrSlackUserNotFound) {
+		t.Errorf("expected the user to be looked up while the index is loading, got %v", err)
+	}
+
+	waitForIndex(r)
+
+	got, err := r.Resolve(context.TODO(), User{ID: "e-123"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if got.ID != "U0001" {
+		t.Errorf("expected U0001, got %s", got.ID)
+	}
+
+	if got, err := r.Resolve(context.TODO(), User{ID: "e-789"}); err != nil || got.ID != "U0003" {
+		t.Errorf("expected U0003, got %v, %v", got, err)
+	}
+
+	if _, err := r.Resolve(context.TODO(), User{ID: "e-456"}); !errors.Is(err, slack.ErrSlackUserNotFound) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+
+	if client.calls != 1 {
+		t.Errorf("expected the slack users to be listed once, got %d", client.calls)
+	}
+
+	if client.profileCalls != 2 {
+		t.Errorf("expected 2 profiles to be fetched, got %d", client.profileCalls)
+	}
+
+	// the profiles of the users that weren't updated since aren't fetched again on refresh
+	r.nextLoad = time.Now()
+	waitForIndex(r)
+
+	if client.calls != 2 || client.profileCalls != 2 {
+		t.Errorf("expected the users to be listed again without fetching the profiles, got %d lists and %d profiles", client.calls, client.profileCalls)
+	}
+
+	// the cached field value is kept if the profile of an updated user can't be fetched
+	client.users[0].Updated = 1
+	client.profileErr = errors.New("boom") //nolint:err113
+	r.nextLoad = time.Now()
+	waitForIndex(r)
+
+	if got, err := r.Resolve(context.TODO(), User{ID: "e-123"}); err != nil || got.ID != "U0001" {
+		t.Errorf("expected U0001 from the cached profile, got %v, %v", got, err)
+	}
+}
+
+func TestProfileFieldResolver_partialIndex(t *testing.T) {
+	p2 := &slackgo.UserProfile{}
+	p2.SetFieldsMap(map[string]slackgo.UserProfileCustomField{"Xf0001": {Value: "E-2"}})
+
+	client := &mockSlackClient{
+		users:    []slackgo.User{{ID: "U0001"}, {ID: "U0002"}},
+		profiles: map[string]*slackgo.UserProfile{"U0002": p2},
+	}
+
+	// the profile of U0001 can't be fetched
+	r := NewProfileFieldResolver(&failingProfiles{mockSlackClient: client, fail: "U0001"}, "Xf0001", func(_ context.Context, u User) (string, error) {
+		return u.ID, nil
+	}, time.Hour, WithProfileFetchInterval(0))
+
+	r.refreshIndex(context.TODO())
+	waitForIndex(r)
+
+	// the users indexed are kept, the others can't be reported as not found
+	if got, err := r.Resolve(context.TODO(), User{ID: "e-2"}); err != nil || got.ID != "U0002" {
+		t.Errorf("expected U0002, got %v, %v", got, err)
+	}
+
+	if _, err := r.Resolve(context.TODO(), User{ID: "e-1"}); !errors.Is(err, ErrProfileIndexIncomplete) {
+		t.Errorf("expected the incomplete index error, got %v", err)
+	}
+
+	if r.nextLoad.After(time.Now().Add(profileRetryInterval)) {
+		t.Errorf("expected the incomplete index to be loaded again sooner, next load at %s", r.nextLoad)
+	}
+}
+
+// failingProfiles fails to fetch the profile of one of the users
+type failingProfiles struct {
+	*mockSlackClient
+	fail string
+}
+
+func (f *failingProfiles) GetUserProfile(ctx context.Context, id string) (*slackgo.UserProfile, error) {
+	if id == f.fail {
+		return nil, errors.New("boom") //nolint:err113
+	}
+
+	return f.mockSlackClient.GetUserProfile(ctx, id)
+}
+
+// waitForIndex starts loading the index if it's stale and waits for the load to end
+func waitForIndex(r *ProfileFieldResolver) {
+	if done := r.refreshIndex(context.TODO()); done != nil {
+		<-done
+	}
+}
+
+func TestOverrideKey(t *testing.T) {
+	if got := OverrideKey("User+Tag@Example.com"); got != OverrideKey("user+tag@example.com") {
+		t.Errorf("expected override keys to be case insensitive, got %s", got)
+	}
+}
@@ -0,0 +1,113 @@
+package identity
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+	slackgo "github.com/slack-go/slack"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/slack"
+)
+
+// OverrideResolver matches users from an explicit table of governor emails (or governor user
+// ids) to slack emails (or slack user ids). The table can come from the configuration and/or
+// a JetStream key-value bucket, with the configuration taking precedence.
+type OverrideResolver struct {
+	client    slackClient
+	overrides map[string]string
+	kv        nats.KeyValue
+}
+
+// OverrideOption is a functional configuration option for the override resolver
+type OverrideOption func(r *OverrideResolver)
+
+// WithOverrideKeyValue sets a key-value bucket to look up overrides in. Keys are built with OverrideKey.
+func WithOverrideKeyValue(kv nats.KeyValue) OverrideOption {
+	return func(r *OverrideResolver) {
+		r.kv = kv
+	}
+}
+
+// NewOverrideResolver returns a resolver for the given override table
+func NewOverrideResolver(client slackClient, overrides map[string]string, opts ...OverrideOption) *OverrideResolver {
+	r := &OverrideResolver{
+		client:    client,
+		overrides: make(map[string]string, len(overrides)),
+	}
+
+	for k, v := range overrides {
+		r.overrides[strings.ToLower(k)] = v
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// OverrideKey returns the key-value bucket key for a governor email or user id. Emails contain
+// characters that aren't valid in keys, so they are base64 (url) encoded.
+func OverrideKey(s string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strings.ToLower(s)))
+}
+
+// Name returns the resolver name
+func (r *OverrideResolver) Name() string {
+	return ResolverOverride
+}
+
+// Resolve looks up the override for the user and then the slack user it points to
+func (r *OverrideResolver) Resolve(ctx context.Context, u User) (*slackgo.User, error) {
+	target, err := r.lookup(u)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.Contains(target, "@") {
+		return r.client.GetUserByEmail(ctx, strings.ToLower(target))
+	}
+
+	return r.client.GetUser(ctx, target)
+}
+
+// lookup returns the override target for the user, trying the governor user id before the email
+func (r *OverrideResolver) lookup(u User) (string, error) {
+	keys := []string{}
+
+	for _, k := range []string{u.ID, u.Email} {
+		if k != "" {
+			keys = append(keys, strings.ToLower(k))
+		}
+	}
+
+	for _, k := range keys {
+		if target, ok := r.overrides[k]; ok && target != "" {
+			return target, nil
+		}
+	}
+
+	if r.kv == nil {
+		return "", slack.ErrSlackUserNotFound
+	}
+
+	for _, k := range keys {
+		entry, err := r.kv.Get(OverrideKey(k))
+		if err != nil {
+			if errors.Is(err, nats.ErrKeyNotFound) {
+				continue
+			}
+
+			return "", err
+		}
+
+		if target := strings.TrimSpace(string(entry.Value())); target != "" {
+			return target, nil
+		}
+	}
+
+	return "", slack.ErrSlackUserNotFound
+}
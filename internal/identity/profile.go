@@ -0,0 +1,296 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	slackgo "github.com/slack-go/slack"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/slack"
+)
+
+// AttributeFunc returns the value of a governor user attribute to match against a slack profile field
+type AttributeFunc func(ctx context.Context, u User) (string, error)
+
+// GovernorUserID is an AttributeFunc returning the governor user id
+func GovernorUserID(_ context.Context, u User) (string, error) {
+	return u.ID, nil
+}
+
+// EmailLocalPart is an AttributeFunc returning the part of the governor email before the @
+func EmailLocalPart(_ context.Context, u User) (string, error) {
+	local, _, _ := strings.Cut(u.Email, "@")
+
+	return local, nil
+}
+
+// DefaultProfileFetchInterval is the default time between two users.profile.get calls, slack
+// allows about 100 calls per minute for this tier 4 method
+const DefaultProfileFetchInterval = 600 * time.Millisecond
+
+// profileRetryInterval is how long an index missing some users is used before loading it again
+const profileRetryInterval = time.Minute
+
+// ProfileFieldResolver matches users by a custom slack profile field, such as an employee id,
+// against an attribute of the governor user. The slack users are indexed by the field value
+// and the index is refreshed periodically. Slack only returns the custom profile fields from
+// users.profile.get, so the profile of each user is fetched and cached until the user is updated.
+// The index is loaded in the background, at most one profile at a time, and the lookups never
+// wait for it: until a load has indexed every user, a user that isn't in the index yet can't be
+// told apart from a user without the field, so ErrProfileIndexIncomplete is returned instead of
+// slack.ErrSlackUserNotFound.
+type ProfileFieldResolver struct {
+	client        slackClient
+	fieldID       string
+	attribute     AttributeFunc
+	refresh       time.Duration
+	fetchInterval time.Duration
+
+	// profiles caches the field values fetched from the user profiles, it's only used by the load
+	// in progress
+	profiles map[string]profileField
+
+	mu       sync.RWMutex
+	index    map[string]*slackgo.User
+	complete bool
+	loadErr  error
+	nextLoad time.Time
+	loading  chan struct{}
+}
+
+// profileField is the cached value of the profile field of a slack user, along with the update
+// time of the user when the profile was fetched
+type profileField struct {
+	value   string
+	updated slackgo.JSONTime
+}
+
+// ProfileFieldOption is a functional configuration option for the profile field resolver
+type ProfileFieldOption func(r *ProfileFieldResolver)
+
+// WithProfileFetchInterval sets the time between two users.profile.get calls, they're not throttled if zero
+func WithProfileFetchInterval(d time.Duration) ProfileFieldOption {
+	return func(r *ProfileFieldResolver) {
+		r.fetchInterval = d
+	}
+}
+
+// NewProfileFieldResolver returns a resolver matching the slack profile field with the given id
+func NewProfileFieldResolver(
+	client slackClient,
+	fieldID string,
+	attribute AttributeFunc,
+	refresh time.Duration,
+	opts ...ProfileFieldOption,
+) *ProfileFieldResolver {
+	r := &ProfileFieldResolver{
+		client:        client,
+		fieldID:       fieldID,
+		attribute:     attribute,
+		refresh:       refresh,
+		fetchInterval: DefaultProfileFetchInterval,
+		profiles:      make(map[string]profileField),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Name returns the resolver name
+func (r *ProfileFieldResolver) Name() string {
+	return ResolverProfileField
+}
+
+// Resolve looks up the slack user whose profile field matches the governor user attribute
+func (r *ProfileFieldResolver) Resolve(ctx context.Context, u User) (*slackgo.User, error) {
+	value, err := r.attribute(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	value = normalizeFieldValue(value)
+	if value == "" {
+		return nil, slack.ErrSlackUserNotFound
+	}
+
+	r.refreshIndex(ctx)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if su, ok := r.index[value]; ok {
+		return su, nil
+	}
+
+	if !r.complete {
+		if r.loadErr != nil {
+			return nil, fmt.Errorf("%w: %w", ErrProfileIndexIncomplete, r.loadErr)
+		}
+
+		return nil, ErrProfileIndexIncomplete
+	}
+
+	return nil, slack.ErrSlackUserNotFound
+}
+
+// refreshIndex starts loading the index in the background if it's stale and isn't being loaded
+// already. It returns a channel closed once the load in progress ends, or nil if there's none.
+func (r *ProfileFieldResolver) refreshIndex(ctx context.Context) <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.loading != nil {
+		return r.loading
+	}
+
+	if r.index != nil && time.Now().Before(r.nextLoad) {
+		return nil
+	}
+
+	done := make(chan struct{})
+	r.loading = done
+
+	// the load outlives the lookup that started it
+	go r.load(context.WithoutCancel(ctx), done)
+
+	return done
+}
+
+// load rebuilds the index of slack users by profile field value and closes done once it's over.
+// The first load publishes the users as they're indexed, the next ones replace the index at the
+// end. A profile that can't be fetched is skipped, the index is then incomplete and loaded again
+// sooner, as is the index if the users can't be listed.
+func (r *ProfileFieldResolver) load(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	users, err := r.client.ListUsers(ctx)
+	if err != nil {
+		r.mu.Lock()
+		if r.index == nil {
+			r.index = map[string]*slackgo.User{}
+		}
+
+		r.loadErr = err
+		r.nextLoad = time.Now().Add(min(r.refresh, profileRetryInterval))
+		r.loading = nil
+		r.mu.Unlock()
+
+		return
+	}
+
+	r.mu.Lock()
+	first := r.index == nil
+	if first {
+		r.index = map[string]*slackgo.User{}
+	}
+	r.mu.Unlock()
+
+	var throttle <-chan time.Time
+
+	if r.fetchInterval > 0 {
+		t := time.NewTicker(r.fetchInterval)
+		defer t.Stop()
+
+		throttle = t.C
+	}
+
+	index := make(map[string]*slackgo.User, len(users))
+	seen := make(map[string]bool, len(users))
+
+	var errs []error
+
+	for i := range users {
+		if users[i].Deleted || users[i].IsBot {
+			continue
+		}
+
+		seen[users[i].ID] = true
+
+		value, fetched, err := r.fieldValue(ctx, &users[i])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", users[i].ID, err))
+		}
+
+		if value != "" {
+			index[value] = &users[i]
+
+			if first {
+				r.mu.Lock()
+				r.index[value] = &users[i]
+				r.mu.Unlock()
+			}
+		}
+
+		if fetched && throttle != nil {
+			<-throttle
+		}
+	}
+
+	// the users that were deleted or left the organization are dropped from the cache
+	for id := range r.profiles {
+		if !seen[id] {
+			delete(r.profiles, id)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.index = index
+	r.complete = len(errs) == 0
+	r.loadErr = errors.Join(errs...)
+	r.nextLoad = time.Now().Add(r.refresh)
+	r.loading = nil
+
+	if !r.complete {
+		r.nextLoad = time.Now().Add(min(r.refresh, profileRetryInterval))
+	}
+}
+
+// fieldValue returns the normalized value of the profile field of the slack user, from the listed
+// profile if slack included the field, or from the user's profile cached since the user was last
+// updated, and whether the profile was fetched. The cached value is kept if the profile can't be
+// fetched, and an error is returned if there's none.
+func (r *ProfileFieldResolver) fieldValue(ctx context.Context, u *slackgo.User) (string, bool, error) {
+	if field, ok := u.Profile.Fields.ToMap()[r.fieldID]; ok {
+		return normalizeFieldValue(field.Value), false, nil
+	}
+
+	cached, ok := r.profiles[u.ID]
+	if ok && cached.updated == u.Updated {
+		return cached.value, false, nil
+	}
+
+	profile, err := r.client.GetUserProfile(ctx, u.ID)
+
+	switch {
+	case errors.Is(err, slack.ErrSlackUserNotFound):
+		delete(r.profiles, u.ID)
+
+		return "", true, nil
+	case err != nil && ok:
+		return cached.value, true, nil
+	case err != nil:
+		return "", true, err
+	}
+
+	value := ""
+	if field, ok := profile.Fields.ToMap()[r.fieldID]; ok {
+		value = normalizeFieldValue(field.Value)
+	}
+
+	r.profiles[u.ID] = profileField{value: value, updated: u.Updated}
+
+	return value, true, nil
+}
+
+func normalizeFieldValue(v string) string {
+	return strings.ToLower(strings.TrimSpace(v))
+}
@@ -0,0 +1,106 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	slackgo "github.com/slack-go/slack"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/slack"
+)
+
+// RewriteResolver matches users by rewriting their governor email, e.g. replacing an
+// old domain with the current one or removing plus-addressing tags
+type RewriteResolver struct {
+	client    slackClient
+	domains   map[string]string
+	stripPlus bool
+}
+
+// NewRewriteResolver returns a resolver that rewrites email domains (from -> to) and
+// optionally strips plus-addressing tags before looking up the slack user
+func NewRewriteResolver(client slackClient, domains map[string]string, stripPlus bool) *RewriteResolver {
+	r := &RewriteResolver{
+		client:    client,
+		domains:   make(map[string]string, len(domains)),
+		stripPlus: stripPlus,
+	}
+
+	for from, to := range domains {
+		r.domains[strings.ToLower(from)] = strings.ToLower(to)
+	}
+
+	return r
+}
+
+// Name returns the resolver name
+func (r *RewriteResolver) Name() string {
+	return ResolverRewrite
+}
+
+// Resolve looks up the slack user by each of the rewritten emails
+func (r *RewriteResolver) Resolve(ctx context.Context, u User) (*slackgo.User, error) {
+	for _, email := range r.candidates(u.Email) {
+		su, err := r.client.GetUserByEmail(ctx, email)
+		if err == nil {
+			return su, nil
+		}
+
+		if !errors.Is(err, slack.ErrSlackUserNotFound) {
+			return nil, err
+		}
+	}
+
+	return nil, slack.ErrSlackUserNotFound
+}
+
+// candidates returns the rewritten emails to try, most specific first, excluding the original email
+func (r *RewriteResolver) candidates(email string) []string {
+	email = strings.ToLower(email)
+
+	i := strings.LastIndex(email, "@")
+	if i <= 0 {
+		return nil
+	}
+
+	local, domain := email[:i], email[i+1:]
+
+	locals := []string{local}
+
+	if r.stripPlus {
+		if j := strings.Index(local, "+"); j > 0 {
+			locals = []string{local[:j], local}
+		}
+	}
+
+	domains := []string{domain}
+
+	if to, ok := r.domains[domain]; ok && to != "" {
+		domains = []string{to, domain}
+	}
+
+	out := []string{}
+
+	for _, d := range domains {
+		for _, l := range locals {
+			c := l + "@" + d
+			if c != email && !contains(out, c) {
+				out = append(out, c)
+			}
+		}
+	}
+
+	return out
+}
+
+// contains returns true if the item is in the list
+func contains(list []string, item string) bool {
+	for _, i := range list {
+		if i == item {
+			return true
+		}
+	}
+
+	return false
+}
@@ -5,19 +5,22 @@ import (
 
 	"github.com/metal-toolbox/auditevent"
 	"github.com/metal-toolbox/gov-slack-addon/internal/auctx"
+	"github.com/metal-toolbox/gov-slack-addon/internal/tracing"
 	"github.com/metal-toolbox/governor-api/pkg/events/v1alpha1"
 	"github.com/metal-toolbox/governor-extension-sdk/pkg/eventrouter"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
 // ApplicationsLink handles a group being linked to a slack application, it
 // creates the corresponding slack user group and syncs its members.
 func (p *Processor) ApplicationsLink(ctx context.Context, payload *v1alpha1.Event) error {
-	ctx, span := p.tracer.Start(ctx, "process-applink-create")
+	ctx, span := p.tracer.Start(ctx, "process-applink-create", trace.WithAttributes(eventAttributes(payload)...))
 	defer span.End()
 
-	logger := p.logger.With(zap.String("governor.group.id", payload.GroupID))
+	logger := tracing.Logger(ctx, p.logger).With(zap.String("governor.group.id", payload.GroupID))
 
 	if payload.GroupID == "" {
 		logger.Error("bad event payload", zap.Error(ErrEventMissingGroupID))
@@ -33,6 +36,13 @@ func (p *Processor) ApplicationsLink(ctx context.Context, payload *v1alpha1.Even
 		return err
 	}
 
+	if err := p.reconciler.AttachUserGroup(ctx, payload.GroupID, payload.ApplicationID); err != nil {
+		logger.Error("error attaching user group to workspace", zap.Error(err))
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
 	if err := p.reconciler.UpdateUserGroupMembers(ctx, payload.GroupID, payload.ApplicationID); err != nil {
 		logger.Error("error setting user group members", zap.Error(err))
 		span.SetStatus(codes.Error, err.Error())
@@ -40,16 +50,23 @@ func (p *Processor) ApplicationsLink(ctx context.Context, payload *v1alpha1.Even
 		return err
 	}
 
+	if err := p.reconciler.SyncChannelMembers(ctx, payload.GroupID, payload.ApplicationID); err != nil {
+		logger.Error("error syncing channel members", zap.Error(err))
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
 	return nil
 }
 
 // ApplicationUnlink handles a group being unlinked from a slack application:
 // it deletes/disables the corresponding slack user group.
 func (p *Processor) ApplicationUnlink(ctx context.Context, payload *v1alpha1.Event) error {
-	ctx, span := p.tracer.Start(ctx, "process-applink-delete")
+	ctx, span := p.tracer.Start(ctx, "process-applink-delete", trace.WithAttributes(eventAttributes(payload)...))
 	defer span.End()
 
-	logger := p.logger.With(zap.String("governor.group.id", payload.GroupID))
+	logger := tracing.Logger(ctx, p.logger).With(zap.String("governor.group.id", payload.GroupID))
 
 	if payload.GroupID == "" {
 		logger.Error("bad event payload", zap.Error(ErrEventMissingGroupID))
@@ -70,10 +87,10 @@ func (p *Processor) ApplicationUnlink(ctx context.Context, payload *v1alpha1.Eve
 
 // MemberCreate handles a member being added to a group.
 func (p *Processor) MemberCreate(ctx context.Context, payload *v1alpha1.Event) error {
-	ctx, span := p.tracer.Start(ctx, "process-member-create")
+	ctx, span := p.tracer.Start(ctx, "process-member-create", trace.WithAttributes(eventAttributes(payload)...))
 	defer span.End()
 
-	logger := p.logger.With(zap.String("governor.group.id", payload.GroupID), zap.String("governor.user.id", payload.UserID))
+	logger := tracing.Logger(ctx, p.logger).With(zap.String("governor.group.id", payload.GroupID), zap.String("governor.user.id", payload.UserID))
 
 	if payload.GroupID == "" {
 		logger.Error("bad event payload", zap.Error(ErrEventMissingGroupID))
@@ -89,15 +106,27 @@ func (p *Processor) MemberCreate(ctx context.Context, payload *v1alpha1.Event) e
 		return err
 	}
 
+	if err := p.reconciler.SyncGroupChannels(ctx, payload.GroupID); err != nil {
+		logger.Error("error syncing channel members", zap.Error(err))
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
+	// the new member may have requested to join from slack
+	if err := p.reconciler.NotifyMembershipRequests(ctx, payload.GroupID); err != nil {
+		logger.Warn("error notifying membership requests", zap.Error(err))
+	}
+
 	return nil
 }
 
 // MemberDelete handles a member being removed from a group.
 func (p *Processor) MemberDelete(ctx context.Context, payload *v1alpha1.Event) error {
-	ctx, span := p.tracer.Start(ctx, "process-member-delete")
+	ctx, span := p.tracer.Start(ctx, "process-member-delete", trace.WithAttributes(eventAttributes(payload)...))
 	defer span.End()
 
-	logger := p.logger.With(zap.String("governor.group.id", payload.GroupID), zap.String("governor.user.id", payload.UserID))
+	logger := tracing.Logger(ctx, p.logger).With(zap.String("governor.group.id", payload.GroupID), zap.String("governor.user.id", payload.UserID))
 
 	if payload.GroupID == "" {
 		logger.Error("bad event payload", zap.Error(ErrEventMissingGroupID))
@@ -113,13 +142,34 @@ func (p *Processor) MemberDelete(ctx context.Context, payload *v1alpha1.Event) e
 		return err
 	}
 
+	if err := p.reconciler.SyncGroupChannels(ctx, payload.GroupID); err != nil {
+		logger.Error("error syncing channel members", zap.Error(err))
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
 	return nil
 }
 
+// eventAttributes returns the span attributes of a governor event
+func eventAttributes(e *v1alpha1.Event) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("governor.group.id", e.GroupID),
+		attribute.String("governor.app.id", e.ApplicationID),
+		attribute.String("governor.user.id", e.UserID),
+		attribute.String("governor.audit.id", e.AuditID),
+	}
+}
+
 func (p *Processor) auditMiddleware(next eventrouter.Handler) eventrouter.Handler {
 	return func(ctx context.Context, e *v1alpha1.Event) error {
 		subject := eventrouter.GetSubjectFromContext(ctx)
 
+		// the governor user or client that made the change is the subject of the audit events
+		subjects := p.reconciler.ActorSubjects(ctx, e.ActorID)
+		subjects["event"] = "governor"
+
 		ctx = auctx.WithAuditEvent(
 			ctx,
 			auditevent.NewAuditEventWithID(
@@ -132,10 +182,8 @@ func (p *Processor) auditMiddleware(next eventrouter.Handler) eventrouter.Handle
 						"nats.subject": subject,
 					},
 				},
-				auditevent.OutcomeSucceeded,
-				map[string]string{
-					"event": "governor",
-				},
+				auditevent.OutcomeSucceeded, // the outcome of each operation is set when its event is written
+				subjects,
 				"gov-slack-addon",
 			),
 		)
@@ -143,3 +191,15 @@ func (p *Processor) auditMiddleware(next eventrouter.Handler) eventrouter.Handle
 		return next(ctx, e)
 	}
 }
+
+// alertMiddleware alerts the ops channel when an event can't be processed
+func (p *Processor) alertMiddleware(next eventrouter.Handler) eventrouter.Handler {
+	return func(ctx context.Context, e *v1alpha1.Event) error {
+		err := next(ctx, e)
+		if err != nil {
+			p.reconciler.AlertEventFailure(ctx, eventrouter.GetSubjectFromContext(ctx), err)
+		}
+
+		return err
+	}
+}
@@ -54,17 +54,17 @@ func WithTracer(tracer trace.Tracer) Option {
 	}
 }
 
-// Register wires up the governor event handlers on the event router. The
-// gov-slack-addon does not use governor's interactive extension capability, so
-// the extension object is ignored.
+// Register wires up the governor event handlers on the event router. The group
+// settings resources of the extension are read by the reconciler, so the
+// extension object is ignored.
 func (p *Processor) Register(er eventrouter.EventRouter, _ *v1alpha1.Extension) {
 	p.logger.Info("registering governor event handlers")
 
 	// application link events: a group linked/unlinked to a slack app
-	er.Create(govevents.GovernorApplicationLinksEventSubject, p.ApplicationsLink, p.auditMiddleware)
-	er.Delete(govevents.GovernorApplicationLinksEventSubject, p.ApplicationUnlink, p.auditMiddleware)
+	er.Create(govevents.GovernorApplicationLinksEventSubject, p.ApplicationsLink, p.auditMiddleware, p.alertMiddleware)
+	er.Delete(govevents.GovernorApplicationLinksEventSubject, p.ApplicationUnlink, p.auditMiddleware, p.alertMiddleware)
 
 	// group membership events: a member added/removed from a group
-	er.Create(govevents.GovernorMembersEventSubject, p.MemberCreate, p.auditMiddleware)
-	er.Delete(govevents.GovernorMembersEventSubject, p.MemberDelete, p.auditMiddleware)
+	er.Create(govevents.GovernorMembersEventSubject, p.MemberCreate, p.auditMiddleware, p.alertMiddleware)
+	er.Delete(govevents.GovernorMembersEventSubject, p.MemberDelete, p.auditMiddleware, p.alertMiddleware)
 }
@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+type batchKeyType string
+
+const batchKey batchKeyType = "notifybatch"
+
+// Batch collects the notifications of a reconciliation pass so each user gets a single message
+type Batch struct {
+	mu      sync.Mutex
+	pending map[string][]Notification
+}
+
+// NewBatch returns a batch with the given notifications
+func NewBatch(notifications ...Notification) *Batch {
+	b := &Batch{pending: make(map[string][]Notification)}
+
+	for _, n := range notifications {
+		b.Add(n)
+	}
+
+	return b
+}
+
+// Add adds a notification to the batch, ignoring duplicates and notifications without a slack user
+func (b *Batch) Add(n Notification) {
+	if n.SlackUserID == "" {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, p := range b.pending[n.SlackUserID] {
+		if p == n {
+			return
+		}
+	}
+
+	b.pending[n.SlackUserID] = append(b.pending[n.SlackUserID], n)
+}
+
+// Len returns the number of users with pending notifications
+func (b *Batch) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.pending)
+}
+
+// users returns the users with pending notifications, sorted by id
+func (b *Batch) users() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	out := make([]string, 0, len(b.pending))
+	for u := range b.pending {
+		out = append(out, u)
+	}
+
+	sort.Strings(out)
+
+	return out
+}
+
+// get returns the pending notifications of the user
+func (b *Batch) get(userID string) []Notification {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]Notification(nil), b.pending[userID]...)
+}
+
+// WithBatch adds a notifications batch to the context
+func WithBatch(ctx context.Context, b *Batch) context.Context {
+	return context.WithValue(ctx, batchKey, b)
+}
+
+// GetBatch gets the notifications batch from the context, or nil if there's none
+func GetBatch(ctx context.Context) *Batch {
+	b, ok := ctx.Value(batchKey).(*Batch)
+	if !ok {
+		return nil
+	}
+
+	return b
+}
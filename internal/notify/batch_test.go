@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBatch_Add(t *testing.T) {
+	n := Notification{Kind: KindAdded, SlackUserID: "U0001", UserGroupID: "S0001"}
+
+	b := NewBatch(n, n, Notification{Kind: KindAdded, UserGroupID: "S0001"})
+
+	if b.Len() != 1 {
+		t.Fatalf("expected one user, got %d", b.Len())
+	}
+
+	if got := b.get("U0001"); len(got) != 1 {
+		t.Errorf("expected duplicates to be ignored, got %+v", got)
+	}
+
+	b.Add(Notification{Kind: KindRemoved, SlackUserID: "U0001", UserGroupID: "S0002"})
+
+	if got := b.get("U0001"); len(got) != 2 {
+		t.Errorf("expected two notifications, got %+v", got)
+	}
+}
+
+func TestGetBatch(t *testing.T) {
+	if GetBatch(context.Background()) != nil {
+		t.Error("expected no batch in an empty context")
+	}
+
+	b := NewBatch()
+
+	if GetBatch(WithBatch(context.Background(), b)) != b {
+		t.Error("expected the batch added to the context")
+	}
+}
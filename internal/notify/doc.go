@@ -0,0 +1,2 @@
+// Package notify sends slack direct messages to users when the addon changes their user group memberships
+package notify
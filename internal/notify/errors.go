@@ -0,0 +1,6 @@
+package notify
+
+import "errors"
+
+// ErrInvalidTemplate is returned when a notification template can't be parsed or rendered
+var ErrInvalidTemplate = errors.New("invalid notification template")
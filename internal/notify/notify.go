@@ -0,0 +1,179 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"go.uber.org/zap"
+)
+
+// Kind is the kind of membership change a user is notified about
+type Kind string
+
+const (
+	// KindAdded is used when the user was added to a user group
+	KindAdded Kind = "added"
+	// KindRemoved is used when the user was removed from a user group
+	KindRemoved Kind = "removed"
+	// KindNotInWorkspace is used when the user couldn't be added to a user group because they
+	// haven't joined its workspace
+	KindNotInWorkspace Kind = "not_in_workspace"
+
+	// DefaultAddedTemplate is the default template for users added to a user group
+	DefaultAddedTemplate = "You were added to the Slack user group *{{ .UserGroupName }}* (@{{ .UserGroupHandle }}) in {{ .Workspace }} " +
+		"because you're a member of the governor group {{ .GovernorGroupName }}."
+	// DefaultRemovedTemplate is the default template for users removed from a user group
+	DefaultRemovedTemplate = "You were removed from the Slack user group *{{ .UserGroupName }}* (@{{ .UserGroupHandle }}) in {{ .Workspace }} " +
+		"because you're no longer a member of the governor group {{ .GovernorGroupName }}."
+	// DefaultNotInWorkspaceTemplate is the default template for users that couldn't be added to a user group
+	DefaultNotInWorkspaceTemplate = "You couldn't be added to the Slack user group *{{ .UserGroupName }}* for the governor group " +
+		"{{ .GovernorGroupName }} because you haven't joined the {{ .Workspace }} workspace yet."
+)
+
+// Notification is a membership change to notify a slack user about, it's also the data available
+// to the notification templates
+type Notification struct {
+	Kind              Kind
+	SlackUserID       string
+	Workspace         string
+	UserGroupID       string
+	UserGroupName     string
+	UserGroupHandle   string
+	GovernorGroupID   string
+	GovernorGroupSlug string
+	GovernorGroupName string
+}
+
+// Templates holds the text/template sources of the notifications, empty templates fall back to the defaults
+type Templates struct {
+	Added          string
+	Removed        string
+	NotInWorkspace string
+}
+
+// Poster posts a slack message to a channel, or to a user as a direct message if given a user id
+type Poster interface {
+	PostMessage(ctx context.Context, channelID, text string) (string, error)
+}
+
+// Notifier renders the notifications and sends them to the slack users
+type Notifier struct {
+	logger    *zap.Logger
+	poster    Poster
+	dryrun    bool
+	templates map[Kind]*template.Template
+}
+
+// Option is a functional configuration option
+type Option func(n *Notifier)
+
+// WithLogger sets logger
+func WithLogger(l *zap.Logger) Option {
+	return func(n *Notifier) {
+		n.logger = l
+	}
+}
+
+// WithDryRun logs the notifications instead of sending them
+func WithDryRun(d bool) Option {
+	return func(n *Notifier) {
+		n.dryrun = d
+	}
+}
+
+// New returns a notifier sending the notifications with the poster, rendered with the given templates
+func New(p Poster, t Templates, opts ...Option) (*Notifier, error) {
+	n := &Notifier{
+		logger:    zap.NewNop(),
+		poster:    p,
+		templates: make(map[Kind]*template.Template, 3),
+	}
+
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	sources := map[Kind]string{
+		KindAdded:          DefaultAddedTemplate,
+		KindRemoved:        DefaultRemovedTemplate,
+		KindNotInWorkspace: DefaultNotInWorkspaceTemplate,
+	}
+
+	for kind, src := range map[Kind]string{
+		KindAdded:          t.Added,
+		KindRemoved:        t.Removed,
+		KindNotInWorkspace: t.NotInWorkspace,
+	} {
+		if src != "" {
+			sources[kind] = src
+		}
+	}
+
+	for kind, src := range sources {
+		tpl, err := template.New(string(kind)).Option("missingkey=error").Parse(src)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidTemplate, kind, err)
+		}
+
+		// render once with empty data so references to unknown fields are caught at startup
+		if err := tpl.Execute(&strings.Builder{}, Notification{}); err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidTemplate, kind, err)
+		}
+
+		n.templates[kind] = tpl
+	}
+
+	return n, nil
+}
+
+// Send sends one direct message to each user in the batch with all of their notifications. Failures
+// are logged and don't stop the other users from being notified.
+func (n *Notifier) Send(ctx context.Context, b *Batch) {
+	for _, userID := range b.users() {
+		logger := n.logger.With(zap.String("slack.user.id", userID))
+
+		notifications := b.get(userID)
+
+		text, err := n.render(notifications)
+		if err != nil {
+			logger.Error("failed to render notification", zap.Error(err))
+			continue
+		}
+
+		if n.dryrun {
+			logger.Info("SKIP sending membership notification", zap.String("notification", text))
+			continue
+		}
+
+		if _, err := n.poster.PostMessage(ctx, userID, text); err != nil {
+			logger.Error("failed to send membership notification", zap.Error(err))
+			continue
+		}
+
+		logger.Debug("sent membership notification", zap.Int("notifications", len(notifications)))
+	}
+}
+
+// render renders the notifications of a user, one per line
+func (n *Notifier) render(notifications []Notification) (string, error) {
+	lines := make([]string, 0, len(notifications))
+
+	for _, nt := range notifications {
+		tpl, ok := n.templates[nt.Kind]
+		if !ok {
+			return "", fmt.Errorf("%w: unknown kind %q", ErrInvalidTemplate, nt.Kind)
+		}
+
+		var b strings.Builder
+
+		if err := tpl.Execute(&b, nt); err != nil {
+			return "", fmt.Errorf("%w: %s: %w", ErrInvalidTemplate, nt.Kind, err)
+		}
+
+		lines = append(lines, strings.TrimSpace(b.String()))
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
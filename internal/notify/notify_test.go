@@ -0,0 +1,129 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type mockPoster struct {
+	sent map[string]string
+	err  error
+}
+
+func (m *mockPoster) PostMessage(_ context.Context, channelID, text string) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+
+	m.sent[channelID] = text
+
+	return "1700000000.000100", nil
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		templates Templates
+		wantErr   bool
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name:      "custom template",
+			templates: Templates{Added: "Welcome to @{{ .UserGroupHandle }}!"},
+		},
+		{
+			name:      "invalid syntax",
+			templates: Templates{Removed: "{{ .UserGroupName "},
+			wantErr:   true,
+		},
+		{
+			name:      "unknown field",
+			templates: Templates{NotInWorkspace: "{{ .Nope }}"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(&mockPoster{}, tt.templates)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr && !errors.Is(err, ErrInvalidTemplate) {
+				t.Errorf("New() error = %v, want ErrInvalidTemplate", err)
+			}
+		})
+	}
+}
+
+func TestNotifier_Send(t *testing.T) {
+	p := &mockPoster{sent: map[string]string{}}
+
+	n, err := New(p, Templates{Added: "added to @{{ .UserGroupHandle }}", Removed: "removed from @{{ .UserGroupHandle }}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n.Send(context.Background(), NewBatch(
+		Notification{Kind: KindAdded, SlackUserID: "U0001", UserGroupHandle: "team-a"},
+		Notification{Kind: KindRemoved, SlackUserID: "U0001", UserGroupHandle: "team-b"},
+		Notification{Kind: KindAdded, SlackUserID: "U0002", UserGroupHandle: "team-a"},
+	))
+
+	if len(p.sent) != 2 {
+		t.Fatalf("expected one message per user, got %v", p.sent)
+	}
+
+	if got := p.sent["U0001"]; got != "added to @team-a\nremoved from @team-b" {
+		t.Errorf("unexpected message for U0001: %q", got)
+	}
+
+	if got := p.sent["U0002"]; got != "added to @team-a" {
+		t.Errorf("unexpected message for U0002: %q", got)
+	}
+}
+
+func TestNotifier_Send_dryrun(t *testing.T) {
+	p := &mockPoster{sent: map[string]string{}}
+
+	n, err := New(p, Templates{}, WithDryRun(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n.Send(context.Background(), NewBatch(Notification{Kind: KindNotInWorkspace, SlackUserID: "U0001", Workspace: "ws"}))
+
+	if len(p.sent) != 0 {
+		t.Errorf("expected no messages in dry-run mode, got %v", p.sent)
+	}
+}
+
+func TestNotifier_render(t *testing.T) {
+	n, err := New(&mockPoster{}, Templates{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := n.render([]Notification{{
+		Kind:              KindNotInWorkspace,
+		Workspace:         "my-workspace",
+		UserGroupName:     "[Governor] Team A",
+		GovernorGroupName: "Team A",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(text, "haven't joined the my-workspace workspace") {
+		t.Errorf("unexpected default message %q", text)
+	}
+
+	if _, err := n.render([]Notification{{Kind: "nope"}}); !errors.Is(err, ErrInvalidTemplate) {
+		t.Errorf("render() error = %v, want ErrInvalidTemplate", err)
+	}
+}
@@ -0,0 +1,146 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// AlertKind is the kind of hard failure an alert is posted for
+type AlertKind string
+
+const (
+	// AlertAuth is used when slack rejects the addon token
+	AlertAuth AlertKind = "auth"
+	// AlertWorkspaceNotFound is used when a slack application's workspace isn't found in slack
+	AlertWorkspaceNotFound AlertKind = "workspace_not_found"
+	// AlertEventFailed is used when a governor event couldn't be processed
+	AlertEventFailed AlertKind = "event_failed"
+)
+
+// alertTitles are the titles of the alert messages
+var alertTitles = map[AlertKind]string{
+	AlertAuth:              "Slack rejected the addon token",
+	AlertWorkspaceNotFound: "Slack workspace not found",
+	AlertEventFailed:       "Failed to process a governor event",
+}
+
+// Alert is a hard failure to post to the ops channel right away
+type Alert struct {
+	Kind      AlertKind
+	Workspace string
+	Message   string
+}
+
+// Alert posts the alert to the ops channel. The same alert is posted once, repeats are counted and
+// replied to in its thread at most once per repeat interval, until it's been quiet for a day.
+func (r *Reporter) Alert(ctx context.Context, a Alert) {
+	if !r.Enabled() {
+		return
+	}
+
+	channel := r.channelFor(a.Workspace)
+	if channel == "" {
+		return
+	}
+
+	logger := r.logger.With(
+		zap.String("slack.channel.id", channel),
+		zap.String("alert.kind", string(a.Kind)),
+		zap.String("slack.workspace.name", a.Workspace),
+	)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	key := strings.Join([]string{channel, string(a.Kind), a.Workspace, a.Message}, "|")
+
+	if st, ok := r.alerts[key]; ok && now.Sub(st.last) < alertExpiry {
+		st.count++
+		st.last = now
+
+		if st.ts == "" || now.Sub(st.repliedAt) < r.repeatInterval || !r.allow(channel, now) {
+			logger.Debug("suppressed repeated alert", zap.Int("alert.count", st.count))
+			return
+		}
+
+		st.repliedAt = now
+
+		r.reply(ctx, logger, channel, st.ts, formatRepeat(st))
+
+		return
+	}
+
+	if !r.allow(channel, now) {
+		logger.Warn("ops channel rate limit reached, alert not posted", zap.String("alert.message", a.Message))
+		return
+	}
+
+	ts := r.post(ctx, logger, channel, formatAlert(a))
+
+	// retry posting the alert next time it happens if it failed
+	if ts == "" && !r.dryrun {
+		return
+	}
+
+	r.alerts[key] = &alertState{ts: ts, first: now, last: now, count: 1, repliedAt: now}
+}
+
+// post posts a message to the channel and returns its timestamp, it's empty if the message wasn't posted
+func (r *Reporter) post(ctx context.Context, logger *zap.Logger, channel, text string) string {
+	if r.dryrun {
+		logger.Info("SKIP posting to ops channel", zap.String("message", text))
+		return ""
+	}
+
+	ts, err := r.poster.PostMessage(ctx, channel, text)
+	if err != nil {
+		logger.Error("failed to post to ops channel", zap.Error(err))
+		return ""
+	}
+
+	return ts
+}
+
+// reply replies in the thread of a message posted to the channel
+func (r *Reporter) reply(ctx context.Context, logger *zap.Logger, channel, ts, text string) {
+	if r.dryrun {
+		logger.Info("SKIP replying in ops channel thread", zap.String("message", text))
+		return
+	}
+
+	if _, err := r.poster.PostThreadReply(ctx, channel, ts, text); err != nil {
+		logger.Error("failed to reply in ops channel thread", zap.Error(err))
+	}
+}
+
+// formatAlert renders the alert message
+func formatAlert(a Alert) string {
+	title, ok := alertTitles[a.Kind]
+	if !ok {
+		title = string(a.Kind)
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, ":rotating_light: *%s*", title)
+
+	if a.Workspace != "" {
+		fmt.Fprintf(&b, " in *%s*", a.Workspace)
+	}
+
+	if a.Message != "" {
+		fmt.Fprintf(&b, "\n```%s```", a.Message)
+	}
+
+	return b.String()
+}
+
+// formatRepeat renders the thread reply for a repeated alert
+func formatRepeat(st *alertState) string {
+	return fmt.Sprintf("Still failing: seen %d times since %s", st.count, st.first.UTC().Format(time.RFC3339))
+}
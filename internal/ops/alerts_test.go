@@ -0,0 +1,75 @@
+package ops
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReporter_Alert(t *testing.T) {
+	p := &mockPoster{}
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	r := New(p, WithChannel("C0000"), WithRepeatInterval(15*time.Minute))
+	r.now = func() time.Time { return now }
+
+	a := Alert{Kind: AlertAuth, Workspace: "ws", Message: "invalid_auth"}
+
+	r.Alert(context.Background(), a)
+
+	if len(p.posts) != 1 || !strings.Contains(p.posts[0], "Slack rejected the addon token") {
+		t.Fatalf("expected the alert to be posted, got %v", p.posts)
+	}
+
+	// repeated within the repeat interval
+	now = now.Add(5 * time.Minute)
+	r.Alert(context.Background(), a)
+
+	if len(p.posts) != 1 || len(p.replies) != 0 {
+		t.Fatalf("expected the repeated alert to be suppressed, got posts %v, replies %v", p.posts, p.replies)
+	}
+
+	// repeated after the repeat interval
+	now = now.Add(15 * time.Minute)
+	r.Alert(context.Background(), a)
+
+	if len(p.posts) != 1 || len(p.replies) != 1 || !strings.Contains(p.replies[0], "seen 3 times") {
+		t.Fatalf("expected a thread reply, got posts %v, replies %v", p.posts, p.replies)
+	}
+
+	// a different alert
+	r.Alert(context.Background(), Alert{Kind: AlertWorkspaceNotFound, Workspace: "other"})
+
+	if len(p.posts) != 2 {
+		t.Fatalf("expected the new alert to be posted, got %v", p.posts)
+	}
+
+	// quiet for more than a day
+	now = now.Add(25 * time.Hour)
+	r.Alert(context.Background(), a)
+
+	if len(p.posts) != 3 {
+		t.Errorf("expected the expired alert to be posted again, got %v", p.posts)
+	}
+}
+
+func TestReporter_Alert_dryrun(t *testing.T) {
+	p := &mockPoster{}
+
+	r := New(p, WithChannel("C0000"), WithDryRun(true))
+	r.Alert(context.Background(), Alert{Kind: AlertEventFailed, Message: "boom"})
+
+	if len(p.posts) != 0 {
+		t.Errorf("expected nothing to be posted in dry-run mode, got %v", p.posts)
+	}
+}
+
+func Test_formatAlert(t *testing.T) {
+	got := formatAlert(Alert{Kind: AlertWorkspaceNotFound, Workspace: "ws", Message: "slack workspace not found"})
+
+	want := ":rotating_light: *Slack workspace not found* in *ws*\n```slack workspace not found```"
+	if got != want {
+		t.Errorf("formatAlert() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,2 @@
+// Package ops posts reconciliation summaries and failure alerts to the slack channels of the operators
+package ops
@@ -0,0 +1,162 @@
+package ops
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultRateLimit is the default number of messages posted to a channel per hour
+	DefaultRateLimit = 20
+	// DefaultRepeatInterval is the default minimum time between thread replies for a repeated alert
+	DefaultRepeatInterval = 15 * time.Minute
+
+	// alertExpiry is how long an alert has to be quiet before it's posted again as a new message
+	alertExpiry = 24 * time.Hour
+	// summaryRepeat is how long an unchanged summary is suppressed for
+	summaryRepeat = 24 * time.Hour
+	// rateWindow is the window of the channel rate limit
+	rateWindow = time.Hour
+)
+
+// Poster posts messages and thread replies to slack channels
+type Poster interface {
+	PostMessage(ctx context.Context, channelID, text string) (string, error)
+	PostThreadReply(ctx context.Context, channelID, threadTS, text string) (string, error)
+}
+
+// Reporter posts reconciliation summaries and alerts to the ops channels, the channel of the workspace
+// if one is set and the global channel otherwise
+type Reporter struct {
+	logger            *zap.Logger
+	poster            Poster
+	dryrun            bool
+	channel           string
+	workspaceChannels map[string]string
+	rateLimit         int
+	repeatInterval    time.Duration
+	now               func() time.Time
+
+	mu        sync.Mutex
+	posts     map[string][]time.Time
+	alerts    map[string]*alertState
+	summaries map[string]summaryState
+}
+
+// alertState tracks an alert posted to a channel, repeats are replied to in its thread
+type alertState struct {
+	ts        string
+	first     time.Time
+	last      time.Time
+	count     int
+	repliedAt time.Time
+}
+
+// summaryState is the last summary posted for a workspace
+type summaryState struct {
+	text     string
+	postedAt time.Time
+}
+
+// Option is a functional configuration option
+type Option func(r *Reporter)
+
+// WithLogger sets logger
+func WithLogger(l *zap.Logger) Option {
+	return func(r *Reporter) {
+		r.logger = l
+	}
+}
+
+// WithDryRun logs the messages instead of posting them
+func WithDryRun(d bool) Option {
+	return func(r *Reporter) {
+		r.dryrun = d
+	}
+}
+
+// WithChannel sets the global ops channel id
+func WithChannel(c string) Option {
+	return func(r *Reporter) {
+		r.channel = c
+	}
+}
+
+// WithWorkspaceChannels sets the ops channel ids of the workspaces, keyed by workspace name
+func WithWorkspaceChannels(c map[string]string) Option {
+	return func(r *Reporter) {
+		r.workspaceChannels = c
+	}
+}
+
+// WithRateLimit sets the maximum number of messages posted to a channel per hour
+func WithRateLimit(n int) Option {
+	return func(r *Reporter) {
+		r.rateLimit = n
+	}
+}
+
+// WithRepeatInterval sets the minimum time between thread replies for a repeated alert
+func WithRepeatInterval(d time.Duration) Option {
+	return func(r *Reporter) {
+		r.repeatInterval = d
+	}
+}
+
+// New returns a reporter posting with the given poster
+func New(p Poster, opts ...Option) *Reporter {
+	r := &Reporter{
+		logger:         zap.NewNop(),
+		poster:         p,
+		rateLimit:      DefaultRateLimit,
+		repeatInterval: DefaultRepeatInterval,
+		now:            time.Now,
+		posts:          make(map[string][]time.Time),
+		alerts:         make(map[string]*alertState),
+		summaries:      make(map[string]summaryState),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Enabled returns true if an ops channel is configured
+func (r *Reporter) Enabled() bool {
+	return r != nil && (r.channel != "" || len(r.workspaceChannels) > 0)
+}
+
+// channelFor returns the ops channel of the workspace, falling back to the global channel
+func (r *Reporter) channelFor(workspace string) string {
+	if c, ok := r.workspaceChannels[workspace]; ok && c != "" {
+		return c
+	}
+
+	return r.channel
+}
+
+// allow records a post to the channel if it's under the rate limit, it must be called with the lock held
+func (r *Reporter) allow(channel string, now time.Time) bool {
+	recent := r.posts[channel][:0]
+
+	for _, t := range r.posts[channel] {
+		if now.Sub(t) < rateWindow {
+			recent = append(recent, t)
+		}
+	}
+
+	r.posts[channel] = recent
+
+	if r.rateLimit > 0 && len(recent) >= r.rateLimit {
+		return false
+	}
+
+	r.posts[channel] = append(recent, now)
+
+	return true
+}
@@ -0,0 +1,67 @@
+package ops
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type mockPoster struct {
+	posts   []string
+	replies []string
+}
+
+func (m *mockPoster) PostMessage(_ context.Context, channelID, text string) (string, error) {
+	m.posts = append(m.posts, channelID+": "+text)
+	return "1700000000.000100", nil
+}
+
+func (m *mockPoster) PostThreadReply(_ context.Context, channelID, threadTS, text string) (string, error) {
+	m.replies = append(m.replies, channelID+"/"+threadTS+": "+text)
+	return "1700000000.000200", nil
+}
+
+func TestReporter_Enabled(t *testing.T) {
+	var r *Reporter
+
+	if r.Enabled() {
+		t.Error("expected a nil reporter to be disabled")
+	}
+
+	if New(&mockPoster{}).Enabled() {
+		t.Error("expected a reporter without channels to be disabled")
+	}
+
+	if !New(&mockPoster{}, WithWorkspaceChannels(map[string]string{"ws": "C0001"})).Enabled() {
+		t.Error("expected a reporter with a workspace channel to be enabled")
+	}
+}
+
+func TestReporter_channelFor(t *testing.T) {
+	r := New(&mockPoster{}, WithChannel("C0000"), WithWorkspaceChannels(map[string]string{"ws-a": "C000A"}))
+
+	if got := r.channelFor("ws-a"); got != "C000A" {
+		t.Errorf("channelFor(ws-a) = %q, want C000A", got)
+	}
+
+	if got := r.channelFor("ws-b"); got != "C0000" {
+		t.Errorf("channelFor(ws-b) = %q, want the global channel", got)
+	}
+}
+
+func TestReporter_allow(t *testing.T) {
+	r := New(&mockPoster{}, WithChannel("C0000"), WithRateLimit(2))
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	if !r.allow("C0000", now) || !r.allow("C0000", now.Add(time.Minute)) {
+		t.Fatal("expected the first two posts to be allowed")
+	}
+
+	if r.allow("C0000", now.Add(2*time.Minute)) {
+		t.Error("expected the third post within the hour to be rate limited")
+	}
+
+	if !r.allow("C0000", now.Add(61*time.Minute)) {
+		t.Error("expected a post to be allowed once the first one is out of the window")
+	}
+}
@@ -0,0 +1,112 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// maxSummaryFailures is the number of failures listed in a summary
+const maxSummaryFailures = 10
+
+// Failure is an operation that failed during a reconciliation
+type Failure struct {
+	Operation string
+	Group     string
+	Error     string
+}
+
+// Summary summarizes a reconciliation of the groups of a workspace
+type Summary struct {
+	Workspace string
+	Groups    int
+	// Unchanged is the number of groups that hadn't changed since they were last reconciled, they're
+	// skipped unless the pass is deep
+	Unchanged int
+	// Skipped is the number of groups that weren't reconciled because the pass reached its deadline
+	// or was canceled
+	Skipped  int
+	Failures []Failure
+}
+
+// Summary posts the summary to the ops channel of the workspace. An unchanged summary is only posted
+// again after a day.
+func (r *Reporter) Summary(ctx context.Context, s Summary) {
+	if !r.Enabled() {
+		return
+	}
+
+	channel := r.channelFor(s.Workspace)
+	if channel == "" {
+		return
+	}
+
+	logger := r.logger.With(zap.String("slack.channel.id", channel), zap.String("slack.workspace.name", s.Workspace))
+	text := FormatSummary(s)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	key := channel + "|" + s.Workspace
+
+	if last, ok := r.summaries[key]; ok && last.text == text && now.Sub(last.postedAt) < summaryRepeat {
+		logger.Debug("summary unchanged, not posted")
+		return
+	}
+
+	if !r.allow(channel, now) {
+		logger.Warn("ops channel rate limit reached, summary not posted")
+		return
+	}
+
+	r.post(ctx, logger, channel, text)
+
+	r.summaries[key] = summaryState{text: text, postedAt: now}
+}
+
+// FormatSummary renders the summary message
+func FormatSummary(s Summary) string {
+	var b strings.Builder
+
+	workspace := s.Workspace
+	if workspace == "" {
+		workspace = "all workspaces"
+	}
+
+	fmt.Fprintf(&b, "*Reconciled %s*: %d groups", workspace, s.Groups)
+
+	if s.Unchanged > 0 {
+		fmt.Fprintf(&b, ", %d unchanged", s.Unchanged)
+	}
+
+	if s.Skipped > 0 {
+		fmt.Fprintf(&b, ", %d skipped (partial pass)", s.Skipped)
+	}
+
+	if len(s.Failures) == 0 {
+		b.WriteString(", no failures")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, ", %d failures", len(s.Failures))
+
+	for i, f := range s.Failures {
+		if i == maxSummaryFailures {
+			fmt.Fprintf(&b, "\n_and %d more_", len(s.Failures)-maxSummaryFailures)
+			break
+		}
+
+		b.WriteString("\n• " + f.Operation)
+
+		if f.Group != "" {
+			b.WriteString(" " + f.Group)
+		}
+
+		b.WriteString(": " + f.Error)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReporter_Summary(t *testing.T) {
+	p := &mockPoster{}
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	r := New(p, WithChannel("C0000"))
+	r.now = func() time.Time { return now }
+
+	s := Summary{Workspace: "ws", Groups: 3}
+
+	r.Summary(context.Background(), s)
+	r.Summary(context.Background(), s)
+
+	if len(p.posts) != 1 {
+		t.Fatalf("expected an unchanged summary to be posted once, got %v", p.posts)
+	}
+
+	s.Failures = []Failure{{Operation: "update members", Group: "team-a", Error: "boom"}}
+	r.Summary(context.Background(), s)
+
+	if len(p.posts) != 2 {
+		t.Fatalf("expected a changed summary to be posted, got %v", p.posts)
+	}
+
+	now = now.Add(25 * time.Hour)
+	r.Summary(context.Background(), s)
+
+	if len(p.posts) != 3 {
+		t.Errorf("expected an unchanged summary to be posted again after a day, got %v", p.posts)
+	}
+}
+
+func TestFormatSummary(t *testing.T) {
+	if got := FormatSummary(Summary{Workspace: "ws", Groups: 2}); got != "*Reconciled ws*: 2 groups, no failures" {
+		t.Errorf("FormatSummary() = %q", got)
+	}
+
+	if got := FormatSummary(Summary{Workspace: "ws", Groups: 6, Unchanged: 1, Skipped: 3}); got != "*Reconciled ws*: 6 groups, 1 unchanged, 3 skipped (partial pass), no failures" {
+		t.Errorf("FormatSummary() = %q", got)
+	}
+
+	s := Summary{Groups: 20}
+	for i := range 12 {
+		s.Failures = append(s.Failures, Failure{Operation: "update members", Group: fmt.Sprintf("team-%d", i), Error: "boom"})
+	}
+
+	got := FormatSummary(s)
+
+	for _, want := range []string{"*Reconciled all workspaces*: 20 groups, 12 failures", "• update members team-0: boom", "_and 2 more_"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("FormatSummary() = %q, expected to contain %q", got, want)
+		}
+	}
+
+	if strings.Contains(got, "team-10") {
+		t.Errorf("FormatSummary() = %q, expected the failures to be truncated", got)
+	}
+}
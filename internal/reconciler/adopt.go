@@ -0,0 +1,257 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/metal-toolbox/governor-api/pkg/api/v1alpha1"
+	"go.uber.org/zap"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/slack"
+	"github.com/metal-toolbox/gov-slack-addon/internal/tracing"
+)
+
+// AdoptRule binds an existing, manually created, slack user group to a governor group
+type AdoptRule struct {
+	// Group is the governor group id or slug
+	Group string
+	// Workspace is the slack workspace name
+	Workspace string
+	// UserGroup is the slack user group id or handle
+	UserGroup string
+}
+
+// Adoption is the plan for adopting an existing slack user group
+type Adoption struct {
+	Workspace         string
+	TeamID            string
+	GovernorAppID     string
+	GovernorGroupID   string
+	GovernorGroupSlug string
+
+	// UserGroup is the current state of the slack user group
+	UserGroup UserGroup
+
+	// Name, Handle and Description follow the managed naming scheme
+	Name        string
+	Handle      string
+	Description string
+	// KeepHandle leaves the user group handle unchanged
+	KeepHandle bool
+
+	// Add and Remove are the slack user ids that will be added to and removed from the user group
+	Add    []string
+	Remove []string
+	// Unmatched are the governor group members that can't be added to the user group
+	Unmatched []UnmatchedMember
+}
+
+// PlanAdoption finds the slack user group, by id or handle, in the workspace of the given application
+// and computes the changes needed to bring it under management for the governor group. No changes
+// are made in slack.
+func (r *Reconciler) PlanAdoption(ctx context.Context, groupID, appID, ref string) (*Adoption, error) {
+	if groupID == "" || appID == "" || ref == "" {
+		return nil, ErrBadParameter
+	}
+
+	isSlack, workspace, err := r.isSlackApplication(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isSlack {
+		return nil, fmt.Errorf("%w: %s is not a slack application", ErrBadParameter, appID)
+	}
+
+	group, err := r.GovernorClient.Group(ctx, groupID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	teamID, err := r.userGroupTeamID(ctx, workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.planAdoption(ctx, group, appID, workspace, teamID, ref)
+}
+
+// planAdoption computes the adoption of the slack user group by the governor group
+func (r *Reconciler) planAdoption(ctx context.Context, group *v1alpha1.Group, appID, workspace, teamID, ref string) (*Adoption, error) {
+	logger := tracing.Logger(ctx, r.Logger).With(zap.String("slack.workspace.name", workspace), zap.String("governor.group.id", group.ID))
+
+	ug, err := r.userGroupFromRef(ctx, ref, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	mappings, err := r.store.ListMappings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range mappings {
+		if m.UserGroupID == ug.ID && m.GovernorGroupID != group.ID {
+			return nil, fmt.Errorf("%w: %s is bound to governor group %s", ErrUserGroupAlreadyManaged, ug.ID, m.GovernorGroupID)
+		}
+	}
+
+	members, err := r.GovernorClient.GroupMembers(ctx, group.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	desired, unmatched, err := r.desiredMembers(ctx, logger, group, workspace, teamID, ug, members)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Adoption{
+		Workspace:         workspace,
+		TeamID:            teamID,
+		GovernorAppID:     appID,
+		GovernorGroupID:   group.ID,
+		GovernorGroupSlug: group.Slug,
+		UserGroup:         *ug,
+		Name:              r.userGroupName(group, appID, workspace),
+		Handle:            r.userGroupHandle(ctx, group, appID, workspace),
+		Description:       r.userGroupDescription(group, appID, workspace),
+		Add:               difference(desired, ug.Users),
+		Remove:            difference(ug.Users, desired),
+		Unmatched:         unmatched,
+	}, nil
+}
+
+// Adopt brings the slack user group under management: it's bound to the governor group in the
+// mapping, renamed to the managed naming scheme, and its members are synced
+func (r *Reconciler) Adopt(ctx context.Context, a *Adoption) error {
+	if a == nil {
+		return ErrBadParameter
+	}
+
+	logger := tracing.Logger(ctx, r.Logger).With(
+		zap.String("slack.workspace.name", a.Workspace),
+		zap.String("slack.usergroup.id", a.UserGroup.ID),
+		zap.String("governor.app.id", a.GovernorAppID),
+		zap.String("governor.group.id", a.GovernorGroupID),
+	)
+
+	req := slack.UserGroupReq{}
+
+	if a.UserGroup.Name != a.Name {
+		req.Name = &a.Name
+	}
+
+	if !a.KeepHandle && a.UserGroup.Handle != a.Handle {
+		req.Handle = &a.Handle
+	}
+
+	if a.UserGroup.Description != a.Description {
+		req.Description = &a.Description
+	}
+
+	target := map[string]string{
+		"slack.workspace.name":       a.Workspace,
+		"slack.usergroup.id":         a.UserGroup.ID,
+		"slack.usergroup.name":       a.Name,
+		"slack.usergroup.old.name":   a.UserGroup.Name,
+		"slack.usergroup.handle":     a.UserGroup.Handle,
+		"slack.usergroup.old.handle": a.UserGroup.Handle,
+		"governor.app.id":            a.GovernorAppID,
+		"governor.group.id":          a.GovernorGroupID,
+		"governor.group.slug":        a.GovernorGroupSlug,
+	}
+
+	if req.Handle != nil {
+		target["slack.usergroup.handle"] = *req.Handle
+	}
+
+	// the members are synced after the user group is bound, the planned change is part of the adoption
+	diff := userGroupDiff(&a.UserGroup, &req)
+	diff["members"] = r.withEmails(ctx, &listDiff{
+		Old:     a.UserGroup.Users,
+		New:     append(difference(a.UserGroup.Users, a.Remove), a.Add...),
+		Added:   a.Add,
+		Removed: a.Remove,
+	})
+
+	if r.dryrun {
+		logger.Info("SKIP adopting slack user group", zap.String("slack.usergroup.name", a.Name), zap.Strings("slack.usergroup.add", a.Add), zap.Strings("slack.usergroup.remove", a.Remove))
+		r.audit(ctx, logger, "UserGroupAdopt", target, diff, nil)
+
+		return nil
+	}
+
+	handle := a.UserGroup.Handle
+
+	if req.Name != nil || req.Handle != nil || req.Description != nil {
+		ug, err := r.Client.UpdateUserGroup(ctx, a.UserGroup.ID, a.TeamID, req)
+		if err != nil {
+			logger.Error("failed to rename adopted user group", zap.Error(err))
+			r.audit(ctx, logger, "UserGroupAdopt", target, diff, err)
+
+			return err
+		}
+
+		handle = ug.Handle
+	}
+
+	r.putMapping(ctx, logger, a.GovernorGroupID, a.GovernorAppID, a.TeamID, a.UserGroup.ID, a.Name, handle)
+
+	logger.Info("adopted user group", zap.String("slack.usergroup.name", a.Name), zap.String("slack.usergroup.old.name", a.UserGroup.Name))
+
+	target["slack.usergroup.handle"] = handle
+
+	r.audit(ctx, logger, "UserGroupAdopt", target, diff, nil)
+
+	return r.UpdateUserGroupMembers(ctx, a.GovernorGroupID, a.GovernorAppID)
+}
+
+// adoptFromRules adopts the slack user group configured for the governor group in the workspace,
+// if any. It returns true if a user group was adopted.
+func (r *Reconciler) adoptFromRules(ctx context.Context, group *v1alpha1.Group, appID, workspace, teamID string) (bool, error) {
+	for _, rule := range r.adoptRules {
+		if rule.Group != group.ID && rule.Group != group.Slug {
+			continue
+		}
+
+		if rule.Workspace != workspace {
+			continue
+		}
+
+		a, err := r.planAdoption(ctx, group, appID, workspace, teamID, rule.UserGroup)
+		if err != nil {
+			if errors.Is(err, ErrSlackUserGroupNotFound) {
+				r.Logger.Warn("user group to adopt not found", zap.String("slack.usergroup", rule.UserGroup), zap.String("slack.workspace.name", workspace))
+				continue
+			}
+
+			return false, err
+		}
+
+		if err := r.Adopt(ctx, a); err != nil {
+			return false, err
+		}
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// userGroupFromRef returns the user group with the given id or handle
+func (r *Reconciler) userGroupFromRef(ctx context.Context, ref, teamID string) (*UserGroup, error) {
+	usergroups, err := r.Client.GetUserGroups(ctx, teamID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ug := range usergroups {
+		if ug.ID == ref || ug.Handle == ref {
+			return toUserGroup(ug), nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrSlackUserGroupNotFound, ref)
+}
@@ -0,0 +1,231 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/auctx"
+	"github.com/metal-toolbox/gov-slack-addon/internal/slack"
+	slackgo "github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// error classes recorded in the audit events of failed operations
+const (
+	errorClassBadParameter = "bad_parameter"
+	errorClassCanceled     = "canceled"
+	errorClassNotFound     = "not_found"
+	errorClassSlackAPI     = "slack_api"
+	errorClassSlackAuth    = "slack_auth"
+	errorClassSlackLimited = "slack_rate_limited"
+	errorClassTimeout      = "timeout"
+	errorClassUnknown      = "unknown"
+)
+
+// auditData is the data of the audit event of an operation
+type auditData struct {
+	Diff       any    `json:"diff,omitempty"`
+	Error      string `json:"error,omitempty"`
+	ErrorClass string `json:"error_class,omitempty"`
+}
+
+// listDiff is the change to a list of slack ids, e.g. the members of a user group
+type listDiff struct {
+	Old     []string `json:"old"`
+	New     []string `json:"new"`
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	// Emails are the emails of the added and removed slack users, by slack user id
+	Emails map[string]string `json:"emails,omitempty"`
+}
+
+// newListDiff computes the change from the old to the new list
+func newListDiff(o, n []string) *listDiff {
+	if o == nil {
+		o = []string{}
+	}
+
+	if n == nil {
+		n = []string{}
+	}
+
+	return &listDiff{
+		Old:     o,
+		New:     n,
+		Added:   difference(n, o),
+		Removed: difference(o, n),
+	}
+}
+
+// withEmails adds the emails of the added and removed slack users to the diff of a list of slack users
+func (r *Reconciler) withEmails(ctx context.Context, d *listDiff) *listDiff {
+	d.Emails = map[string]string{}
+
+	for _, id := range append(slices.Clone(d.Added), d.Removed...) {
+		if email := r.slackUserEmail(ctx, id); email != "" {
+			d.Emails[id] = email
+		}
+	}
+
+	return d
+}
+
+// userEmails keeps the emails of the slack users matched to governor users, by slack user id, so the
+// users affected by a change can be named in its audit event without looking them up again
+type userEmails struct {
+	mu     sync.RWMutex
+	emails map[string]string
+}
+
+func newUserEmails() *userEmails {
+	return &userEmails{emails: make(map[string]string)}
+}
+
+// get returns the email of the slack user, it's empty if the user hasn't been matched
+func (u *userEmails) get(slackUserID string) string {
+	if u == nil {
+		return ""
+	}
+
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	return u.emails[slackUserID]
+}
+
+// set records the email of the slack user
+func (u *userEmails) set(slackUserID, email string) {
+	if u == nil || slackUserID == "" || email == "" {
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.emails[slackUserID] = strings.ToLower(email)
+}
+
+// slackUserEmail returns the email of the slack user, from the governor user it was matched to or
+// from its slack profile. It's empty if the user can't be found.
+func (r *Reconciler) slackUserEmail(ctx context.Context, slackUserID string) string {
+	if email := r.emails.get(slackUserID); email != "" {
+		return email
+	}
+
+	u, err := r.Client.GetUser(ctx, slackUserID)
+	if err != nil {
+		r.Logger.Debug("failed to get slack user email", zap.String("slack.user.id", slackUserID), zap.Error(err))
+		return ""
+	}
+
+	r.emails.set(slackUserID, u.Profile.Email)
+
+	return strings.ToLower(u.Profile.Email)
+}
+
+// ActorSubjects returns the audit event subjects naming the governor actor of an event, that is the
+// id of the user or the client that made the change in governor. The email and name of the user are
+// added when the actor is a governor user.
+func (r *Reconciler) ActorSubjects(ctx context.Context, actorID string) map[string]string {
+	if actorID == "" {
+		return map[string]string{}
+	}
+
+	subjects := map[string]string{"governor.actor.id": actorID}
+
+	user, err := r.GovernorClient.User(ctx, actorID, false)
+	if err != nil || user == nil {
+		// clients aren't governor users
+		r.Logger.Debug("governor actor isn't a governor user", zap.String("governor.actor.id", actorID), zap.Error(err))
+		return subjects
+	}
+
+	subjects["governor.actor.email"] = user.Email
+	subjects["governor.actor.name"] = user.Name
+
+	return subjects
+}
+
+// valueDiff is the change to a single value, e.g. the name of a user group
+type valueDiff struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// userGroupDiff computes the change from the user group to the request, old is nil for a new user group
+func userGroupDiff(old *UserGroup, req *slack.UserGroupReq) map[string]any {
+	if old == nil {
+		old = &UserGroup{}
+	}
+
+	diff := map[string]any{}
+
+	if req.Name != nil && *req.Name != old.Name {
+		diff["name"] = valueDiff{Old: old.Name, New: *req.Name}
+	}
+
+	if req.Handle != nil && *req.Handle != old.Handle {
+		diff["handle"] = valueDiff{Old: old.Handle, New: *req.Handle}
+	}
+
+	if req.Description != nil && *req.Description != old.Description {
+		diff["description"] = valueDiff{Old: old.Description, New: *req.Description}
+	}
+
+	if req.Channels != nil && !equal(old.Channels, *req.Channels) {
+		diff["channels"] = newListDiff(old.Channels, *req.Channels)
+	}
+
+	return diff
+}
+
+// audit writes the audit event of an operation that changes slack or governor. The outcome is failed
+// if err isn't nil, dryrun if the change was skipped because of dry-run mode, and succeeded otherwise.
+// The diff computed for the operation is written in the event data, along with the error.
+func (r *Reconciler) audit(ctx context.Context, logger *zap.Logger, evType string, target map[string]string, diff any, err error) {
+	data := auditData{Diff: diff}
+
+	if err != nil {
+		data.Error = err.Error()
+		data.ErrorClass = errorClass(err)
+	}
+
+	if err := auctx.WriteAuditEventOutcome(ctx, r.auditEventWriter, evType, auctx.Outcome(err, r.dryrun), target, data); err != nil {
+		logger.Error("error writing audit event", zap.String("audit.type", evType), zap.Error(err))
+	}
+}
+
+// errorClass returns the class of the error of a failed operation
+func errorClass(err error) string {
+	var rateLimited *slackgo.RateLimitedError
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return errorClassTimeout
+	case errors.Is(err, context.Canceled):
+		return errorClassCanceled
+	case errors.As(err, &rateLimited):
+		return errorClassSlackLimited
+	case slack.IsAuthError(err):
+		return errorClassSlackAuth
+	case errors.Is(err, ErrBadParameter),
+		errors.Is(err, slack.ErrBadParameter),
+		errors.Is(err, slack.ErrMissingUserGroupParameter),
+		errors.Is(err, slack.ErrEmptyUserGroupMembers):
+		return errorClassBadParameter
+	case errors.Is(err, ErrSlackUserGroupNotFound),
+		errors.Is(err, ErrSlackWorkspaceNotFound),
+		errors.Is(err, slack.ErrSlackGroupNotFound),
+		errors.Is(err, slack.ErrSlackChannelNotFound),
+		errors.Is(err, slack.ErrSlackUserNotFound),
+		errors.Is(err, slack.ErrSlackWorkspaceNotFound):
+		return errorClassNotFound
+	case errors.Is(err, slack.ErrSlackAPI), errors.Is(err, ErrRequestNonSuccess):
+		return errorClassSlackAPI
+	default:
+		return errorClassUnknown
+	}
+}
@@ -0,0 +1,200 @@
+package reconciler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/metal-toolbox/auditevent"
+	slackgo "github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/auctx"
+	"github.com/metal-toolbox/gov-slack-addon/internal/slack"
+)
+
+func Test_errorClass(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "timeout", err: fmt.Errorf("list users: %w", context.DeadlineExceeded), want: errorClassTimeout},
+		{name: "canceled", err: context.Canceled, want: errorClassCanceled},
+		{name: "rate limited", err: fmt.Errorf("%w: update: %w", slack.ErrSlackAPI, &slackgo.RateLimitedError{}), want: errorClassSlackLimited},
+		{name: "auth", err: fmt.Errorf("%w: update: %w", slack.ErrSlackAPI, slackgo.SlackErrorResponse{Err: "invalid_auth"}), want: errorClassSlackAuth},
+		{name: "not found", err: ErrSlackUserGroupNotFound, want: errorClassNotFound},
+		{name: "bad parameter", err: slack.ErrEmptyUserGroupMembers, want: errorClassBadParameter},
+		{name: "slack api", err: fmt.Errorf("%w: update: %w", slack.ErrSlackAPI, slackgo.SlackErrorResponse{Err: "permission_denied"}), want: errorClassSlackAPI},
+		{name: "unknown", err: errors.New("boom"), want: errorClassUnknown}, //nolint:err113
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorClass(tt.err); got != tt.want {
+				t.Errorf("errorClass() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_newListDiff(t *testing.T) {
+	got := newListDiff([]string{"U1", "U2"}, []string{"U2", "U3"})
+	want := &listDiff{
+		Old:     []string{"U1", "U2"},
+		New:     []string{"U2", "U3"},
+		Added:   []string{"U3"},
+		Removed: []string{"U1"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("newListDiff() = %+v, want %+v", got, want)
+	}
+
+	if got := newListDiff(nil, nil); got.Old == nil || got.New == nil {
+		t.Error("expected empty lists rather than nil")
+	}
+}
+
+func Test_userGroupDiff(t *testing.T) {
+	name := "new name"
+	handle := "same-handle"
+	channels := []string{"C1"}
+
+	got := userGroupDiff(&UserGroup{Name: "old name", Handle: "same-handle"}, &slack.UserGroupReq{
+		Name:     &name,
+		Handle:   &handle,
+		Channels: &channels,
+	})
+
+	if len(got) != 2 {
+		t.Fatalf("expected the name and channels to change, got %+v", got)
+	}
+
+	if got["name"] != (valueDiff{Old: "old name", New: "new name"}) {
+		t.Errorf("unexpected name diff %+v", got["name"])
+	}
+
+	if d, ok := got["channels"].(*listDiff); !ok || !reflect.DeepEqual(d.Added, []string{"C1"}) {
+		t.Errorf("unexpected channels diff %+v", got["channels"])
+	}
+}
+
+func TestReconciler_audit(t *testing.T) {
+	tests := []struct {
+		name        string
+		dryrun      bool
+		err         error
+		wantOutcome string
+		wantClass   string
+	}{
+		{name: "succeeded", wantOutcome: auditevent.OutcomeSucceeded},
+		{name: "dry-run", dryrun: true, wantOutcome: auctx.OutcomeDryRun},
+		{name: "failed", err: ErrSlackUserGroupNotFound, wantOutcome: auditevent.OutcomeFailed, wantClass: errorClassNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+
+			r := New(
+				WithLogger(zap.NewNop()),
+				WithDryRun(tt.dryrun),
+				WithAuditEventWriter(auditevent.NewDefaultAuditEventWriter(buf)),
+			)
+
+			ctx := auctx.WithAuditEvent(context.Background(), auditevent.NewAuditEvent("", auditevent.EventSource{}, auditevent.OutcomeSucceeded, nil, "test"))
+
+			r.audit(ctx, r.Logger, "UserGroupUpdateMembers", map[string]string{"slack.usergroup.id": "S1"}, newListDiff([]string{"U1"}, []string{"U2"}), tt.err)
+
+			ev := struct {
+				Type    string `json:"type"`
+				Outcome string `json:"outcome"`
+				Data    struct {
+					Diff       listDiff `json:"diff"`
+					ErrorClass string   `json:"error_class"`
+				} `json:"data"`
+			}{}
+
+			if err := json.Unmarshal(buf.Bytes(), &ev); err != nil {
+				t.Fatalf("failed to decode audit event: %v", err)
+			}
+
+			if ev.Type != "UserGroupUpdateMembers" || ev.Outcome != tt.wantOutcome || ev.Data.ErrorClass != tt.wantClass {
+				t.Errorf("unexpected audit event %+v", ev)
+			}
+
+			if !reflect.DeepEqual(ev.Data.Diff.Added, []string{"U2"}) || !reflect.DeepEqual(ev.Data.Diff.Removed, []string{"U1"}) {
+				t.Errorf("unexpected diff %+v", ev.Data.Diff)
+			}
+		})
+	}
+}
+
+func TestReconciler_ActorSubjects(t *testing.T) {
+	tests := []struct {
+		name   string
+		client mockGovernorClient
+		actor  string
+		want   map[string]string
+	}{
+		{
+			name: "governor user",
+			client: mockGovernorClient{
+				resp: []byte(`{"id":"user-1","name":"Jane Doe","email":"jane@example.com"}`),
+			},
+			actor: "user-1",
+			want: map[string]string{
+				"governor.actor.id":    "user-1",
+				"governor.actor.email": "jane@example.com",
+				"governor.actor.name":  "Jane Doe",
+			},
+		},
+		{
+			name:   "client",
+			client: mockGovernorClient{err: errors.New("not found")}, //nolint:err113
+			actor:  "client-1",
+			want:   map[string]string{"governor.actor.id": "client-1"},
+		},
+		{
+			name: "no actor",
+			want: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New(WithLogger(zap.NewNop()), func(r *Reconciler) { r.GovernorClient = tt.client })
+
+			if got := r.ActorSubjects(context.Background(), tt.actor); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ActorSubjects() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_userEmails(t *testing.T) {
+	var nilEmails *userEmails
+
+	nilEmails.set("U1", "jane@example.com")
+
+	if got := nilEmails.get("U1"); got != "" {
+		t.Errorf("expected no email from a nil cache, got %s", got)
+	}
+
+	e := newUserEmails()
+	e.set("U1", "Jane@Example.com")
+	e.set("U2", "")
+
+	if got := e.get("U1"); got != "jane@example.com" {
+		t.Errorf("get() = %s, want jane@example.com", got)
+	}
+
+	if got := e.get("U2"); got != "" {
+		t.Errorf("expected no email for U2, got %s", got)
+	}
+}
@@ -0,0 +1,430 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/metal-toolbox/governor-api/pkg/api/v1alpha1"
+	"go.uber.org/zap"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/slack"
+	"github.com/metal-toolbox/gov-slack-addon/internal/tracing"
+)
+
+// ChannelLink links a governor group to slack channels, so the group members are kept in sync
+// as channel members
+type ChannelLink struct {
+	// Group is the governor group id or slug
+	Group string
+	// Workspace is the slack workspace name, the link applies to all the workspaces if empty
+	Workspace string
+	// Channels are the slack channel ids
+	Channels []string
+}
+
+// SyncGroupChannels syncs the members of the channels linked to the governor group in all of
+// the slack workspaces the group is linked to
+func (r *Reconciler) SyncGroupChannels(ctx context.Context, groupID string) error {
+	if groupID == "" {
+		return ErrBadParameter
+	}
+
+	ctx, unlock := r.lockGroup(ctx, groupID)
+	defer unlock()
+
+	if len(r.channelLinks) == 0 {
+		return nil
+	}
+
+	group, err := r.GovernorClient.Group(ctx, groupID, false)
+	if err != nil {
+		r.Logger.Error("error getting governor group", zap.String("governor.group.id", groupID), zap.Error(err))
+		return err
+	}
+
+	var errs []error
+
+	for _, appID := range group.Applications {
+		if err := r.SyncChannelMembers(ctx, groupID, appID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// SyncChannelMembers invites the members of the governor group to the slack channels linked to
+// the group in the workspace of the given application. If kicking is enabled, channel members
+// that aren't in the governor group are removed from the channels.
+func (r *Reconciler) SyncChannelMembers(ctx context.Context, groupID, appID string) error {
+	if groupID == "" || appID == "" {
+		return ErrBadParameter
+	}
+
+	ctx, unlock := r.lockGroup(ctx, groupID)
+	defer unlock()
+
+	if len(r.channelLinks) == 0 {
+		return nil
+	}
+
+	isSlack, workspace, err := r.isSlackApplication(ctx, appID)
+	if err != nil {
+		r.Logger.Error("failed to get application from governor", zap.String("governor.app.id", appID), zap.Error(err))
+		return err
+	}
+
+	if !isSlack {
+		r.Logger.Debug("not a slack application, skipping", zap.String("governor.app.id", appID), zap.String("governor.app.name", workspace))
+		return nil
+	}
+
+	if !r.syncsToWorkspace(ctx, groupID, workspace) {
+		r.Logger.Debug("workspace not in the group settings, skipping", zap.String("governor.group.id", groupID), zap.String("slack.workspace.name", workspace))
+		return nil
+	}
+
+	group, err := r.GovernorClient.Group(ctx, groupID, false)
+	if err != nil {
+		r.Logger.Error("error getting governor group", zap.String("governor.group.id", groupID), zap.Error(err))
+		return err
+	}
+
+	channels := r.linkedChannels(group, workspace)
+	if len(channels) == 0 {
+		return nil
+	}
+
+	logger := tracing.Logger(ctx, r.Logger).With(
+		zap.String("slack.workspace.name", workspace),
+		zap.String("governor.app.id", appID),
+		zap.String("governor.group.id", group.ID),
+		zap.String("governor.group.slug", group.Slug),
+	)
+
+	members, err := r.GovernorClient.GroupMembers(ctx, groupID)
+	if err != nil {
+		logger.Error("error getting governor group members", zap.Error(err))
+		return err
+	}
+
+	teamID, err := r.teamIDFromName(ctx, workspace)
+	if err != nil {
+		return err
+	}
+
+	// the rules scoped to a user group don't apply to the channels
+	ex := r.exclusions(group, workspace, nil)
+
+	userIDs, err := r.channelMembers(ctx, logger, teamID, ex, members)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for _, ch := range channels {
+		target := map[string]string{
+			"slack.workspace.name": workspace,
+			"slack.channel.id":     ch,
+			"governor.app.id":      appID,
+			"governor.group.id":    group.ID,
+			"governor.group.slug":  group.Slug,
+		}
+
+		if err := r.syncChannel(ctx, logger.With(zap.String("slack.channel.id", ch)), ch, userIDs, ex, target); err != nil {
+			logger.Error("failed to sync slack channel members", zap.String("slack.channel.id", ch), zap.Error(err))
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// syncChannel updates the members of a single channel to match the desired slack users. A failed
+// removal doesn't stop the other removals.
+func (r *Reconciler) syncChannel(
+	ctx context.Context,
+	logger *zap.Logger,
+	channelID string,
+	desired []string,
+	ex *exclusions,
+	target map[string]string,
+) error {
+	current, err := r.Client.GetChannelMembers(ctx, channelID)
+	if err != nil {
+		return err
+	}
+
+	toAdd := difference(desired, current)
+
+	var toRemove []string
+	if r.channelKick {
+		toRemove = r.channelRemovals(ctx, logger, ex, current, desired)
+	}
+
+	if len(toAdd) == 0 && len(toRemove) == 0 {
+		logger.Debug("no need to update channel members")
+		return nil
+	}
+
+	added := append(slices.Clone(current), toAdd...)
+
+	if r.dryrun {
+		logger.Info("SKIP updating slack channel members", zap.Strings("slack.channel.add", toAdd), zap.Strings("slack.channel.remove", toRemove))
+
+		if len(toAdd) > 0 {
+			r.audit(ctx, logger, "ChannelAddMembers", target, r.withEmails(ctx, newListDiff(current, added)), nil)
+		}
+
+		for _, u := range toRemove {
+			r.audit(ctx, logger, "ChannelRemoveMember", withTarget(target, map[string]string{"slack.user.id": u, "slack.user.email": r.slackUserEmail(ctx, u)}), newListDiff(added, remove(added, u)), nil)
+		}
+
+		return nil
+	}
+
+	if len(toAdd) > 0 {
+		if err := r.Client.InviteToChannel(ctx, channelID, toAdd); err != nil {
+			r.audit(ctx, logger, "ChannelAddMembers", target, r.withEmails(ctx, newListDiff(current, added)), err)
+			return err
+		}
+
+		logger.Info("added users to channel", zap.Strings("slack.user.ids", toAdd))
+
+		r.audit(ctx, logger, "ChannelAddMembers", target, r.withEmails(ctx, newListDiff(current, added)), nil)
+
+		current = added
+	}
+
+	var errs []error
+
+	for _, u := range toRemove {
+		next := remove(current, u)
+		userTarget := withTarget(target, map[string]string{"slack.user.id": u, "slack.user.email": r.slackUserEmail(ctx, u)})
+
+		if err := r.Client.KickFromChannel(ctx, channelID, u); err != nil {
+			logger.Error("failed to remove user from channel", zap.String("slack.user.id", u), zap.Error(err))
+			r.audit(ctx, logger, "ChannelRemoveMember", userTarget, newListDiff(current, next), err)
+			errs = append(errs, err)
+
+			continue
+		}
+
+		logger.Info("removed user from channel", zap.String("slack.user.id", u))
+
+		r.audit(ctx, logger, "ChannelRemoveMember", userTarget, newListDiff(current, next), nil)
+
+		current = next
+	}
+
+	return errors.Join(errs...)
+}
+
+// channelRemovals returns the channel members to remove: the members that aren't desired, except
+// for the addon's own user and the slack users protected by the exclusion rules
+func (r *Reconciler) channelRemovals(ctx context.Context, logger *zap.Logger, ex *exclusions, current, desired []string) []string {
+	toRemove := difference(current, desired)
+
+	// slack doesn't let the addon kick itself
+	selfID, err := r.selfUserID(ctx)
+	if err != nil {
+		logger.Warn("failed to get the slack user of the token", zap.Error(err))
+	}
+
+	toRemove = remove(toRemove, selfID)
+
+	return difference(toRemove, r.protectedUsers(ctx, logger, ex, toRemove))
+}
+
+// linkedChannels returns the channels linked to the governor group in the workspace
+func (r *Reconciler) linkedChannels(group *v1alpha1.Group, workspace string) []string {
+	channels := []string{}
+
+	for _, l := range r.channelLinks {
+		if l.Group != group.ID && l.Group != group.Slug {
+			continue
+		}
+
+		if l.Workspace != "" && l.Workspace != workspace {
+			continue
+		}
+
+		for _, ch := range l.Channels {
+			if !contains(channels, ch) {
+				channels = append(channels, ch)
+			}
+		}
+	}
+
+	return channels
+}
+
+// withTarget returns a copy of the audit event target with the extra fields added
+func withTarget(target, extra map[string]string) map[string]string {
+	out := make(map[string]string, len(target)+len(extra))
+
+	for k, v := range target {
+		out[k] = v
+	}
+
+	for k, v := range extra {
+		out[k] = v
+	}
+
+	return out
+}
+
+// DefaultChannels sets the default channels of the slack user groups of a governor group and/or
+// a governor application. When several rules match, the most specific one wins.
+type DefaultChannels struct {
+	// Group is the governor group id or slug, the rule applies to all the groups if empty
+	Group string
+	// Application is the governor application id or name, the rule applies to all the applications if empty
+	Application string
+	// Channels are the slack channel ids
+	Channels []string
+}
+
+// defaultChannels returns the default channels for the user group of the governor group in the
+// given application, or nil if no rule matches and the default channels should be left alone. The
+// default channels set in the group settings take precedence over the rules.
+func (r *Reconciler) defaultChannels(ctx context.Context, group *v1alpha1.Group, appID, appName string) *[]string {
+	if s := r.groupSettings(ctx, group.ID); s != nil && s.DefaultChannels != nil {
+		channels := append([]string{}, s.DefaultChannels...)
+		return &channels
+	}
+
+	var (
+		match *DefaultChannels
+		best  = -1
+	)
+
+	for i, d := range r.defaultChannelRules {
+		score := 0
+
+		if d.Group != "" {
+			if d.Group != group.ID && d.Group != group.Slug {
+				continue
+			}
+
+			score += 2
+		}
+
+		if d.Application != "" {
+			if d.Application != appID && d.Application != appName {
+				continue
+			}
+
+			score++
+		}
+
+		if score > best {
+			match = &r.defaultChannelRules[i]
+			best = score
+		}
+	}
+
+	if match == nil {
+		return nil
+	}
+
+	channels := append([]string{}, match.Channels...)
+
+	return &channels
+}
+
+// UpdateUserGroupDefaultChannels sets the default channels of the slack user group to match the
+// configured default channels rules, correcting any changes made in slack
+func (r *Reconciler) UpdateUserGroupDefaultChannels(ctx context.Context, groupID, appID string) error {
+	if groupID == "" || appID == "" {
+		return ErrBadParameter
+	}
+
+	ctx, unlock := r.lockGroup(ctx, groupID)
+	defer unlock()
+
+	// default channels can also be set in the group settings
+	if len(r.defaultChannelRules) == 0 && r.extensions == nil {
+		return nil
+	}
+
+	isSlack, workspace, err := r.isSlackApplication(ctx, appID)
+	if err != nil {
+		r.Logger.Error("failed to get application from governor", zap.String("governor.app.id", appID), zap.Error(err))
+		return err
+	}
+
+	if !isSlack {
+		r.Logger.Debug("not a slack application, skipping", zap.String("governor.app.id", appID), zap.String("governor.app.name", workspace))
+		return nil
+	}
+
+	if !r.syncsToWorkspace(ctx, groupID, workspace) {
+		r.Logger.Debug("workspace not in the group settings, skipping", zap.String("governor.group.id", groupID), zap.String("slack.workspace.name", workspace))
+		return nil
+	}
+
+	logger := tracing.Logger(ctx, r.Logger).With(zap.String("slack.workspace.name", workspace), zap.String("governor.app.id", appID))
+
+	group, err := r.GovernorClient.Group(ctx, groupID, false)
+	if err != nil {
+		logger.Error("error getting governor group", zap.String("governor.group.id", groupID), zap.Error(err))
+		return err
+	}
+
+	channels := r.defaultChannels(ctx, group, appID, workspace)
+	if channels == nil {
+		return nil
+	}
+
+	teamID, err := r.userGroupTeamID(ctx, workspace)
+	if err != nil {
+		return err
+	}
+
+	ug, err := r.userGroup(ctx, group, appID, workspace, teamID)
+	if err != nil {
+		return err
+	}
+
+	if equal(ug.Channels, *channels) {
+		logger.Debug("no need to update default channels", zap.Strings("slack.usergroup.channels", ug.Channels))
+		return nil
+	}
+
+	target := map[string]string{
+		"slack.workspace.name": workspace,
+		"slack.usergroup.name": ug.Name,
+		"slack.usergroup.id":   ug.ID,
+		"governor.app.id":      appID,
+		"governor.group.id":    group.ID,
+		"governor.group.slug":  group.Slug,
+	}
+
+	if r.dryrun {
+		logger.Info("SKIP updating slack user group default channels",
+			zap.String("slack.usergroup.id", ug.ID),
+			zap.Strings("slack.usergroup.channels.existing", ug.Channels),
+			zap.Strings("slack.usergroup.channels.new", *channels),
+		)
+		r.audit(ctx, logger, "UserGroupUpdateChannels", target, newListDiff(ug.Channels, *channels), nil)
+
+		return nil
+	}
+
+	ugUpdated, err := r.Client.UpdateUserGroup(ctx, ug.ID, teamID, slack.UserGroupReq{Channels: channels})
+	if err != nil {
+		logger.Error("failed to update user group default channels", zap.String("slack.usergroup.id", ug.ID), zap.Error(err))
+		r.audit(ctx, logger, "UserGroupUpdateChannels", target, newListDiff(ug.Channels, *channels), err)
+
+		return err
+	}
+
+	logger.Info("updated user group default channels", zap.String("slack.usergroup.id", ug.ID), zap.Strings("slack.usergroup.channels", ugUpdated.Prefs.Channels))
+
+	r.audit(ctx, logger, "UserGroupUpdateChannels", target, newListDiff(ug.Channels, *channels), nil)
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package reconciler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/metal-toolbox/governor-api/pkg/api/v1alpha1"
+)
+
+func TestReconciler_linkedChannels(t *testing.T) {
+	r := &Reconciler{
+		channelLinks: []ChannelLink{
+			{Group: "group-id", Channels: []string{"C1", "C2"}},
+			{Group: "group-slug", Workspace: "workspace-a", Channels: []string{"C2", "C3"}},
+			{Group: "other-group", Channels: []string{"C4"}},
+		},
+	}
+
+	group := &v1alpha1.Group{ID: "group-id", Slug: "group-slug"}
+
+	tests := []struct {
+		name      string
+		workspace string
+		want      []string
+	}{
+		{
+			name:      "workspace link",
+			workspace: "workspace-a",
+			want:      []string{"C1", "C2", "C3"},
+		},
+		{
+			name:      "other workspace",
+			workspace: "workspace-b",
+			want:      []string{"C1", "C2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.linkedChannels(group, tt.workspace); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("linkedChannels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconciler_defaultChannels(t *testing.T) {
+	r := &Reconciler{
+		defaultChannelRules: []DefaultChannels{
+			{Application: "workspace-a", Channels: []string{"C1"}},
+			{Group: "group-slug", Channels: []string{"C2"}},
+			{Group: "group-id", Application: "app-b", Channels: []string{"C3"}},
+		},
+	}
+
+	group := &v1alpha1.Group{ID: "group-id", Slug: "group-slug"}
+	other := &v1alpha1.Group{ID: "other-id", Slug: "other-slug"}
+
+	tests := []struct {
+		name    string
+		group   *v1alpha1.Group
+		appID   string
+		appName string
+		want    *[]string
+	}{
+		{
+			name:    "application rule",
+			group:   other,
+			appID:   "app-a",
+			appName: "workspace-a",
+			want:    &[]string{"C1"},
+		},
+		{
+			name:    "group rule wins over application rule",
+			group:   group,
+			appID:   "app-a",
+			appName: "workspace-a",
+			want:    &[]string{"C2"},
+		},
+		{
+			name:    "group and application rule",
+			group:   group,
+			appID:   "app-b",
+			appName: "workspace-b",
+			want:    &[]string{"C3"},
+		},
+		{
+			name:    "no match",
+			group:   other,
+			appID:   "app-c",
+			appName: "workspace-c",
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.defaultChannels(context.Background(), tt.group, tt.appID, tt.appName); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("defaultChannels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconciler_SyncChannelMembers_workspaceSettings(t *testing.T) {
+	r, _ := testSettingsReconciler(map[string]string{"group-1": `{"workspaces":["ws-a"]}`})
+	r.applicationType = "slack"
+	r.channelLinks = []ChannelLink{{Group: "group-1", Channels: []string{"C1"}}}
+
+	// the mock answers every governor call with the application, so listing the group members fails
+	// if the sync goes past the settings
+	r.GovernorClient = mockGovernorClient{resp: []byte(`{"id":"group-1","name":"ws-b","type":{"slug":"slack"}}`)}
+
+	if err := r.SyncChannelMembers(context.Background(), "group-1", "app-b"); err != nil {
+		t.Errorf("SyncChannelMembers() error = %v, want the workspace to be skipped", err)
+	}
+
+	r.GovernorClient = mockGovernorClient{resp: []byte(`{"id":"group-1","name":"ws-a","type":{"slug":"slack"}}`)}
+
+	if err := r.SyncChannelMembers(context.Background(), "group-1", "app-a"); err == nil {
+		t.Error("SyncChannelMembers() expected the members of a workspace in the settings to be synced")
+	}
+}
@@ -0,0 +1,424 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/metal-toolbox/auditevent"
+	"go.uber.org/zap"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/auctx"
+	"github.com/metal-toolbox/gov-slack-addon/internal/slack"
+	"github.com/metal-toolbox/gov-slack-addon/internal/store"
+	"github.com/metal-toolbox/gov-slack-addon/internal/tracing"
+)
+
+// Drift is a change made by hand to a slack user group, as reported by the slack events api
+type Drift struct {
+	// EventType is the slack event type, subteam_updated or subteam_members_changed
+	EventType   string
+	TeamID      string
+	UserGroupID string
+	// Actor is the slack user who changed the user group, if the event says
+	Actor   string
+	Added   []string
+	Removed []string
+}
+
+// pendingDrift holds the drifts queued for a managed user group
+type pendingDrift struct {
+	mapping *store.UserGroupMapping
+	drifts  []Drift
+}
+
+// driftQueue holds the managed user groups waiting for a targeted reconcile. Drifts of the same
+// user group are merged, so a burst of events results in a single reconcile.
+type driftQueue struct {
+	mu      sync.Mutex
+	order   []string
+	pending map[string]*pendingDrift
+	notify  chan struct{}
+}
+
+func newDriftQueue() *driftQueue {
+	return &driftQueue{
+		pending: make(map[string]*pendingDrift),
+		notify:  make(chan struct{}, 1),
+	}
+}
+
+// push queues the drift of the mapped user group
+func (q *driftQueue) push(m *store.UserGroupMapping, d Drift) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	key := m.GovernorGroupID + "/" + m.TeamID
+
+	p, ok := q.pending[key]
+	if !ok {
+		p = &pendingDrift{mapping: m}
+		q.pending[key] = p
+		q.order = append(q.order, key)
+	}
+
+	p.drifts = append(p.drifts, d)
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// drain returns the queued drifts in the order they were first queued, and empties the queue
+func (q *driftQueue) drain() []*pendingDrift {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	out := make([]*pendingDrift, 0, len(q.order))
+
+	for _, k := range q.order {
+		out = append(out, q.pending[k])
+	}
+
+	q.order = nil
+	q.pending = make(map[string]*pendingDrift)
+
+	return out
+}
+
+// QueueDrift queues a targeted reconcile of the user group changed by hand, if it's managed by the
+// addon. It returns false if the user group isn't managed.
+func (r *Reconciler) QueueDrift(ctx context.Context, d Drift) (bool, error) {
+	if d.UserGroupID == "" {
+		return false, ErrBadParameter
+	}
+
+	m, err := r.mappingForUserGroup(ctx, d.UserGroupID)
+	if err != nil {
+		return false, err
+	}
+
+	if m == nil {
+		r.Logger.Debug("ignoring change to unmanaged user group", zap.String("slack.usergroup.id", d.UserGroupID), zap.String("slack.event.type", d.EventType))
+		return false, nil
+	}
+
+	r.Logger.Info("queueing reconcile of user group changed in slack",
+		zap.String("slack.usergroup.id", d.UserGroupID),
+		zap.String("slack.event.type", d.EventType),
+		zap.String("slack.user.id", d.Actor),
+		zap.String("governor.group.id", m.GovernorGroupID),
+	)
+
+	r.drift.push(m, d)
+
+	return true, nil
+}
+
+// mappingForUserGroup returns the mapping of the slack user group, or nil if it isn't managed. User
+// group ids are unique across the organization, so the team isn't matched.
+func (r *Reconciler) mappingForUserGroup(ctx context.Context, userGroupID string) (*store.UserGroupMapping, error) {
+	m, err := r.store.GetMappingByUserGroup(ctx, userGroupID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// processDrift runs the targeted reconciles queued by QueueDrift until the context is canceled
+func (r *Reconciler) processDrift(ctx context.Context) {
+	for {
+		select {
+		case <-r.drift.notify:
+			for _, p := range r.drift.drain() {
+				r.revertDrift(ctx, p)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// revertDrift audits the changes made by hand to the user group and restores it to governor's state.
+// Changes made by the addon itself are echoed back by slack, and ignored.
+func (r *Reconciler) revertDrift(ctx context.Context, p *pendingDrift) {
+	logger := tracing.Logger(ctx, r.Logger).With(
+		zap.String("slack.usergroup.id", p.mapping.UserGroupID),
+		zap.String("governor.group.id", p.mapping.GovernorGroupID),
+		zap.String("governor.app.id", p.mapping.GovernorAppID),
+	)
+
+	selfID, err := r.selfUserID(ctx)
+	if err != nil {
+		logger.Warn("failed to get the slack user of the token", zap.Error(err))
+	}
+
+	lastUpdatedBy := ""
+
+	if ug, err := r.userGroupFromID(ctx, p.mapping.UserGroupID, p.mapping.TeamID, true); err == nil {
+		lastUpdatedBy = ug.UpdatedBy
+	}
+
+	drifts := []Drift{}
+
+	for _, d := range p.drifts {
+		// subteam_members_changed doesn't say who made the change
+		if d.Actor == "" {
+			d.Actor = lastUpdatedBy
+		}
+
+		if selfID != "" && d.Actor == selfID {
+			continue
+		}
+
+		drifts = append(drifts, d)
+	}
+
+	if len(drifts) == 0 {
+		logger.Debug("ignoring user group changes made by the addon")
+		return
+	}
+
+	for _, d := range drifts {
+		logger.Warn("slack user group changed by hand",
+			zap.String("slack.event.type", d.EventType),
+			zap.String("slack.user.id", d.Actor),
+			zap.Strings("slack.usergroup.added", d.Added),
+			zap.Strings("slack.usergroup.removed", d.Removed),
+		)
+
+		actorEmail := ""
+		if d.Actor != "" {
+			actorEmail = r.slackUserEmail(ctx, d.Actor)
+		}
+
+		members := r.withEmails(ctx, &listDiff{Added: d.Added, Removed: d.Removed})
+
+		actx := auctx.WithAuditEvent(ctx, auditevent.NewAuditEvent(
+			"", // eventType to be populated later
+			auditevent.EventSource{
+				Type:  "slack",
+				Value: "SlackEvents",
+			},
+			auditevent.OutcomeSucceeded,
+			map[string]string{
+				"event":            "drift",
+				"slack.user.id":    d.Actor,
+				"slack.user.email": actorEmail,
+			},
+			"gov-slack-addon",
+		))
+
+		// the drift was made by hand in slack, the event records what changed rather than an operation of the addon
+		if err := auctx.WriteAuditEventOutcome(actx, r.auditEventWriter, "UserGroupDrift", auditevent.OutcomeSucceeded, map[string]string{
+			"slack.event.type":   d.EventType,
+			"slack.workspace.id": d.TeamID,
+			"slack.usergroup.id": d.UserGroupID,
+			"governor.app.id":    p.mapping.GovernorAppID,
+			"governor.group.id":  p.mapping.GovernorGroupID,
+		}, auditData{Diff: map[string]any{
+			"members": map[string]any{"added": members.Added, "removed": members.Removed, "emails": members.Emails},
+		}}); err != nil {
+			logger.Error("error writing audit event", zap.Error(err))
+		}
+	}
+
+	// only the leader reverts the changes, so replicas don't restore the user group concurrently. The
+	// change is picked up by the next pass of the leader otherwise, since the user group changed.
+	isLead, err := r.isLeader()
+	if err != nil {
+		logger.Error("error checking for leader lock", zap.Error(err))
+		return
+	}
+
+	if !isLead {
+		logger.Info("not leader, leaving the user group to the next reconcile pass")
+		return
+	}
+
+	// the revert mustn't race with the reconciliation of the same group by the loop
+	ctx, unlock := r.lockGroup(ctx, p.mapping.GovernorGroupID)
+	defer unlock()
+
+	ctx = auctx.WithAuditEvent(ctx, auditevent.NewAuditEvent(
+		"", // eventType to be populated later
+		auditevent.EventSource{
+			Type:  "slack",
+			Value: "SlackEvents",
+		},
+		auditevent.OutcomeSucceeded,
+		map[string]string{
+			"event": "drift-revert",
+		},
+		"gov-slack-addon",
+	))
+
+	if err := r.RestoreUserGroup(ctx, p.mapping.GovernorGroupID, p.mapping.GovernorAppID); err != nil {
+		logger.Error("failed to restore user group", zap.Error(err))
+	}
+}
+
+// RestoreUserGroup restores the slack user group of the governor group to governor's state: it's
+// re-enabled if it was disabled, its name, handle and description are reset, and its members and
+// default channels are synced
+func (r *Reconciler) RestoreUserGroup(ctx context.Context, groupID, appID string) error {
+	if groupID == "" || appID == "" {
+		return ErrBadParameter
+	}
+
+	isSlack, workspace, err := r.isSlackApplication(ctx, appID)
+	if err != nil {
+		return err
+	}
+
+	if !isSlack {
+		return nil
+	}
+
+	logger := tracing.Logger(ctx, r.Logger).With(zap.String("slack.workspace.name", workspace), zap.String("governor.app.id", appID), zap.String("governor.group.id", groupID))
+
+	group, err := r.GovernorClient.Group(ctx, groupID, false)
+	if err != nil {
+		logger.Error("error getting governor group", zap.Error(err))
+		return err
+	}
+
+	teamID, err := r.userGroupTeamID(ctx, workspace)
+	if err != nil {
+		return err
+	}
+
+	m, err := r.store.GetMapping(ctx, group.ID, teamID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return ErrSlackUserGroupNotFound
+		}
+
+		return err
+	}
+
+	ug, err := r.userGroupFromID(ctx, m.UserGroupID, teamID, true)
+	if err != nil {
+		return err
+	}
+
+	name := r.userGroupName(group, appID, workspace)
+	description := r.userGroupDescription(group, appID, workspace)
+
+	// the handle may have fallen back to another candidate when the user group was created
+	handle := m.Handle
+	if handle == "" {
+		handle = r.userGroupHandle(ctx, group, appID, workspace)
+	}
+
+	req := slack.UserGroupReq{}
+
+	if ug.Name != name {
+		req.Name = &name
+	}
+
+	if ug.Handle != handle {
+		req.Handle = &handle
+	}
+
+	if ug.Description != description {
+		req.Description = &description
+	}
+
+	rename := req.Name != nil || req.Handle != nil || req.Description != nil
+
+	enableTarget := map[string]string{
+		"slack.workspace.name": workspace,
+		"slack.usergroup.id":   ug.ID,
+		"slack.usergroup.name": ug.Name,
+		"governor.app.id":      appID,
+		"governor.group.id":    group.ID,
+	}
+	enableDiff := map[string]any{"disabled": valueDiff{Old: "true", New: "false"}}
+
+	restoreTarget := map[string]string{
+		"slack.workspace.name":            workspace,
+		"slack.usergroup.id":              ug.ID,
+		"slack.usergroup.name":            name,
+		"slack.usergroup.old.name":        ug.Name,
+		"slack.usergroup.handle":          handle,
+		"slack.usergroup.old.handle":      ug.Handle,
+		"slack.usergroup.description":     description,
+		"slack.usergroup.old.description": ug.Description,
+		"governor.app.id":                 appID,
+		"governor.group.id":               group.ID,
+		"governor.group.slug":             group.Slug,
+	}
+	restoreDiff := userGroupDiff(ug, &req)
+
+	if r.dryrun {
+		logger.Info("SKIP restoring slack user group", zap.Any("slack.usergroup", *ug))
+
+		if ug.Disabled {
+			r.audit(ctx, logger, "UserGroupEnable", enableTarget, enableDiff, nil)
+		}
+
+		if rename {
+			r.audit(ctx, logger, "UserGroupRestore", restoreTarget, restoreDiff, nil)
+		}
+
+		return nil
+	}
+
+	if ug.Disabled {
+		if _, err := r.Client.EnableUserGroup(ctx, ug.ID, teamID); err != nil {
+			logger.Error("failed to enable user group", zap.String("slack.usergroup.id", ug.ID), zap.Error(err))
+			r.audit(ctx, logger, "UserGroupEnable", enableTarget, enableDiff, err)
+
+			return err
+		}
+
+		logger.Info("enabled user group", zap.String("slack.usergroup.id", ug.ID))
+
+		r.audit(ctx, logger, "UserGroupEnable", enableTarget, enableDiff, nil)
+	}
+
+	if rename {
+		if _, err := r.Client.UpdateUserGroup(ctx, ug.ID, teamID, req); err != nil {
+			logger.Error("failed to restore user group", zap.String("slack.usergroup.id", ug.ID), zap.Error(err))
+			r.audit(ctx, logger, "UserGroupRestore", restoreTarget, restoreDiff, err)
+
+			return err
+		}
+
+		logger.Info("restored user group", zap.String("slack.usergroup.id", ug.ID), zap.String("slack.usergroup.name", name))
+
+		r.audit(ctx, logger, "UserGroupRestore", restoreTarget, restoreDiff, nil)
+	}
+
+	if err := r.UpdateUserGroupMembers(ctx, group.ID, appID); err != nil {
+		return err
+	}
+
+	return r.UpdateUserGroupDefaultChannels(ctx, group.ID, appID)
+}
+
+// selfUserID returns the id of the slack user the token belongs to, looked up once
+func (r *Reconciler) selfUserID(ctx context.Context) (string, error) {
+	r.selfMu.Lock()
+	defer r.selfMu.Unlock()
+
+	if r.selfID != "" {
+		return r.selfID, nil
+	}
+
+	id, err := r.Client.AuthUserID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	r.selfID = id
+
+	return id, nil
+}
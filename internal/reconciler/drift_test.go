@@ -0,0 +1,62 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/store"
+)
+
+func TestReconciler_QueueDrift(t *testing.T) {
+	ctx := context.TODO()
+	st := store.NewMemory()
+
+	if err := st.PutMapping(ctx, &store.UserGroupMapping{GovernorGroupID: "group-1", GovernorAppID: "app-1", TeamID: "T0001", UserGroupID: "S0001"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	r := New(WithStore(st))
+
+	queued, err := r.QueueDrift(ctx, Drift{EventType: "subteam_updated", TeamID: "T0001", UserGroupID: "S0002"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if queued {
+		t.Error("expected unmanaged user group not to be queued")
+	}
+
+	for _, d := range []Drift{
+		{EventType: "subteam_updated", TeamID: "T0001", UserGroupID: "S0001", Actor: "U0001"},
+		{EventType: "subteam_members_changed", TeamID: "T0001", UserGroupID: "S0001", Added: []string{"U0002"}},
+	} {
+		queued, err := r.QueueDrift(ctx, d)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !queued {
+			t.Error("expected managed user group to be queued")
+		}
+	}
+
+	select {
+	case <-r.drift.notify:
+	default:
+		t.Fatal("expected the queue to be notified")
+	}
+
+	pending := r.drift.drain()
+
+	if len(pending) != 1 || pending[0].mapping.GovernorGroupID != "group-1" || len(pending[0].drifts) != 2 {
+		t.Fatalf("unexpected pending drifts %+v", pending)
+	}
+
+	if len(r.drift.drain()) != 0 {
+		t.Error("expected the queue to be empty after draining")
+	}
+
+	if _, err := r.QueueDrift(ctx, Drift{}); err == nil {
+		t.Error("expected an error for a drift without user group")
+	}
+}
@@ -12,6 +12,9 @@ var (
 	// ErrAppNameEmpty is returned when the governor application name is empty
 	ErrAppNameEmpty = errors.New("governor application name is empty")
 
+	// ErrApplicationTypeNotFound is returned when the configured application type is not found in governor
+	ErrApplicationTypeNotFound = errors.New("governor application type not found")
+
 	// ErrGovernorUserPendingStatus is returned when an event it received for a user with pending status
 	ErrGovernorUserPendingStatus = errors.New("governor user has pending status")
 
@@ -23,9 +26,36 @@ var (
 	// user in the governor group
 	ErrGroupMembershipFound = errors.New("delete request user found in group")
 
+	// ErrInvalidUserGroupTemplate is returned when a user group name, handle or description template is invalid
+	ErrInvalidUserGroupTemplate = errors.New("invalid user group template")
+
+	// ErrNoAvailableHandle is returned when all the handle candidates for a user group are taken
+	ErrNoAvailableHandle = errors.New("no available slack user group handle")
+
 	// ErrSlackUserGroupNotFound is returned when the slack user group is not found
 	ErrSlackUserGroupNotFound = errors.New("slack user group not found")
 
+	// ErrUserGroupAlreadyManaged is returned when adopting a slack user group that's bound to another governor group
+	ErrUserGroupAlreadyManaged = errors.New("slack user group is already managed for another governor group")
+
+	// ErrUserGroupNotManaged is returned when the slack user group isn't managed by the addon
+	ErrUserGroupNotManaged = errors.New("slack user group is not managed by governor")
+
+	// ErrMembershipRequestsDisabled is returned when requesting a group membership without a governor requests client
+	ErrMembershipRequestsDisabled = errors.New("governor membership requests are disabled")
+
+	// ErrMembershipRequestPending is returned when the user already has a pending request to join the group
+	ErrMembershipRequestPending = errors.New("governor membership request already pending")
+
+	// ErrMembershipRequestWrongUser is returned when governor files the membership request for another user
+	ErrMembershipRequestWrongUser = errors.New("governor membership request filed for another user")
+
+	// ErrAlreadyGroupMember is returned when requesting to join a group the user is already a member of
+	ErrAlreadyGroupMember = errors.New("user is already a member of the governor group")
+
+	// ErrGovernorUserNotFound is returned when no governor user matches the slack user
+	ErrGovernorUserNotFound = errors.New("governor user not found")
+
 	// ErrSlackWorkspaceNotFound is returned when the slack workspace (team) is not found
 	ErrSlackWorkspaceNotFound = errors.New("slack workspace not found")
 )
@@ -0,0 +1,257 @@
+package reconciler
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/metal-toolbox/governor-api/pkg/api/v1alpha1"
+	"go.uber.org/zap"
+)
+
+// ExclusionRule protects slack members added by hand to the user groups, such as bots, integration
+// users or on-call aliases, from being removed, and excludes governor users from being synced. A rule
+// applies to the user groups matching all of its selectors, and all the matching rules apply.
+type ExclusionRule struct {
+	// Group is the governor group id or slug, the rule applies to all the groups if empty
+	Group string
+	// Workspace is the slack workspace name, the rule applies to all the workspaces if empty
+	Workspace string
+	// UserGroup is the slack user group id or handle, the rule applies to all the user groups if empty
+	UserGroup string
+	// KeepSlackUsers are the ids of the slack users that are never removed from the user groups
+	KeepSlackUsers []string
+	// KeepEmails are the emails of the slack users that are never removed from the user groups
+	KeepEmails []string
+	// KeepBots keeps the slack bot users in the user groups
+	KeepBots bool
+	// IgnoreGovernorUsers are the ids or emails of the governor users that are never synced, they're
+	// treated as if they weren't members of the governor groups
+	IgnoreGovernorUsers []string
+}
+
+// exclusions are the exclusion rules matching a user group, merged together
+type exclusions struct {
+	keepUsers  map[string]bool
+	keepEmails map[string]bool
+	keepBots   bool
+	ignore     map[string]bool
+}
+
+// exclusions returns the exclusion rules matching the user group of the governor group in the workspace
+func (r *Reconciler) exclusions(group *v1alpha1.Group, workspace string, ug *UserGroup) *exclusions {
+	ex := &exclusions{
+		keepUsers:  map[string]bool{},
+		keepEmails: map[string]bool{},
+		ignore:     map[string]bool{},
+	}
+
+	for _, rule := range r.exclusionRules {
+		if rule.Group != "" && rule.Group != group.ID && rule.Group != group.Slug {
+			continue
+		}
+
+		if rule.Workspace != "" && rule.Workspace != workspace {
+			continue
+		}
+
+		if rule.UserGroup != "" && (ug == nil || (rule.UserGroup != ug.ID && rule.UserGroup != ug.Handle)) {
+			continue
+		}
+
+		for _, id := range rule.KeepSlackUsers {
+			ex.keepUsers[id] = true
+		}
+
+		for _, email := range rule.KeepEmails {
+			ex.keepEmails[strings.ToLower(email)] = true
+		}
+
+		for _, u := range rule.IgnoreGovernorUsers {
+			ex.ignore[strings.ToLower(u)] = true
+		}
+
+		ex.keepBots = ex.keepBots || rule.KeepBots
+	}
+
+	return ex
+}
+
+// ignored returns true if the governor user must not be synced
+func (e *exclusions) ignored(userID, email string) bool {
+	return e.ignore[strings.ToLower(userID)] || (email != "" && e.ignore[strings.ToLower(email)])
+}
+
+// withoutIgnored returns the governor group members that aren't ignored
+func (e *exclusions) withoutIgnored(logger *zap.Logger, members []*v1alpha1.GroupMember) []*v1alpha1.GroupMember {
+	if len(e.ignore) == 0 {
+		return members
+	}
+
+	out := make([]*v1alpha1.GroupMember, 0, len(members))
+
+	for _, m := range members {
+		if e.ignored(m.ID, m.Email) {
+			logger.Debug("governor user excluded from sync", zap.String("governor.user.id", m.ID), zap.String("user.email", m.Email))
+			continue
+		}
+
+		out = append(out, m)
+	}
+
+	return out
+}
+
+// slackUserTTL is how long the bot flag and email of a slack user are cached for the exclusion rules
+const slackUserTTL = time.Hour
+
+// slackUserInfo is the part of a slack user the exclusion rules look at
+type slackUserInfo struct {
+	email     string
+	bot       bool
+	fetchedAt time.Time
+}
+
+// slackUserCache keeps the bot flag and email of the slack users looked up for the exclusion rules,
+// so the protected users aren't looked up on every pass
+type slackUserCache struct {
+	mu    sync.RWMutex
+	users map[string]slackUserInfo
+}
+
+func newSlackUserCache() *slackUserCache {
+	return &slackUserCache{users: make(map[string]slackUserInfo)}
+}
+
+// get returns the cached slack user, unless it's missing or expired
+func (c *slackUserCache) get(id string, now time.Time) (slackUserInfo, bool) {
+	if c == nil {
+		return slackUserInfo{}, false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	u, ok := c.users[id]
+	if !ok || now.Sub(u.fetchedAt) > slackUserTTL {
+		return slackUserInfo{}, false
+	}
+
+	return u, true
+}
+
+// set caches the slack user
+func (c *slackUserCache) set(id string, u slackUserInfo) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.users[id] = u
+}
+
+// slackUserInfo returns the bot flag and email of the slack user, from the cache or from slack
+func (r *Reconciler) slackUserInfo(ctx context.Context, id string) (slackUserInfo, error) {
+	now := time.Now()
+
+	if u, ok := r.slackUsers.get(id, now); ok {
+		return u, nil
+	}
+
+	su, err := r.Client.GetUser(ctx, id)
+	if err != nil {
+		return slackUserInfo{}, err
+	}
+
+	r.emails.set(id, su.Profile.Email)
+
+	u := slackUserInfo{email: strings.ToLower(su.Profile.Email), bot: su.IsBot, fetchedAt: now}
+	r.slackUsers.set(id, u)
+
+	return u, nil
+}
+
+// protectedUsers returns the slack users that must be kept in the user group. The slack profile is
+// only looked up if a rule keeps bots or emails, it's cached for an hour, and a user is kept if the
+// lookup fails, so we don't end up removing a protected user.
+func (r *Reconciler) protectedUsers(ctx context.Context, logger *zap.Logger, ex *exclusions, userIDs []string) []string {
+	var kept []string
+
+	for _, id := range userIDs {
+		if ex.keepUsers[id] {
+			kept = append(kept, id)
+			continue
+		}
+
+		if !ex.keepBots && len(ex.keepEmails) == 0 {
+			continue
+		}
+
+		u, err := r.slackUserInfo(ctx, id)
+		if err != nil {
+			logger.Warn("failed to get slack user, keeping it in the user group", zap.String("slack.user.id", id), zap.Error(err))
+			kept = append(kept, id)
+
+			continue
+		}
+
+		if (ex.keepBots && u.bot) || ex.keepEmails[u.email] {
+			kept = append(kept, id)
+		}
+	}
+
+	if len(kept) != 0 {
+		logger.Debug("keeping protected slack users", zap.Strings("slack.user.ids", kept))
+	}
+
+	return kept
+}
+
+// desiredMembers returns the slack users the user group of the governor group should have: the
+// governor members matched to slack users, unless they're ignored, along with the protected users
+// already in the user group. Deactivated users and users that haven't joined the workspace are only
+// kept if they're already in the user group, since slack rejects them otherwise. The members that
+// couldn't be matched are returned too.
+func (r *Reconciler) desiredMembers(
+	ctx context.Context,
+	logger *zap.Logger,
+	group *v1alpha1.Group,
+	workspace, teamID string,
+	ug *UserGroup,
+	members []*v1alpha1.GroupMember,
+) ([]string, []UnmatchedMember, error) {
+	ex := r.exclusions(group, workspace, ug)
+
+	desired, unmatched, err := r.matchSlackUsers(ctx, logger, teamID, ex.withoutIgnored(logger, members))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	unusable := unmatchedSlackUsers(unmatched)
+	desired = difference(desired, difference(unusable, ug.Users))
+
+	desired = append(desired, r.protectedUsers(ctx, logger, ex, difference(ug.Users, desired))...)
+
+	return desired, unmatched, nil
+}
+
+// channelMembers returns the slack users the channels linked to the governor group should have: the
+// governor members matched to slack users, unless they're ignored. Deactivated users and users that
+// haven't joined the workspace are left out, since they can't be invited to the channels.
+func (r *Reconciler) channelMembers(
+	ctx context.Context,
+	logger *zap.Logger,
+	teamID string,
+	ex *exclusions,
+	members []*v1alpha1.GroupMember,
+) ([]string, error) {
+	userIDs, unmatched, err := r.matchSlackUsers(ctx, logger, teamID, ex.withoutIgnored(logger, members))
+	if err != nil {
+		return nil, err
+	}
+
+	return difference(userIDs, unmatchedSlackUsers(unmatched)), nil
+}
@@ -0,0 +1,213 @@
+package reconciler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/metal-toolbox/governor-api/pkg/api/v1alpha1"
+	slackgo "github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+func TestReconciler_exclusions(t *testing.T) {
+	r := &Reconciler{
+		exclusionRules: []ExclusionRule{
+			{KeepBots: true},
+			{Group: "group-slug", KeepSlackUsers: []string{"U1"}, IgnoreGovernorUsers: []string{"Bot@Example.com"}},
+			{Workspace: "workspace-a", UserGroup: "oncall", KeepEmails: []string{"Pager@Example.com"}},
+			{Group: "other-group", KeepSlackUsers: []string{"U2"}, IgnoreGovernorUsers: []string{"gov-user"}},
+		},
+	}
+
+	group := &v1alpha1.Group{ID: "group-id", Slug: "group-slug"}
+
+	tests := []struct {
+		name      string
+		workspace string
+		ug        *UserGroup
+		want      *exclusions
+	}{
+		{
+			name:      "matching user group",
+			workspace: "workspace-a",
+			ug:        &UserGroup{ID: "S1", Handle: "oncall"},
+			want: &exclusions{
+				keepUsers:  map[string]bool{"U1": true},
+				keepEmails: map[string]bool{"pager@example.com": true},
+				keepBots:   true,
+				ignore:     map[string]bool{"bot@example.com": true},
+			},
+		},
+		{
+			name:      "other workspace",
+			workspace: "workspace-b",
+			ug:        &UserGroup{ID: "S1", Handle: "oncall"},
+			want: &exclusions{
+				keepUsers:  map[string]bool{"U1": true},
+				keepEmails: map[string]bool{},
+				keepBots:   true,
+				ignore:     map[string]bool{"bot@example.com": true},
+			},
+		},
+		{
+			name:      "no user group",
+			workspace: "workspace-a",
+			want: &exclusions{
+				keepUsers:  map[string]bool{"U1": true},
+				keepEmails: map[string]bool{},
+				keepBots:   true,
+				ignore:     map[string]bool{"bot@example.com": true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.exclusions(group, tt.workspace, tt.ug); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("exclusions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_exclusions_withoutIgnored(t *testing.T) {
+	ex := &exclusions{ignore: map[string]bool{"gov-1": true, "bot@example.com": true}}
+
+	members := []*v1alpha1.GroupMember{
+		{ID: "gov-1", Email: "one@example.com"},
+		{ID: "gov-2", Email: "BOT@example.com"},
+		{ID: "gov-3", Email: "three@example.com"},
+	}
+
+	got := ex.withoutIgnored(zap.NewNop(), members)
+
+	if len(got) != 1 || got[0].ID != "gov-3" {
+		t.Errorf("withoutIgnored() = %v, want [gov-3]", got)
+	}
+
+	if got := (&exclusions{}).withoutIgnored(zap.NewNop(), members); len(got) != len(members) {
+		t.Errorf("withoutIgnored() without rules = %v, want all the members", got)
+	}
+}
+
+func TestReconciler_protectedUsers(t *testing.T) {
+	r := &Reconciler{}
+	ex := &exclusions{keepUsers: map[string]bool{"U1": true}}
+
+	// the slack profiles aren't looked up unless bots or emails are kept
+	got := r.protectedUsers(context.Background(), zap.NewNop(), ex, []string{"U1", "U2"})
+	if !reflect.DeepEqual(got, []string{"U1"}) {
+		t.Errorf("protectedUsers() = %v, want [U1]", got)
+	}
+
+	// the cached slack users aren't looked up again
+	r.slackUsers = newSlackUserCache()
+	r.slackUsers.set("U2", slackUserInfo{bot: true, fetchedAt: time.Now()})
+	r.slackUsers.set("U3", slackUserInfo{email: "pager@example.com", fetchedAt: time.Now()})
+	r.slackUsers.set("U4", slackUserInfo{email: "someone@example.com", fetchedAt: time.Now()})
+
+	ex = &exclusions{keepBots: true, keepEmails: map[string]bool{"pager@example.com": true}}
+
+	got = r.protectedUsers(context.Background(), zap.NewNop(), ex, []string{"U2", "U3", "U4"})
+	if !reflect.DeepEqual(got, []string{"U2", "U3"}) {
+		t.Errorf("protectedUsers() = %v, want [U2 U3]", got)
+	}
+}
+
+func Test_slackUserCache(t *testing.T) {
+	c := newSlackUserCache()
+	now := time.Now()
+
+	c.set("U1", slackUserInfo{bot: true, fetchedAt: now})
+
+	if u, ok := c.get("U1", now.Add(time.Minute)); !ok || !u.bot {
+		t.Errorf("get() = %+v, %v, want the cached user", u, ok)
+	}
+
+	if _, ok := c.get("U1", now.Add(slackUserTTL+time.Second)); ok {
+		t.Error("expected the cached user to expire")
+	}
+
+	if _, ok := c.get("U2", now); ok {
+		t.Error("expected a missing user not to be found")
+	}
+}
+
+func TestReconciler_channelMembers(t *testing.T) {
+	r := &Reconciler{
+		identity: &mockResolver{users: map[string]*slackgo.User{
+			"active@example.com":      {ID: "U1", TeamID: "T1"},
+			"bot@example.com":         {ID: "U2", TeamID: "T1"},
+			"deactivated@example.com": {ID: "U3", TeamID: "T1", Deleted: true},
+		}},
+		emails: newUserEmails(),
+	}
+
+	ex := &exclusions{ignore: map[string]bool{"bot@example.com": true}}
+
+	members := []*v1alpha1.GroupMember{
+		{ID: "gov-1", Email: "active@example.com"},
+		{ID: "gov-2", Email: "bot@example.com"},
+		{ID: "gov-3", Email: "deactivated@example.com"},
+	}
+
+	got, err := r.channelMembers(context.Background(), zap.NewNop(), "T1", ex, members)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// ignored governor users aren't invited, and deactivated users can't be
+	if want := []string{"U1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("channelMembers() = %v, want %v", got, want)
+	}
+}
+
+func TestReconciler_channelRemovals(t *testing.T) {
+	r := &Reconciler{selfID: "USELF"}
+	ex := &exclusions{keepUsers: map[string]bool{"U3": true}}
+
+	got := r.channelRemovals(context.Background(), zap.NewNop(), ex, []string{"U1", "U2", "U3", "USELF"}, []string{"U1"})
+
+	// the addon's own user and the protected users are kept in the channel
+	if want := []string{"U2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("channelRemovals() = %v, want %v", got, want)
+	}
+}
+
+func TestReconciler_desiredMembers(t *testing.T) {
+	r := &Reconciler{
+		identity: &mockResolver{users: map[string]*slackgo.User{
+			"active@example.com":     {ID: "U1", TeamID: "T1"},
+			"deleted@example.com":    {ID: "U2", TeamID: "T1", Deleted: true},
+			"deleted-in@example.com": {ID: "U3", TeamID: "T1", Deleted: true},
+			"elsewhere@example.com":  {ID: "U4", Enterprise: slackgo.EnterpriseUser{Teams: []string{"T2"}}},
+		}},
+		emails: newUserEmails(),
+	}
+
+	group := &v1alpha1.Group{ID: "group-id", Slug: "group-slug"}
+	ug := &UserGroup{ID: "S1", Users: []string{"U1", "U3"}}
+
+	members := []*v1alpha1.GroupMember{
+		{ID: "gov-1", Email: "active@example.com"},
+		{ID: "gov-2", Email: "deleted@example.com"},
+		{ID: "gov-3", Email: "deleted-in@example.com"},
+		{ID: "gov-4", Email: "elsewhere@example.com"},
+	}
+
+	got, unmatched, err := r.desiredMembers(context.Background(), zap.NewNop(), group, "workspace-a", "T1", ug, members)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// unusable slack users are kept if they're already in the user group, and never added
+	if want := []string{"U1", "U3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("desiredMembers() = %v, want %v", got, want)
+	}
+
+	if len(unmatched) != 3 {
+		t.Errorf("desiredMembers() unmatched = %v, want 3 members", unmatched)
+	}
+}
@@ -0,0 +1,237 @@
+package reconciler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/metal-toolbox/governor-api/pkg/api/v1alpha1"
+	slackgo "github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// groupFingerprint is the state of a governor group and of its slack user group in a workspace when
+// the group was last reconciled without failures
+type groupFingerprint struct {
+	governor string
+	slack    string
+}
+
+// fingerprints keeps the fingerprint of each governor group and slack application reconciled, so
+// the groups that haven't changed since can be skipped
+type fingerprints struct {
+	mu           sync.Mutex
+	fingerprints map[string]groupFingerprint
+}
+
+func newFingerprints() *fingerprints {
+	return &fingerprints{fingerprints: make(map[string]groupFingerprint)}
+}
+
+// unchanged returns true if the group was reconciled in the application with the same fingerprint
+func (f *fingerprints) unchanged(groupID, appID string, fp groupFingerprint) bool {
+	if fp.governor == "" || fp.slack == "" {
+		return false
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.fingerprints[groupID+"/"+appID] == fp
+}
+
+// set records the fingerprint of the group reconciled in the application
+func (f *fingerprints) set(groupID, appID string, fp groupFingerprint) {
+	if fp.governor == "" || fp.slack == "" {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.fingerprints[groupID+"/"+appID] = fp
+}
+
+// forget removes the fingerprints of the group, so it's reconciled in the next pass. It's used when
+// a governor event changed the group, since the group update time doesn't track its members.
+func (f *fingerprints) forget(groupID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for key := range f.fingerprints {
+		if strings.HasPrefix(key, groupID+"/") {
+			delete(f.fingerprints, key)
+		}
+	}
+}
+
+// fingerprintMember is the state of a governor group member that matters to the slack user group
+type fingerprintMember struct {
+	ID     string `json:"id"`
+	Email  string `json:"email"`
+	Status string `json:"status"`
+}
+
+// governorFingerprint hashes the governor group as listed for the application, along with its members
+// and settings. The members are hashed with their email and status, since changing them doesn't update
+// the group.
+func governorFingerprint(g *v1alpha1.Group, members []*v1alpha1.GroupMember, settings *GroupSettings) string {
+	fm := make([]fingerprintMember, 0, len(members))
+	for _, m := range members {
+		fm = append(fm, fingerprintMember{ID: m.ID, Email: strings.ToLower(m.Email), Status: m.Status.String})
+	}
+
+	slices.SortFunc(fm, func(a, b fingerprintMember) int { return strings.Compare(a.ID, b.ID) })
+
+	return hashJSON(struct {
+		ID          string              `json:"id"`
+		Name        string              `json:"name"`
+		Slug        string              `json:"slug"`
+		Description string              `json:"description"`
+		Members     []fingerprintMember `json:"members"`
+		UpdatedAt   time.Time           `json:"updated_at"`
+		Settings    *GroupSettings      `json:"settings"`
+	}{
+		ID:          g.ID,
+		Name:        g.Name,
+		Slug:        g.Slug,
+		Description: g.Description,
+		Members:     fm,
+		UpdatedAt:   g.UpdatedAt,
+		Settings:    settings,
+	})
+}
+
+// slackFingerprint hashes the slack user group, its update time and members
+func slackFingerprint(ug *slackgo.UserGroup) string {
+	return hashJSON(struct {
+		ID          string   `json:"id"`
+		Name        string   `json:"name"`
+		Handle      string   `json:"handle"`
+		Description string   `json:"description"`
+		Users       []string `json:"users"`
+		Channels    []string `json:"channels"`
+		DateUpdate  int64    `json:"date_update"`
+	}{
+		ID:          ug.ID,
+		Name:        ug.Name,
+		Handle:      ug.Handle,
+		Description: ug.Description,
+		Users:       sorted(ug.Users),
+		Channels:    sorted(ug.Prefs.Channels),
+		DateUpdate:  int64(ug.DateUpdate),
+	})
+}
+
+// hashJSON returns the hex encoded sha256 hash of the json encoding of v
+func hashJSON(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// sorted returns a sorted copy of the list
+func sorted(l []string) []string {
+	out := slices.Clone(l)
+	slices.Sort(out)
+
+	return out
+}
+
+// passUserGroups lists the slack user groups of each slack team once per pass, to fingerprint them.
+// The groups are fingerprinted by the workers, so it's safe for concurrent use.
+type passUserGroups struct {
+	mu    sync.Mutex
+	teams map[string]*teamUserGroups
+}
+
+// teamUserGroups are the slack user groups of a team, by id
+type teamUserGroups struct {
+	once   sync.Once
+	groups map[string]slackgo.UserGroup
+}
+
+// team returns the user groups of the slack team, they're listed by the first caller
+func (p *passUserGroups) team(teamID string) *teamUserGroups {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.teams == nil {
+		p.teams = make(map[string]*teamUserGroups)
+	}
+
+	t, ok := p.teams[teamID]
+	if !ok {
+		t = &teamUserGroups{}
+		p.teams[teamID] = t
+	}
+
+	return t
+}
+
+// groupFingerprint returns the fingerprint of the governor group and of its slack user group in the
+// workspace of the application. The governor fingerprint is empty if the group members couldn't be
+// listed, and the slack fingerprint is empty if the user group isn't bound to the group yet, or the
+// user groups of the workspace couldn't be listed.
+func (r *Reconciler) groupFingerprint(
+	ctx context.Context,
+	logger *zap.Logger,
+	ugs *passUserGroups,
+	app *v1alpha1.Application,
+	g *v1alpha1.Group,
+) groupFingerprint {
+	fp := groupFingerprint{}
+
+	members, err := r.GovernorClient.GroupMembers(ctx, g.ID)
+	if err != nil {
+		logger.Warn("failed to list governor group members, reconciling the group", zap.String("governor.group.id", g.ID), zap.Error(err))
+		return fp
+	}
+
+	fp.governor = governorFingerprint(g, members, r.groupSettings(ctx, g.ID))
+
+	// unmatched members may have joined slack or the workspace since, which doesn't change the group
+	if len(r.unmatched.get(g.ID, app.ID).Members) != 0 {
+		return fp
+	}
+
+	teamID, err := r.userGroupTeamID(ctx, app.Name)
+	if err != nil {
+		return fp
+	}
+
+	m, err := r.store.GetMapping(ctx, g.ID, teamID)
+	if err != nil || m.UserGroupID == "" {
+		return fp
+	}
+
+	team := ugs.team(teamID)
+
+	team.once.Do(func() {
+		list, err := r.Client.GetUserGroups(ctx, teamID, false)
+		if err != nil {
+			logger.Warn("failed to list user groups, reconciling all the groups of the workspace", zap.String("slack.workspace.id", teamID), zap.Error(err))
+		}
+
+		team.groups = make(map[string]slackgo.UserGroup, len(list))
+		for _, ug := range list {
+			team.groups[ug.ID] = ug
+		}
+	})
+
+	if ug, ok := team.groups[m.UserGroupID]; ok {
+		fp.slack = slackFingerprint(&ug)
+	}
+
+	return fp
+}
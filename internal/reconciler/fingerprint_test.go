@@ -0,0 +1,127 @@
+package reconciler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/metal-toolbox/governor-api/pkg/api/v1alpha1"
+	slackgo "github.com/slack-go/slack"
+)
+
+func Test_fingerprints(t *testing.T) {
+	f := newFingerprints()
+	fp := groupFingerprint{governor: "gov", slack: "slack"}
+
+	if f.unchanged("group-a", "app-a", fp) {
+		t.Error("expected a group never reconciled to be changed")
+	}
+
+	f.set("group-a", "app-a", fp)
+	f.set("group-a", "app-b", fp)
+	f.set("group-b", "app-a", fp)
+
+	if !f.unchanged("group-a", "app-a", fp) {
+		t.Error("expected a group with the same fingerprint to be unchanged")
+	}
+
+	if f.unchanged("group-a", "app-a", groupFingerprint{governor: "gov", slack: "other"}) {
+		t.Error("expected a group with a different fingerprint to be changed")
+	}
+
+	if f.unchanged("group-c", "app-a", groupFingerprint{governor: "gov"}) {
+		t.Error("expected a group without a slack fingerprint to be changed")
+	}
+
+	f.forget("group-a")
+
+	if f.unchanged("group-a", "app-a", fp) || f.unchanged("group-a", "app-b", fp) {
+		t.Error("expected the fingerprints of a forgotten group to be removed")
+	}
+
+	if !f.unchanged("group-b", "app-a", fp) {
+		t.Error("expected the fingerprints of the other groups to be kept")
+	}
+}
+
+func Test_governorFingerprint(t *testing.T) {
+	updated := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	g := &v1alpha1.Group{ID: "group-a", Slug: "team-a", UpdatedAt: updated}
+
+	u1 := &v1alpha1.GroupMember{ID: "u1", Email: "one@example.com", Status: v1alpha1.NullString{String: "active", Valid: true}}
+	u2 := &v1alpha1.GroupMember{ID: "u2", Email: "two@example.com", Status: v1alpha1.NullString{String: "active", Valid: true}}
+
+	fp := governorFingerprint(g, []*v1alpha1.GroupMember{u1, u2}, nil)
+
+	if got := governorFingerprint(g, []*v1alpha1.GroupMember{u2, u1}, nil); got != fp {
+		t.Error("expected the fingerprint not to depend on the order of the members")
+	}
+
+	if got := governorFingerprint(g, []*v1alpha1.GroupMember{u1}, nil); got == fp {
+		t.Error("expected the fingerprint to change with the members")
+	}
+
+	renamed := *u2
+	renamed.Email = "deux@example.com"
+
+	if got := governorFingerprint(g, []*v1alpha1.GroupMember{u1, &renamed}, nil); got == fp {
+		t.Error("expected the fingerprint to change with the member emails")
+	}
+
+	pending := *u2
+	pending.Status = v1alpha1.NullString{String: v1alpha1.UserStatusPending, Valid: true}
+
+	if got := governorFingerprint(g, []*v1alpha1.GroupMember{u1, &pending}, nil); got == fp {
+		t.Error("expected the fingerprint to change with the member statuses")
+	}
+
+	later := &v1alpha1.Group{ID: "group-a", Slug: "team-a", UpdatedAt: updated.Add(time.Second)}
+
+	if got := governorFingerprint(later, []*v1alpha1.GroupMember{u1, u2}, nil); got == fp {
+		t.Error("expected the fingerprint to change with the update time")
+	}
+
+	if got := governorFingerprint(g, []*v1alpha1.GroupMember{u1, u2}, &GroupSettings{GroupID: "group-a", Handle: "team"}); got == fp {
+		t.Error("expected the fingerprint to change with the group settings")
+	}
+}
+
+func Test_passUserGroups(t *testing.T) {
+	var ugs passUserGroups
+
+	a := ugs.team("T1")
+
+	if ugs.team("T1") != a {
+		t.Error("expected the user groups of a team to be shared during the pass")
+	}
+
+	if ugs.team("T2") == a {
+		t.Error("expected each team to have its own user groups")
+	}
+}
+
+func Test_slackFingerprint(t *testing.T) {
+	ug := slackgo.UserGroup{ID: "S0001", Handle: "team-a", Users: []string{"U1", "U2"}, DateUpdate: 100}
+
+	fp := slackFingerprint(&ug)
+
+	reordered := ug
+	reordered.Users = []string{"U2", "U1"}
+
+	if slackFingerprint(&reordered) != fp {
+		t.Error("expected the fingerprint not to depend on the order of the users")
+	}
+
+	updated := ug
+	updated.DateUpdate = 200
+
+	if slackFingerprint(&updated) == fp {
+		t.Error("expected the fingerprint to change with the update time")
+	}
+
+	renamed := ug
+	renamed.Handle = "team-b"
+
+	if slackFingerprint(&renamed) == fp {
+		t.Error("expected the fingerprint to change with the handle")
+	}
+}
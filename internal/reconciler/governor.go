@@ -0,0 +1,63 @@
+package reconciler
+
+import (
+	"context"
+
+	"github.com/metal-toolbox/governor-api/pkg/api/v1alpha1"
+	governor "github.com/metal-toolbox/governor-api/pkg/client"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/govapi"
+)
+
+// governorClient is the governor client used by the reconciler. The governor-api client doesn't
+// provide the user lookup by email and the membership request calls, they go through govapi.
+type governorClient struct {
+	*governor.Client
+
+	api *govapi.Client
+}
+
+// UserByEmail returns the governor user with the email
+func (c *governorClient) UserByEmail(ctx context.Context, email string) (*v1alpha1.User, error) {
+	if c.api == nil {
+		return nil, ErrMembershipRequestsDisabled
+	}
+
+	return c.api.UserByEmail(ctx, email)
+}
+
+// CreateGroupMembershipRequest files a request for the governor user to join the group
+func (c *governorClient) CreateGroupMembershipRequest(ctx context.Context, groupID, userID, note string) (*govapi.MembershipRequest, error) {
+	if c.api == nil {
+		return nil, ErrMembershipRequestsDisabled
+	}
+
+	return c.api.CreateGroupMembershipRequest(ctx, groupID, userID, note)
+}
+
+// GroupMembershipRequests returns the pending membership requests of the group
+func (c *governorClient) GroupMembershipRequests(ctx context.Context, groupID string) ([]*govapi.MembershipRequest, error) {
+	if c.api == nil {
+		return nil, ErrMembershipRequestsDisabled
+	}
+
+	return c.api.GroupMembershipRequests(ctx, groupID)
+}
+
+// GroupMembershipRequest returns a membership request of the group, whatever its status
+func (c *governorClient) GroupMembershipRequest(ctx context.Context, groupID, requestID string) (*govapi.MembershipRequest, error) {
+	if c.api == nil {
+		return nil, ErrMembershipRequestsDisabled
+	}
+
+	return c.api.GroupMembershipRequest(ctx, groupID, requestID)
+}
+
+// DeleteGroupMembershipRequest withdraws a membership request of the group
+func (c *governorClient) DeleteGroupMembershipRequest(ctx context.Context, groupID, requestID string) error {
+	if c.api == nil {
+		return ErrMembershipRequestsDisabled
+	}
+
+	return c.api.DeleteGroupMembershipRequest(ctx, groupID, requestID)
+}
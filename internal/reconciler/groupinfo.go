@@ -0,0 +1,189 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/metal-toolbox/governor-api/pkg/api/v1alpha1"
+	"go.uber.org/zap"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/store"
+)
+
+// maxGroupInfoMembers is the number of members listed by FormatGroupInfo
+const maxGroupInfoMembers = 50
+
+// subteamMention matches an escaped slack user group mention, e.g. <!subteam^S0123456789|@platform>
+var subteamMention = regexp.MustCompile(`^<!subteam\^([A-Z0-9]+)(\|[^>]*)?>$`)
+
+// GroupInfo describes the governor group behind a managed slack user group
+type GroupInfo struct {
+	UserGroupID       string
+	UserGroupHandle   string
+	GovernorGroupID   string
+	GovernorGroupSlug string
+	GovernorGroupName string
+	Description       string
+	Admins            []*v1alpha1.GroupMember
+	Members           []*v1alpha1.GroupMember
+	// RequestURL is the governor ui page where membership can be requested, if the ui url is set
+	RequestURL string
+}
+
+// GroupInfo returns the governor group behind the managed slack user group, referenced by mention,
+// handle (with or without @) or id. ErrUserGroupNotManaged is returned if the user group isn't
+// managed by the addon in the slack team.
+func (r *Reconciler) GroupInfo(ctx context.Context, teamID, ref string) (*GroupInfo, error) {
+	if teamID == "" || ref == "" {
+		return nil, ErrBadParameter
+	}
+
+	m, err := r.mappingForRef(ctx, teamID, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if m == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUserGroupNotManaged, ref)
+	}
+
+	group, err := r.GovernorClient.Group(ctx, m.GovernorGroupID, false)
+	if err != nil {
+		r.Logger.Error("error getting governor group", zap.String("governor.group.id", m.GovernorGroupID), zap.Error(err))
+		return nil, err
+	}
+
+	members, err := r.GovernorClient.GroupMembers(ctx, group.ID)
+	if err != nil {
+		r.Logger.Error("error getting governor group members", zap.String("governor.group.id", group.ID), zap.Error(err))
+		return nil, err
+	}
+
+	info := &GroupInfo{
+		UserGroupID:       m.UserGroupID,
+		UserGroupHandle:   m.Handle,
+		GovernorGroupID:   group.ID,
+		GovernorGroupSlug: group.Slug,
+		GovernorGroupName: group.Name,
+		Description:       group.Description,
+		Admins:            []*v1alpha1.GroupMember{},
+		Members:           []*v1alpha1.GroupMember{},
+	}
+
+	for _, member := range members {
+		if member.IsAdmin {
+			info.Admins = append(info.Admins, member)
+		}
+
+		info.Members = append(info.Members, member)
+	}
+
+	sortGroupMembers(info.Admins)
+	sortGroupMembers(info.Members)
+
+	if r.governorUIURL != "" {
+		info.RequestURL = strings.TrimSuffix(r.governorUIURL, "/") + "/groups/" + group.ID
+	}
+
+	return info, nil
+}
+
+// mappingForRef returns the mapping of the user group referenced by mention, handle or id in the
+// slack team, or nil if it isn't managed. Org-wide user groups are available in all the workspaces.
+func (r *Reconciler) mappingForRef(ctx context.Context, teamID, ref string) (*store.UserGroupMapping, error) {
+	id, handle := parseUserGroupRef(ref)
+
+	mappings, err := r.store.ListMappings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range mappings {
+		if m.TeamID != teamID && !r.orgWide() {
+			continue
+		}
+
+		if (id != "" && m.UserGroupID == id) || (handle != "" && m.Handle == handle) {
+			return m, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// parseUserGroupRef returns the user group id of an escaped mention, or the handle otherwise
+func parseUserGroupRef(ref string) (string, string) {
+	ref = strings.TrimSpace(ref)
+
+	if match := subteamMention.FindStringSubmatch(ref); match != nil {
+		return match[1], ""
+	}
+
+	handle := strings.ToLower(strings.TrimPrefix(ref, "@"))
+
+	// user group ids are accepted as is
+	if strings.HasPrefix(ref, "S") && strings.ToUpper(ref) == ref {
+		return ref, handle
+	}
+
+	return "", handle
+}
+
+// sortGroupMembers sorts the members by name, then email
+func sortGroupMembers(members []*v1alpha1.GroupMember) {
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].Name != members[j].Name {
+			return members[i].Name < members[j].Name
+		}
+
+		return members[i].Email < members[j].Email
+	})
+}
+
+// FormatGroupInfo renders the group info as a message suitable for posting to slack
+func FormatGroupInfo(info *GroupInfo) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "*@%s* is managed by the governor group *%s* (`%s`)\n", info.UserGroupHandle, info.GovernorGroupName, info.GovernorGroupSlug)
+
+	if info.Description != "" {
+		fmt.Fprintf(&b, "%s\n", info.Description)
+	}
+
+	fmt.Fprintf(&b, "\n*Admins (%d)*\n", len(info.Admins))
+
+	for _, m := range info.Admins {
+		fmt.Fprintf(&b, "• %s\n", formatGroupMember(m))
+	}
+
+	fmt.Fprintf(&b, "\n*Members (%d)*\n", len(info.Members))
+
+	for i, m := range info.Members {
+		if i == maxGroupInfoMembers {
+			fmt.Fprintf(&b, "_and %d more_\n", len(info.Members)-maxGroupInfoMembers)
+			break
+		}
+
+		fmt.Fprintf(&b, "• %s\n", formatGroupMember(m))
+	}
+
+	if info.RequestURL != "" {
+		fmt.Fprintf(&b, "\nTo join, request membership in governor: %s\n", info.RequestURL)
+	} else {
+		b.WriteString("\nTo join, ask one of the admins or request membership in governor.\n")
+	}
+
+	return b.String()
+}
+
+// formatGroupMember returns the name and email of the member
+func formatGroupMember(m *v1alpha1.GroupMember) string {
+	if m.Name == "" {
+		return m.Email
+	}
+
+	return fmt.Sprintf("%s (%s)", m.Name, m.Email)
+}
@@ -0,0 +1,105 @@
+package reconciler
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/metal-toolbox/governor-api/pkg/api/v1alpha1"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/store"
+)
+
+func Test_parseUserGroupRef(t *testing.T) {
+	tests := []struct {
+		ref        string
+		wantID     string
+		wantHandle string
+	}{
+		{ref: "<!subteam^S0123ABCDEF|@platform>", wantID: "S0123ABCDEF"},
+		{ref: "<!subteam^S0123ABCDEF>", wantID: "S0123ABCDEF"},
+		{ref: "@platform", wantHandle: "platform"},
+		{ref: " Platform ", wantHandle: "platform"},
+		{ref: "S0123ABCDEF", wantID: "S0123ABCDEF", wantHandle: "s0123abcdef"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			id, handle := parseUserGroupRef(tt.ref)
+			if id != tt.wantID || handle != tt.wantHandle {
+				t.Errorf("parseUserGroupRef() = %q, %q, want %q, %q", id, handle, tt.wantID, tt.wantHandle)
+			}
+		})
+	}
+}
+
+func TestReconciler_mappingForRef(t *testing.T) {
+	ctx := context.TODO()
+	st := store.NewMemory()
+
+	for _, m := range []*store.UserGroupMapping{
+		{GovernorGroupID: "group-1", TeamID: "T0001", UserGroupID: "S0001", Handle: "platform"},
+		{GovernorGroupID: "group-2", TeamID: "T0002", UserGroupID: "S0002", Handle: "security"},
+	} {
+		if err := st.PutMapping(ctx, m); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	r := New(WithStore(st))
+
+	tests := []struct {
+		name      string
+		teamID    string
+		ref       string
+		wantGroup string
+	}{
+		{name: "by handle", teamID: "T0001", ref: "@platform", wantGroup: "group-1"},
+		{name: "by mention", teamID: "T0001", ref: "<!subteam^S0001|@platform>", wantGroup: "group-1"},
+		{name: "other workspace", teamID: "T0001", ref: "@security"},
+		{name: "unmanaged", teamID: "T0001", ref: "@nope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := r.mappingForRef(ctx, tt.teamID, tt.ref)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := ""
+			if m != nil {
+				got = m.GovernorGroupID
+			}
+
+			if got != tt.wantGroup {
+				t.Errorf("mappingForRef() = %q, want %q", got, tt.wantGroup)
+			}
+		})
+	}
+}
+
+func TestFormatGroupInfo(t *testing.T) {
+	admin := &v1alpha1.GroupMember{Name: "Ada", Email: "ada@example.com", IsAdmin: true}
+	info := &GroupInfo{
+		UserGroupHandle:   "platform",
+		GovernorGroupName: "Platform",
+		GovernorGroupSlug: "platform",
+		Admins:            []*v1alpha1.GroupMember{admin},
+		Members:           []*v1alpha1.GroupMember{admin, {Email: "bob@example.com"}},
+		RequestURL:        "https://governor.example.com/groups/group-1",
+	}
+
+	got := FormatGroupInfo(info)
+
+	for _, want := range []string{
+		"*@platform* is managed by the governor group *Platform*",
+		"*Admins (1)*\n• Ada (ada@example.com)\n",
+		"*Members (2)*\n• Ada (ada@example.com)\n• bob@example.com\n",
+		"https://governor.example.com/groups/group-1",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("FormatGroupInfo() = %q, missing %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package reconciler
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// maxHandleLength is the maximum length of a slack user group handle
+	maxHandleLength = 21
+	// maxHandleSuffixes is the number of numbered handles tried when a handle is taken
+	maxHandleSuffixes = 5
+	// maxWorkspaceAbbreviationLength is the maximum length of the workspace abbreviation handle suffix
+	maxWorkspaceAbbreviationLength = 4
+	// defaultHandle is used when nothing is left of a handle after sanitizing it
+	defaultHandle = "group"
+)
+
+var (
+	invalidHandleChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+	repeatedHyphens    = regexp.MustCompile(`-{2,}`)
+)
+
+// sanitizeHandle normalises a handle to slack's rules: lowercase letters, numbers, hyphens,
+// periods and underscores, up to 21 characters
+func sanitizeHandle(h string) string {
+	h = strings.ToLower(strings.TrimSpace(h))
+	h = invalidHandleChars.ReplaceAllString(h, "-")
+	h = repeatedHyphens.ReplaceAllString(h, "-")
+
+	if len(h) > maxHandleLength {
+		h = h[:maxHandleLength]
+	}
+
+	h = strings.Trim(h, "-._")
+	if h == "" {
+		return defaultHandle
+	}
+
+	return h
+}
+
+// withHandleSuffix appends the suffix to the handle, truncating the handle to keep it within
+// the maximum length
+func withHandleSuffix(h, suffix string) string {
+	if max := maxHandleLength - len(suffix) - 1; len(h) > max {
+		h = strings.TrimRight(h[:max], "-._")
+	}
+
+	return h + "-" + suffix
+}
+
+// workspaceAbbreviation returns a short abbreviation of the workspace name, made of the first
+// letter of each word, or the first letters of the name if it's a single word
+func workspaceAbbreviation(workspace string) string {
+	words := strings.FieldsFunc(strings.ToLower(workspace), func(r rune) bool {
+		return r > unicode.MaxASCII || (!unicode.IsLetter(r) && !unicode.IsDigit(r))
+	})
+
+	var abbr string
+
+	switch len(words) {
+	case 0:
+		return ""
+	case 1:
+		abbr = words[0]
+	default:
+		for _, w := range words {
+			abbr += w[:1]
+		}
+	}
+
+	if len(abbr) > maxWorkspaceAbbreviationLength {
+		abbr = abbr[:maxWorkspaceAbbreviationLength]
+	}
+
+	return abbr
+}
+
+// handleCandidates returns the handles to try, in order, when creating a user group: the handle
+// itself, the handle suffixed with the workspace abbreviation, and numbered handles
+func handleCandidates(handle, workspace string) []string {
+	candidates := []string{handle}
+
+	if abbr := workspaceAbbreviation(workspace); abbr != "" {
+		candidates = append(candidates, withHandleSuffix(handle, abbr))
+	}
+
+	for i := 2; i < maxHandleSuffixes+2; i++ {
+		candidates = append(candidates, withHandleSuffix(handle, strconv.Itoa(i)))
+	}
+
+	return candidates
+}
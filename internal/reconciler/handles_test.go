@@ -0,0 +1,65 @@
+package reconciler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_sanitizeHandle(t *testing.T) {
+	tests := []struct {
+		name   string
+		handle string
+		want   string
+	}{
+		{name: "valid", handle: "platform-team", want: "platform-team"},
+		{name: "uppercase and spaces", handle: "Platform Team", want: "platform-team"},
+		{name: "invalid characters", handle: "sre/on-call (EU)", want: "sre-on-call-eu"},
+		{name: "too long", handle: "a-very-long-governor-group-slug", want: "a-very-long-governor"},
+		{name: "trailing separators", handle: "--team.__", want: "team"},
+		{name: "nothing left", handle: "!!!", want: "group"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeHandle(tt.handle); got != tt.want {
+				t.Errorf("sanitizeHandle() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_workspaceAbbreviation(t *testing.T) {
+	tests := []struct {
+		workspace string
+		want      string
+	}{
+		{workspace: "Engineering Team EU", want: "ete"},
+		{workspace: "engineering", want: "engi"},
+		{workspace: "my-workspace", want: "mw"},
+		{workspace: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.workspace, func(t *testing.T) {
+			if got := workspaceAbbreviation(tt.workspace); got != tt.want {
+				t.Errorf("workspaceAbbreviation() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_handleCandidates(t *testing.T) {
+	want := []string{
+		"a-very-long-governor",
+		"a-very-long-govern-mw",
+		"a-very-long-governo-2",
+		"a-very-long-governo-3",
+		"a-very-long-governo-4",
+		"a-very-long-governo-5",
+		"a-very-long-governo-6",
+	}
+
+	if got := handleCandidates("a-very-long-governor", "my-workspace"); !reflect.DeepEqual(got, want) {
+		t.Errorf("handleCandidates() = %v, want %v", got, want)
+	}
+}
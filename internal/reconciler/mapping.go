@@ -0,0 +1,107 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/metal-toolbox/governor-api/pkg/api/v1alpha1"
+	slackgo "github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/slack"
+	"github.com/metal-toolbox/gov-slack-addon/internal/store"
+	"github.com/metal-toolbox/gov-slack-addon/internal/tracing"
+)
+
+// userGroup returns the slack user group managed for the governor group in the slack team. The
+// user group bound in the mapping is preferred, falling back to a lookup by name. User groups
+// found by name are recorded in the mapping.
+func (r *Reconciler) userGroup(ctx context.Context, group *v1alpha1.Group, appID, workspace, teamID string) (*UserGroup, error) {
+	logger := tracing.Logger(ctx, r.Logger).With(zap.String("governor.group.id", group.ID), zap.String("slack.workspace.id", teamID))
+
+	m, err := r.store.GetMapping(ctx, group.ID, teamID)
+
+	switch {
+	case err == nil && m.UserGroupID != "":
+		ug, err := r.userGroupFromID(ctx, m.UserGroupID, teamID, false)
+		if err == nil {
+			return ug, nil
+		}
+
+		if !errors.Is(err, ErrSlackUserGroupNotFound) {
+			return nil, err
+		}
+
+		logger.Warn("mapped slack user group not found, looking up by name", zap.String("slack.usergroup.id", m.UserGroupID))
+	case err != nil && !errors.Is(err, store.ErrNotFound):
+		logger.Warn("failed to get user group mapping, looking up by name", zap.Error(err))
+	}
+
+	ug, err := r.userGroupFromName(ctx, r.userGroupName(group, appID, workspace), teamID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if m == nil || m.UserGroupID != ug.ID {
+		r.putMapping(ctx, logger, group.ID, appID, teamID, ug.ID, ug.Name, ug.Handle)
+	}
+
+	return ug, nil
+}
+
+// putMapping records the slack user group bound to the governor group in the slack team. Failures
+// are only logged, since the user group can still be found by name.
+func (r *Reconciler) putMapping(ctx context.Context, logger *zap.Logger, groupID, appID, teamID, ugID, name, handle string) {
+	if r.dryrun {
+		return
+	}
+
+	if err := r.store.PutMapping(ctx, &store.UserGroupMapping{
+		GovernorGroupID: groupID,
+		GovernorAppID:   appID,
+		TeamID:          teamID,
+		UserGroupID:     ugID,
+		Name:            name,
+		Handle:          handle,
+		UpdatedAt:       time.Now().UTC(),
+	}); err != nil {
+		logger.Error("failed to record user group mapping", zap.String("slack.usergroup.id", ugID), zap.Error(err))
+	}
+}
+
+// createUserGroup creates the user group, falling back to the next handle candidate when the handle
+// is already taken by another user group or a slack user. ErrSlackGroupAlreadyExists is returned if
+// the name is taken.
+func (r *Reconciler) createUserGroup(ctx context.Context, logger *zap.Logger, teamID, workspace string, req *slack.UserGroupReq) (*slackgo.UserGroup, error) {
+	for i, handle := range handleCandidates(*req.Handle, workspace) {
+		req.Handle = &handle
+
+		ug, err := r.Client.CreateUserGroup(ctx, teamID, req)
+		if err == nil {
+			if i > 0 {
+				logger.Info("created user group with a fallback handle", zap.String("slack.usergroup.handle", handle))
+			}
+
+			return ug, nil
+		}
+
+		if !errors.Is(err, slack.ErrSlackGroupAlreadyExists) {
+			return nil, err
+		}
+
+		// slack reports name and handle conflicts the same way, there's no point in trying other
+		// handles if a user group (possibly disabled) already has the name
+		if i == 0 {
+			if _, err := r.userGroupFromName(ctx, *req.Name, teamID, true); err == nil {
+				return nil, slack.ErrSlackGroupAlreadyExists
+			} else if !errors.Is(err, ErrSlackUserGroupNotFound) {
+				return nil, err
+			}
+		}
+
+		logger.Info("slack user group handle is taken", zap.String("slack.usergroup.handle", handle))
+	}
+
+	return nil, ErrNoAvailableHandle
+}
@@ -0,0 +1,142 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/slack"
+	"github.com/metal-toolbox/gov-slack-addon/internal/store"
+	"github.com/metal-toolbox/gov-slack-addon/internal/tracing"
+)
+
+// Migration is the result of migrating a user group to the current naming scheme
+type Migration struct {
+	Workspace       string
+	TeamID          string
+	GovernorAppID   string
+	GovernorGroupID string
+	UserGroupID     string
+	OldName         string
+	NewName         string
+
+	// Renamed is true if the user group was renamed, or would be in dry-run mode
+	Renamed bool
+}
+
+// MigrateUserGroupName renames the user group of the governor group in the workspace of the given
+// application from the name it had with the old user group prefix, or the name recorded in the
+// mapping, to the current naming scheme. ErrSlackUserGroupNotFound is returned if no user group is
+// found under either name.
+func (r *Reconciler) MigrateUserGroupName(ctx context.Context, groupID, appID, oldPrefix string) (*Migration, error) {
+	if groupID == "" || appID == "" {
+		return nil, ErrBadParameter
+	}
+
+	isSlack, workspace, err := r.isSlackApplication(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isSlack {
+		return nil, nil
+	}
+
+	group, err := r.GovernorClient.Group(ctx, groupID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	teamID, err := r.userGroupTeamID(ctx, workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := tracing.Logger(ctx, r.Logger).With(
+		zap.String("slack.workspace.name", workspace),
+		zap.String("governor.app.id", appID),
+		zap.String("governor.group.id", group.ID),
+	)
+
+	m := &Migration{
+		Workspace:       workspace,
+		TeamID:          teamID,
+		GovernorAppID:   appID,
+		GovernorGroupID: group.ID,
+		NewName:         r.userGroupName(group, appID, workspace),
+	}
+
+	ug, err := r.migrationSource(ctx, group.ID, teamID, r.userGroupNameWithPrefix(group, appID, workspace, oldPrefix))
+	if err != nil {
+		if !errors.Is(err, ErrSlackUserGroupNotFound) {
+			return nil, err
+		}
+
+		// the user group may have been migrated already
+		if ug, err = r.userGroupFromName(ctx, m.NewName, teamID, false); err != nil {
+			return nil, err
+		}
+	}
+
+	m.UserGroupID = ug.ID
+	m.OldName = ug.Name
+
+	if ug.Name == m.NewName {
+		logger.Debug("user group already migrated", zap.String("slack.usergroup.id", ug.ID))
+
+		r.putMapping(ctx, logger, group.ID, appID, teamID, ug.ID, ug.Name, ug.Handle)
+
+		return m, nil
+	}
+
+	m.Renamed = true
+
+	target := map[string]string{
+		"slack.workspace.name":     workspace,
+		"slack.usergroup.id":       ug.ID,
+		"slack.usergroup.name":     m.NewName,
+		"slack.usergroup.old.name": ug.Name,
+		"governor.app.id":          appID,
+		"governor.group.id":        group.ID,
+		"governor.group.slug":      group.Slug,
+	}
+	req := slack.UserGroupReq{Name: &m.NewName}
+
+	if r.dryrun {
+		logger.Info("SKIP renaming slack user group", zap.String("slack.usergroup.id", ug.ID), zap.String("slack.usergroup.old.name", ug.Name), zap.String("slack.usergroup.name", m.NewName))
+		r.audit(ctx, logger, "UserGroupRename", target, userGroupDiff(ug, &req), nil)
+
+		return m, nil
+	}
+
+	if _, err := r.Client.UpdateUserGroup(ctx, ug.ID, teamID, req); err != nil {
+		logger.Error("failed to rename user group", zap.String("slack.usergroup.id", ug.ID), zap.Error(err))
+		r.audit(ctx, logger, "UserGroupRename", target, userGroupDiff(ug, &req), err)
+
+		return nil, err
+	}
+
+	r.putMapping(ctx, logger, group.ID, appID, teamID, ug.ID, m.NewName, ug.Handle)
+
+	logger.Info("renamed user group", zap.String("slack.usergroup.id", ug.ID), zap.String("slack.usergroup.old.name", ug.Name), zap.String("slack.usergroup.name", m.NewName))
+
+	r.audit(ctx, logger, "UserGroupRename", target, userGroupDiff(ug, &req), nil)
+
+	return m, nil
+}
+
+// migrationSource returns the user group to migrate, from the mapping or by its old name
+func (r *Reconciler) migrationSource(ctx context.Context, groupID, teamID, oldName string) (*UserGroup, error) {
+	m, err := r.store.GetMapping(ctx, groupID, teamID)
+	if err == nil && m.UserGroupID != "" {
+		ug, err := r.userGroupFromID(ctx, m.UserGroupID, teamID, false)
+		if err == nil || !errors.Is(err, ErrSlackUserGroupNotFound) {
+			return ug, err
+		}
+	} else if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return nil, err
+	}
+
+	return r.userGroupFromName(ctx, oldName, teamID, false)
+}
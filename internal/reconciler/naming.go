@@ -0,0 +1,239 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/metal-toolbox/governor-api/pkg/api/v1alpha1"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultUserGroupNameTemplate prepends the configured prefix to the governor group name
+	DefaultUserGroupNameTemplate = "{{ .Prefix }}{{ .Group.Name }}"
+	// DefaultUserGroupHandleTemplate uses the governor group slug as the handle
+	DefaultUserGroupHandleTemplate = "{{ .Group.Slug }}"
+	// DefaultUserGroupDescriptionTemplate uses the governor group description
+	DefaultUserGroupDescriptionTemplate = "{{ .Group.Description }}"
+)
+
+// UserGroupTemplates holds the text/template sources for the name, handle and description
+// of the slack user groups. Empty templates fall back to the defaults.
+type UserGroupTemplates struct {
+	Name        string
+	Handle      string
+	Description string
+}
+
+// UserGroupTemplateData is the data available to the user group templates
+type UserGroupTemplateData struct {
+	// Prefix is the configured user group prefix
+	Prefix string
+	// Group is the governor group
+	Group *v1alpha1.Group
+	// Workspace is the slack workspace name
+	Workspace string
+	// Application is the governor application linking the group to the workspace
+	Application UserGroupTemplateApplication
+}
+
+// UserGroupTemplateApplication has the governor application details available to the templates
+type UserGroupTemplateApplication struct {
+	ID   string
+	Name string
+}
+
+// userGroupTemplates holds the parsed user group templates
+type userGroupTemplates struct {
+	name        *template.Template
+	handle      *template.Template
+	description *template.Template
+}
+
+// UserGroupNamer renders the slack user group name, handle and description from templates,
+// which can be overridden per governor application
+type UserGroupNamer struct {
+	defaults  *userGroupTemplates
+	overrides map[string]*userGroupTemplates
+}
+
+// NewUserGroupNamer parses the default templates and the templates overrides, keyed by governor
+// application id or name. Empty templates fall back to the defaults.
+func NewUserGroupNamer(defaults UserGroupTemplates, overrides map[string]UserGroupTemplates) (*UserGroupNamer, error) {
+	base := UserGroupTemplates{
+		Name:        DefaultUserGroupNameTemplate,
+		Handle:      DefaultUserGroupHandleTemplate,
+		Description: DefaultUserGroupDescriptionTemplate,
+	}
+
+	d, err := parseUserGroupTemplates("default", mergeUserGroupTemplates(base, defaults))
+	if err != nil {
+		return nil, err
+	}
+
+	n := &UserGroupNamer{
+		defaults:  d,
+		overrides: make(map[string]*userGroupTemplates, len(overrides)),
+	}
+
+	for app, o := range overrides {
+		t, err := parseUserGroupTemplates(app, mergeUserGroupTemplates(mergeUserGroupTemplates(base, defaults), o))
+		if err != nil {
+			return nil, err
+		}
+
+		n.overrides[app] = t
+	}
+
+	return n, nil
+}
+
+// mustDefaultUserGroupNamer returns a namer with the default templates
+func mustDefaultUserGroupNamer() *UserGroupNamer {
+	n, err := NewUserGroupNamer(UserGroupTemplates{}, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	return n
+}
+
+// mergeUserGroupTemplates returns the base templates with the non-empty templates of t
+func mergeUserGroupTemplates(base, t UserGroupTemplates) UserGroupTemplates {
+	if t.Name != "" {
+		base.Name = t.Name
+	}
+
+	if t.Handle != "" {
+		base.Handle = t.Handle
+	}
+
+	if t.Description != "" {
+		base.Description = t.Description
+	}
+
+	return base
+}
+
+// parseUserGroupTemplates parses the templates and renders them once with sample data, so
+// references to unknown fields are caught at startup rather than during reconciliation
+func parseUserGroupTemplates(key string, t UserGroupTemplates) (*userGroupTemplates, error) {
+	var (
+		out = &userGroupTemplates{}
+		err error
+	)
+
+	sample := UserGroupTemplateData{Group: &v1alpha1.Group{}}
+
+	for name, tpl := range map[string]struct {
+		src  string
+		dest **template.Template
+	}{
+		"name":        {t.Name, &out.name},
+		"handle":      {t.Handle, &out.handle},
+		"description": {t.Description, &out.description},
+	} {
+		*tpl.dest, err = template.New(key + "-" + name).Option("missingkey=error").Parse(tpl.src)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s %s: %w", ErrInvalidUserGroupTemplate, key, name, err)
+		}
+
+		if _, err := render(*tpl.dest, sample); err != nil {
+			return nil, fmt.Errorf("%w: %s %s: %w", ErrInvalidUserGroupTemplate, key, name, err)
+		}
+	}
+
+	return out, nil
+}
+
+// templates returns the templates for the governor application, matched by id then name
+func (n *UserGroupNamer) templates(appID, appName string) *userGroupTemplates {
+	if t, ok := n.overrides[appID]; ok {
+		return t
+	}
+
+	if t, ok := n.overrides[appName]; ok {
+		return t
+	}
+
+	return n.defaults
+}
+
+func pickName(t *userGroupTemplates) *template.Template        { return t.name }
+func pickHandle(t *userGroupTemplates) *template.Template      { return t.handle }
+func pickDescription(t *userGroupTemplates) *template.Template { return t.description }
+
+// render executes the template and trims the surrounding whitespace
+func render(t *template.Template, data UserGroupTemplateData) (string, error) {
+	var b strings.Builder
+
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(b.String()), nil
+}
+
+// userGroupTemplateData returns the template data for the governor group in the workspace of the application
+func (r *Reconciler) userGroupTemplateData(group *v1alpha1.Group, appID, workspace string) UserGroupTemplateData {
+	return UserGroupTemplateData{
+		Prefix:      r.userGroupPrefix,
+		Group:       group,
+		Workspace:   workspace,
+		Application: UserGroupTemplateApplication{ID: appID, Name: workspace},
+	}
+}
+
+// renderUserGroup renders one of the user group templates, falling back to the default
+// template if the application template fails
+func (r *Reconciler) renderUserGroup(field string, pick func(*userGroupTemplates) *template.Template, data UserGroupTemplateData) string {
+	out, err := render(pick(r.namer.templates(data.Application.ID, data.Workspace)), data)
+	if err == nil {
+		return out
+	}
+
+	r.Logger.Warn("failed to render user group template, using the default",
+		zap.String("template", field),
+		zap.String("governor.group.id", data.Group.ID),
+		zap.String("governor.app.id", data.Application.ID),
+		zap.Error(err),
+	)
+
+	out, err = render(pick(r.namer.defaults), data)
+	if err != nil {
+		r.Logger.Error("failed to render default user group template", zap.String("template", field), zap.Error(err))
+	}
+
+	return out
+}
+
+// userGroupName renders the slack user group name for the governor group
+func (r *Reconciler) userGroupName(group *v1alpha1.Group, appID, workspace string) string {
+	return r.renderUserGroup("name", pickName, r.userGroupTemplateData(group, appID, workspace))
+}
+
+// userGroupNameWithPrefix renders the slack user group name for the governor group with the given
+// user group prefix instead of the configured one
+func (r *Reconciler) userGroupNameWithPrefix(group *v1alpha1.Group, appID, workspace, prefix string) string {
+	data := r.userGroupTemplateData(group, appID, workspace)
+	data.Prefix = prefix
+
+	return r.renderUserGroup("name", pickName, data)
+}
+
+// userGroupHandle renders the slack user group handle for the governor group, sanitized to slack's rules.
+// The handle set in the group settings takes precedence over the template.
+func (r *Reconciler) userGroupHandle(ctx context.Context, group *v1alpha1.Group, appID, workspace string) string {
+	if s := r.groupSettings(ctx, group.ID); s != nil && s.Handle != "" {
+		return sanitizeHandle(s.Handle)
+	}
+
+	return sanitizeHandle(r.renderUserGroup("handle", pickHandle, r.userGroupTemplateData(group, appID, workspace)))
+}
+
+// userGroupDescription renders the slack user group description for the governor group
+func (r *Reconciler) userGroupDescription(group *v1alpha1.Group, appID, workspace string) string {
+	return r.renderUserGroup("description", pickDescription, r.userGroupTemplateData(group, appID, workspace))
+}
@@ -0,0 +1,146 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/metal-toolbox/governor-api/pkg/api/v1alpha1"
+	"go.uber.org/zap"
+)
+
+func TestNewUserGroupNamer(t *testing.T) {
+	tests := []struct {
+		name      string
+		defaults  UserGroupTemplates
+		overrides map[string]UserGroupTemplates
+		wantErr   bool
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name:     "valid templates",
+			defaults: UserGroupTemplates{Name: "eng-{{ .Group.Slug }}"},
+			overrides: map[string]UserGroupTemplates{
+				"workspace-a": {Description: "{{ .Group.Description }} ({{ .Workspace }})"},
+			},
+		},
+		{
+			name:     "parse error",
+			defaults: UserGroupTemplates{Name: "{{ .Group.Slug"},
+			wantErr:  true,
+		},
+		{
+			name: "unknown field",
+			overrides: map[string]UserGroupTemplates{
+				"workspace-a": {Handle: "{{ .Group.Nope }}"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewUserGroupNamer(tt.defaults, tt.overrides)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewUserGroupNamer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil && !errors.Is(err, ErrInvalidUserGroupTemplate) {
+				t.Errorf("expected ErrInvalidUserGroupTemplate, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReconciler_userGroupTemplates(t *testing.T) {
+	namer, err := NewUserGroupNamer(
+		UserGroupTemplates{
+			Description: "{{ .Group.Description }} - https://governor.example.com/groups/{{ .Group.ID }}",
+		},
+		map[string]UserGroupTemplates{
+			"app-b":       {Name: "eng-{{ .Group.Slug }}"},
+			"workspace-c": {Handle: "{{ .Group.Slug }}-{{ .Workspace }}"},
+		},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	r := &Reconciler{
+		Logger:          zap.NewNop(),
+		userGroupPrefix: "[Governor] ",
+		namer:           namer,
+	}
+
+	group := &v1alpha1.Group{ID: "group-id", Name: "Platform", Slug: "platform", Description: "The platform team"}
+
+	tests := []struct {
+		name            string
+		appID           string
+		workspace       string
+		wantName        string
+		wantHandle      string
+		wantDescription string
+	}{
+		{
+			name:            "defaults",
+			appID:           "app-a",
+			workspace:       "workspace-a",
+			wantName:        "[Governor] Platform",
+			wantHandle:      "platform",
+			wantDescription: "The platform team - https://governor.example.com/groups/group-id",
+		},
+		{
+			name:            "application id override",
+			appID:           "app-b",
+			workspace:       "workspace-b",
+			wantName:        "eng-platform",
+			wantHandle:      "platform",
+			wantDescription: "The platform team - https://governor.example.com/groups/group-id",
+		},
+		{
+			name:            "application name override",
+			appID:           "app-c",
+			workspace:       "workspace-c",
+			wantName:        "[Governor] Platform",
+			wantHandle:      "platform-workspace-c",
+			wantDescription: "The platform team - https://governor.example.com/groups/group-id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.userGroupName(group, tt.appID, tt.workspace); got != tt.wantName {
+				t.Errorf("userGroupName() = %q, want %q", got, tt.wantName)
+			}
+
+			if got := r.userGroupHandle(context.Background(), group, tt.appID, tt.workspace); got != tt.wantHandle {
+				t.Errorf("userGroupHandle() = %q, want %q", got, tt.wantHandle)
+			}
+
+			if got := r.userGroupDescription(group, tt.appID, tt.workspace); got != tt.wantDescription {
+				t.Errorf("userGroupDescription() = %q, want %q", got, tt.wantDescription)
+			}
+		})
+	}
+}
+
+func TestReconciler_userGroupNameWithPrefix(t *testing.T) {
+	r := &Reconciler{
+		Logger:          zap.NewNop(),
+		userGroupPrefix: "[Governor] ",
+		namer:           mustDefaultUserGroupNamer(),
+	}
+
+	group := &v1alpha1.Group{ID: "group-id", Name: "Platform", Slug: "platform"}
+
+	if got := r.userGroupNameWithPrefix(group, "app-a", "workspace-a", "[Gov] "); got != "[Gov] Platform" {
+		t.Errorf("userGroupNameWithPrefix() = %q, want %q", got, "[Gov] Platform")
+	}
+
+	if got := r.userGroupName(group, "app-a", "workspace-a"); got != "[Governor] Platform" {
+		t.Errorf("userGroupName() = %q, want %q", got, "[Governor] Platform")
+	}
+}
@@ -0,0 +1,55 @@
+package reconciler
+
+import (
+	"context"
+
+	"github.com/metal-toolbox/governor-api/pkg/api/v1alpha1"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/notify"
+)
+
+// notify queues a notification in the batch of the reconciliation pass, or sends it right away
+// outside of the reconciler loop, e.g. when handling a governor event
+func (r *Reconciler) notify(ctx context.Context, n notify.Notification) {
+	if r.notifier == nil || !r.groupSettings(ctx, n.GovernorGroupID).notificationEnabled(n.Kind) {
+		return
+	}
+
+	if b := notify.GetBatch(ctx); b != nil {
+		b.Add(n)
+		return
+	}
+
+	r.notifier.Send(ctx, notify.NewBatch(n))
+}
+
+// notifyMembers notifies each of the slack users of the same membership change
+func (r *Reconciler) notifyMembers(ctx context.Context, kind notify.Kind, userIDs []string, workspace string, ug *UserGroup, group *v1alpha1.Group) {
+	for _, id := range userIDs {
+		r.notify(ctx, membershipNotification(kind, id, workspace, ug, group))
+	}
+}
+
+// sendNotifications sends the notifications batched during a reconciliation pass
+func (r *Reconciler) sendNotifications(ctx context.Context, b *notify.Batch) {
+	if r.notifier == nil || b.Len() == 0 {
+		return
+	}
+
+	r.notifier.Send(ctx, b)
+}
+
+// membershipNotification returns the notification of a membership change of the slack user in the user group
+func membershipNotification(kind notify.Kind, userID, workspace string, ug *UserGroup, group *v1alpha1.Group) notify.Notification {
+	return notify.Notification{
+		Kind:              kind,
+		SlackUserID:       userID,
+		Workspace:         workspace,
+		UserGroupID:       ug.ID,
+		UserGroupName:     ug.Name,
+		UserGroupHandle:   ug.Handle,
+		GovernorGroupID:   group.ID,
+		GovernorGroupSlug: group.Slug,
+		GovernorGroupName: group.Name,
+	}
+}
@@ -0,0 +1,149 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/ops"
+	"github.com/metal-toolbox/gov-slack-addon/internal/slack"
+)
+
+// passReport collects the groups reconciled and the failures of a reconciliation pass, by workspace.
+// The groups are reconciled concurrently, so it's safe for concurrent use.
+type passReport struct {
+	mu        sync.Mutex
+	summaries map[string]*ops.Summary
+}
+
+func newPassReport() *passReport {
+	return &passReport{summaries: make(map[string]*ops.Summary)}
+}
+
+// summary returns the summary of the workspace, it must be called with the lock held
+func (p *passReport) summary(workspace string) *ops.Summary {
+	s, ok := p.summaries[workspace]
+	if !ok {
+		s = &ops.Summary{Workspace: workspace}
+		p.summaries[workspace] = s
+	}
+
+	return s
+}
+
+// reconciled counts a group reconciled in the workspace
+func (p *passReport) reconciled(workspace string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.summary(workspace).Groups++
+}
+
+// skipped counts a group that wasn't reconciled in the workspace before the pass ended
+func (p *passReport) skipped(workspace string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.summary(workspace).Skipped++
+}
+
+// unchanged counts a group that hadn't changed since it was last reconciled in the workspace
+func (p *passReport) unchanged(workspace string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.summary(workspace).Unchanged++
+}
+
+// failed records a failed operation in the workspace
+func (p *passReport) failed(workspace string, f ops.Failure) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s := p.summary(workspace)
+	s.Failures = append(s.Failures, f)
+}
+
+// passTotals are the numbers of unchanged and skipped groups and of failed operations of a pass
+type passTotals struct {
+	unchanged int
+	skipped   int
+	failures  int
+}
+
+// totals returns the numbers of unchanged and skipped groups and of failed operations in all the workspaces
+func (p *passReport) totals() passTotals {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	t := passTotals{}
+
+	for _, s := range p.summaries {
+		t.unchanged += s.Unchanged
+		t.skipped += s.Skipped
+		t.failures += len(s.Failures)
+	}
+
+	return t
+}
+
+// list returns the summaries sorted by workspace
+func (p *passReport) list() []ops.Summary {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	out := make([]ops.Summary, 0, len(p.summaries))
+	for _, s := range p.summaries {
+		out = append(out, *s)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Workspace < out[j].Workspace })
+
+	return out
+}
+
+// passFailure records a failed operation of the reconciliation pass, and alerts right away if it's a
+// hard failure
+func (r *Reconciler) passFailure(ctx context.Context, p *passReport, workspace, operation, group string, err error) {
+	p.failed(workspace, ops.Failure{Operation: operation, Group: group, Error: err.Error()})
+
+	r.alertOnError(ctx, workspace, err)
+}
+
+// reportPass posts the summaries of the reconciliation pass to the ops channels
+func (r *Reconciler) reportPass(ctx context.Context, p *passReport) {
+	if !r.ops.Enabled() {
+		return
+	}
+
+	for _, s := range p.list() {
+		r.ops.Summary(ctx, s)
+	}
+}
+
+// alertOnError posts an alert to the ops channel if the error is a hard failure that needs attention,
+// such as slack rejecting the token or a missing workspace. The reconciler has no safety guard
+// refusing large membership changes, so there's no guard trip to alert on here.
+func (r *Reconciler) alertOnError(ctx context.Context, workspace string, err error) {
+	if !r.ops.Enabled() {
+		return
+	}
+
+	switch {
+	case slack.IsAuthError(err):
+		// the token is shared by all the workspaces, so it's alerted once
+		r.ops.Alert(ctx, ops.Alert{Kind: ops.AlertAuth})
+	case errors.Is(err, ErrSlackWorkspaceNotFound):
+		r.ops.Alert(ctx, ops.Alert{Kind: ops.AlertWorkspaceNotFound, Workspace: workspace, Message: err.Error()})
+	}
+}
+
+// AlertEventFailure posts an alert to the ops channel for a governor event that couldn't be processed
+func (r *Reconciler) AlertEventFailure(ctx context.Context, subject string, err error) {
+	if !r.ops.Enabled() {
+		return
+	}
+
+	r.ops.Alert(ctx, ops.Alert{Kind: ops.AlertEventFailed, Message: subject + ": " + err.Error()})
+}
@@ -0,0 +1,40 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func Test_passReport(t *testing.T) {
+	r := New()
+	p := newPassReport()
+
+	p.reconciled("ws-b")
+	p.reconciled("ws-a")
+	p.reconciled("ws-a")
+
+	p.skipped("ws-b")
+
+	r.passFailure(context.Background(), p, "ws-a", "update members", "team-a", errors.New("boom")) //nolint:err113
+
+	p.unchanged("ws-a")
+
+	if got := p.totals(); got != (passTotals{unchanged: 1, skipped: 1, failures: 1}) {
+		t.Errorf("expected 1 unchanged group, 1 skipped group and 1 failure, got %+v", got)
+	}
+
+	got := p.list()
+
+	if len(got) != 2 || got[0].Workspace != "ws-a" || got[1].Workspace != "ws-b" {
+		t.Fatalf("expected summaries sorted by workspace, got %+v", got)
+	}
+
+	if got[0].Groups != 2 || got[0].Unchanged != 1 || len(got[0].Failures) != 1 || got[0].Failures[0].Group != "team-a" {
+		t.Errorf("unexpected ws-a summary %+v", got[0])
+	}
+
+	if got[1].Groups != 1 || got[1].Skipped != 1 || len(got[1].Failures) != 0 {
+		t.Errorf("unexpected ws-b summary %+v", got[1])
+	}
+}
@@ -0,0 +1,142 @@
+package reconciler
+
+import (
+	"context"
+
+	"github.com/metal-toolbox/governor-api/pkg/api/v1alpha1"
+	"go.uber.org/zap"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/tracing"
+)
+
+// orgWide returns true if the user groups are managed at the enterprise grid organization level
+func (r *Reconciler) orgWide() bool {
+	return r.orgID != ""
+}
+
+// userGroupTeamID returns the id of the team the user groups of the workspace are managed in,
+// which is the organization in org-wide mode and the workspace itself otherwise
+func (r *Reconciler) userGroupTeamID(ctx context.Context, workspace string) (string, error) {
+	if r.orgWide() {
+		return r.orgID, nil
+	}
+
+	return r.teamIDFromName(ctx, workspace)
+}
+
+// AttachUserGroup makes the organization wide user group of the governor group available in the
+// workspace of the given slack application. It's a no-op if org-wide mode isn't enabled.
+func (r *Reconciler) AttachUserGroup(ctx context.Context, groupID, appID string) error {
+	if groupID == "" || appID == "" {
+		return ErrBadParameter
+	}
+
+	ctx, unlock := r.lockGroup(ctx, groupID)
+	defer unlock()
+
+	if !r.orgWide() {
+		return nil
+	}
+
+	isSlack, workspace, err := r.isSlackApplication(ctx, appID)
+	if err != nil {
+		r.Logger.Error("failed to get application from governor", zap.String("governor.app.id", appID), zap.Error(err))
+		return err
+	}
+
+	if !isSlack {
+		r.Logger.Debug("not a slack application, skipping", zap.String("governor.app.id", appID), zap.String("governor.app.name", workspace))
+		return nil
+	}
+
+	if !r.syncsToWorkspace(ctx, groupID, workspace) {
+		r.Logger.Debug("workspace not in the group settings, skipping", zap.String("governor.group.id", groupID), zap.String("slack.workspace.name", workspace))
+		return nil
+	}
+
+	logger := tracing.Logger(ctx, r.Logger).With(zap.String("slack.workspace.name", workspace), zap.String("governor.app.id", appID))
+
+	group, err := r.GovernorClient.Group(ctx, groupID, false)
+	if err != nil {
+		logger.Error("error getting governor group", zap.String("governor.group.id", groupID), zap.Error(err))
+		return err
+	}
+
+	teamID, err := r.teamIDFromName(ctx, workspace)
+	if err != nil {
+		return err
+	}
+
+	ug, err := r.userGroup(ctx, group, appID, workspace, r.orgID)
+	if err != nil {
+		return err
+	}
+
+	// org-wide user groups are listed in every workspace they are attached to
+	usergroups, err := r.Client.GetUserGroups(ctx, teamID, false)
+	if err != nil {
+		return err
+	}
+
+	for _, wug := range usergroups {
+		if wug.ID == ug.ID {
+			logger.Debug("user group already attached to workspace", zap.String("slack.usergroup.id", ug.ID))
+			return nil
+		}
+	}
+
+	target := map[string]string{
+		"slack.workspace.name": workspace,
+		"slack.workspace.id":   teamID,
+		"slack.org.id":         r.orgID,
+		"slack.usergroup.name": ug.Name,
+		"slack.usergroup.id":   ug.ID,
+		"governor.app.id":      appID,
+		"governor.group.id":    group.ID,
+		"governor.group.slug":  group.Slug,
+	}
+	diff := map[string]any{"workspaces": &listDiff{Old: []string{}, New: []string{teamID}, Added: []string{teamID}, Removed: []string{}}}
+
+	if r.dryrun {
+		logger.Info("SKIP attaching slack user group to workspace", zap.String("slack.usergroup.id", ug.ID), zap.String("slack.workspace.id", teamID))
+		r.audit(ctx, logger, "UserGroupAttachWorkspace", target, diff, nil)
+
+		return nil
+	}
+
+	if err := r.Client.AddUserGroupTeams(ctx, ug.ID, []string{teamID}); err != nil {
+		logger.Error("failed to attach user group to workspace", zap.String("slack.usergroup.id", ug.ID), zap.Error(err))
+		r.audit(ctx, logger, "UserGroupAttachWorkspace", target, diff, err)
+
+		return err
+	}
+
+	logger.Info("attached user group to workspace", zap.String("slack.usergroup.id", ug.ID), zap.String("slack.workspace.id", teamID))
+
+	r.audit(ctx, logger, "UserGroupAttachWorkspace", target, diff, nil)
+
+	return nil
+}
+
+// otherSlackApplications returns the names of the slack applications linked to the governor group,
+// other than the given application
+func (r *Reconciler) otherSlackApplications(ctx context.Context, group *v1alpha1.Group, appID string) ([]string, error) {
+	names := []string{}
+
+	for _, id := range group.Applications {
+		if id == appID {
+			continue
+		}
+
+		isSlack, workspace, err := r.isSlackApplication(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if isSlack {
+			names = append(names, workspace)
+		}
+	}
+
+	return names, nil
+}
@@ -0,0 +1,27 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReconciler_userGroupTeamID(t *testing.T) {
+	r := &Reconciler{orgID: "E0001"}
+
+	got, err := r.userGroupTeamID(context.TODO(), "workspace-a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got != "E0001" {
+		t.Errorf("userGroupTeamID() = %s, want %s", got, "E0001")
+	}
+
+	if !r.orgWide() {
+		t.Error("expected org-wide mode to be enabled")
+	}
+
+	if (&Reconciler{}).orgWide() {
+		t.Error("expected org-wide mode to be disabled")
+	}
+}
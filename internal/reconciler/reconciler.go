@@ -3,18 +3,29 @@ package reconciler
 import (
 	"context"
 	"errors"
+	"sync"
 	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/metal-toolbox/auditevent"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 	"go.uber.org/zap"
 
 	"github.com/metal-toolbox/governor-api/pkg/api/v1alpha1"
 	governor "github.com/metal-toolbox/governor-api/pkg/client"
 
 	"github.com/metal-toolbox/gov-slack-addon/internal/auctx"
+	"github.com/metal-toolbox/gov-slack-addon/internal/govapi"
+	"github.com/metal-toolbox/gov-slack-addon/internal/identity"
 	"github.com/metal-toolbox/gov-slack-addon/internal/natslock"
+	"github.com/metal-toolbox/gov-slack-addon/internal/notify"
+	"github.com/metal-toolbox/gov-slack-addon/internal/ops"
 	"github.com/metal-toolbox/gov-slack-addon/internal/slack"
+	"github.com/metal-toolbox/gov-slack-addon/internal/store"
+	"github.com/metal-toolbox/gov-slack-addon/internal/tracing"
 )
 
 type govClientIface interface {
@@ -25,6 +36,11 @@ type govClientIface interface {
 	Group(context.Context, string, bool) (*v1alpha1.Group, error)
 	GroupMembers(ctx context.Context, id string) ([]*v1alpha1.GroupMember, error)
 	User(context.Context, string, bool) (*v1alpha1.User, error)
+	UserByEmail(ctx context.Context, email string) (*v1alpha1.User, error)
+	CreateGroupMembershipRequest(ctx context.Context, groupID, userID, note string) (*govapi.MembershipRequest, error)
+	GroupMembershipRequests(ctx context.Context, groupID string) ([]*govapi.MembershipRequest, error)
+	GroupMembershipRequest(ctx context.Context, groupID, requestID string) (*govapi.MembershipRequest, error)
+	DeleteGroupMembershipRequest(ctx context.Context, groupID, requestID string) error
 	URL() string
 }
 
@@ -36,12 +52,51 @@ type Reconciler struct {
 	Locker         *natslock.Locker
 	Logger         *zap.Logger
 
-	auditEventWriter *auditevent.EventWriter
-	dryrun           bool
-	interval         time.Duration
-	queue            string
-	userGroupPrefix  string
-	applicationType  string
+	auditEventWriter    *auditevent.EventWriter
+	tracer              trace.Tracer
+	dryrun              bool
+	interval            time.Duration
+	passTimeout         time.Duration
+	deepInterval        time.Duration
+	lastDeepPass        time.Time
+	fingerprints        *fingerprints
+	workerCount         int
+	workspaceWorkers    int
+	workers             *workerPool
+	groupLocks          *groupLocks
+	queue               string
+	userGroupPrefix     string
+	applicationType     string
+	identity            identity.Resolver
+	channelLinks        []ChannelLink
+	channelKick         bool
+	defaultChannelRules []DefaultChannels
+	orgID               string
+	namer               *UserGroupNamer
+	store               store.Store
+	adoptRules          []AdoptRule
+	exclusionRules      []ExclusionRule
+	retention           time.Duration
+	retirementsScanned  bool
+	drift               *driftQueue
+	governorUIURL       string
+	membershipRequests  bool
+	notifier            *notify.Notifier
+	ops                 *ops.Reporter
+	extensions          extensionResources
+	extensionSlug       string
+	settings            *groupSettingsCache
+	statuses            *syncStatuses
+	emails              *userEmails
+	slackUsers          *slackUserCache
+
+	selfMu sync.Mutex
+	selfID string
+
+	unmatched               *unmatchedReports
+	unmatchedReportChannel  string
+	unmatchedReportInterval time.Duration
+	unmatchedReportPostedAt time.Time
 }
 
 // Option is a functional configuration option
@@ -61,6 +116,13 @@ func WithAuditEventWriter(a *auditevent.EventWriter) Option {
 	}
 }
 
+// WithTracer sets the tracer used to make a span for each reconciliation pass and group
+func WithTracer(t trace.Tracer) Option {
+	return func(r *Reconciler) {
+		r.tracer = t
+	}
+}
+
 // WithClient sets slack client
 func WithClient(c *slack.Client) Option {
 	return func(r *Reconciler) {
@@ -68,10 +130,11 @@ func WithClient(c *slack.Client) Option {
 	}
 }
 
-// WithGovernorClient sets governor api client
-func WithGovernorClient(c *governor.Client) Option {
+// WithGovernorClient sets governor api client, api is used for the calls the governor-api client
+// doesn't provide and may be nil if membership requests are disabled
+func WithGovernorClient(c *governor.Client, api *govapi.Client) Option {
 	return func(r *Reconciler) {
-		r.GovernorClient = c
+		r.GovernorClient = &governorClient{Client: c, api: api}
 	}
 }
 
@@ -89,6 +152,31 @@ func WithDryRun(d bool) Option {
 	}
 }
 
+// WithWorkers sets the number of groups reconciled concurrently, globally and in each workspace
+func WithWorkers(global, perWorkspace int) Option {
+	return func(r *Reconciler) {
+		r.workerCount = global
+		r.workspaceWorkers = perWorkspace
+	}
+}
+
+// WithPassTimeout sets the deadline of the group reconciliation of a pass, the groups not reconciled
+// by then are skipped until the next pass. There's no deadline if zero.
+func WithPassTimeout(t time.Duration) Option {
+	return func(r *Reconciler) {
+		r.passTimeout = t
+	}
+}
+
+// WithDeepInterval sets the interval between deep passes, which reconcile all the groups. The passes in
+// between skip the groups that haven't changed since they were last reconciled. Every pass is deep if
+// zero.
+func WithDeepInterval(i time.Duration) Option {
+	return func(r *Reconciler) {
+		r.deepInterval = i
+	}
+}
+
 // WithQueue sets nats queue for events
 func WithQueue(q string) Option {
 	return func(r *Reconciler) {
@@ -117,16 +205,166 @@ func WithApplicationType(t string) Option {
 	}
 }
 
+// WithIdentityResolver sets the resolver used to match governor users to slack users
+func WithIdentityResolver(i identity.Resolver) Option {
+	return func(r *Reconciler) {
+		r.identity = i
+	}
+}
+
+// WithChannelLinks sets the links between governor groups and slack channels
+func WithChannelLinks(l []ChannelLink) Option {
+	return func(r *Reconciler) {
+		r.channelLinks = l
+	}
+}
+
+// WithChannelKick enables removing channel members that aren't in the linked governor group
+func WithChannelKick(k bool) Option {
+	return func(r *Reconciler) {
+		r.channelKick = k
+	}
+}
+
+// WithDefaultChannels sets the default channels rules for the slack user groups
+func WithDefaultChannels(d []DefaultChannels) Option {
+	return func(r *Reconciler) {
+		r.defaultChannelRules = d
+	}
+}
+
+// WithUserGroupNamer sets the templates used to name the slack user groups
+func WithUserGroupNamer(n *UserGroupNamer) Option {
+	return func(r *Reconciler) {
+		r.namer = n
+	}
+}
+
+// WithStore sets the store for the addon state, such as the user group mappings
+func WithStore(s store.Store) Option {
+	return func(r *Reconciler) {
+		r.store = s
+	}
+}
+
+// WithAdoptRules sets the rules binding existing slack user groups to governor groups
+func WithAdoptRules(a []AdoptRule) Option {
+	return func(r *Reconciler) {
+		r.adoptRules = a
+	}
+}
+
+// WithExclusionRules sets the rules protecting slack members of the user groups and excluding
+// governor users from being synced
+func WithExclusionRules(e []ExclusionRule) Option {
+	return func(r *Reconciler) {
+		r.exclusionRules = e
+	}
+}
+
+// WithRetention sets how long retired user groups are kept before they're purged, they're never
+// purged if it's zero
+func WithRetention(d time.Duration) Option {
+	return func(r *Reconciler) {
+		r.retention = d
+	}
+}
+
+// WithGovernorUIURL sets the governor ui url, used to link to the governor groups
+func WithGovernorUIURL(u string) Option {
+	return func(r *Reconciler) {
+		r.governorUIURL = u
+	}
+}
+
+// WithMembershipRequests enables filing governor membership requests from slack
+func WithMembershipRequests(enabled bool) Option {
+	return func(r *Reconciler) {
+		r.membershipRequests = enabled
+	}
+}
+
+// WithNotifier sets the notifier used to tell users about their user group membership changes,
+// users aren't notified if it's nil
+func WithNotifier(n *notify.Notifier) Option {
+	return func(r *Reconciler) {
+		r.notifier = n
+	}
+}
+
+// WithOpsReporter sets the reporter posting reconciliation summaries and alerts to the ops channels
+func WithOpsReporter(o *ops.Reporter) Option {
+	return func(r *Reconciler) {
+		r.ops = o
+	}
+}
+
+// WithExtension sets the client and slug of the addon's governor extension, used to read the group
+// settings resources and write the sync status resources
+func WithExtension(c *govapi.Client, slug string) Option {
+	return func(r *Reconciler) {
+		if c != nil && slug != "" {
+			r.extensions = c
+			r.extensionSlug = slug
+		}
+	}
+}
+
+// WithOrgID enables org-wide mode on enterprise grid, where a single user group is created in the
+// given organization for each governor group and attached to all of its linked workspaces
+func WithOrgID(id string) Option {
+	return func(r *Reconciler) {
+		r.orgID = id
+	}
+}
+
+// WithUnmatchedReportChannel sets the slack channel id where the unmatched members report is posted
+func WithUnmatchedReportChannel(c string) Option {
+	return func(r *Reconciler) {
+		r.unmatchedReportChannel = c
+	}
+}
+
+// WithUnmatchedReportInterval sets how often the unmatched members report is posted
+func WithUnmatchedReportInterval(i time.Duration) Option {
+	return func(r *Reconciler) {
+		r.unmatchedReportInterval = i
+	}
+}
+
 // New returns a new reconciler
 func New(opts ...Option) *Reconciler {
 	rec := Reconciler{
-		Logger: zap.NewNop(),
+		Logger:       zap.NewNop(),
+		tracer:       noop.NewTracerProvider().Tracer("reconciler"),
+		fingerprints: newFingerprints(),
+		unmatched:    newUnmatchedReports(),
+		drift:        newDriftQueue(),
+		settings:     newGroupSettingsCache(),
+		statuses:     newSyncStatuses(),
+		emails:       newUserEmails(),
+		slackUsers:   newSlackUserCache(),
 	}
 
 	for _, opt := range opts {
 		opt(&rec)
 	}
 
+	rec.workers = newWorkerPool(rec.workerCount, rec.workspaceWorkers)
+	rec.groupLocks = newGroupLocks()
+
+	if rec.identity == nil {
+		rec.identity = identity.NewEmailResolver(rec.Client)
+	}
+
+	if rec.namer == nil {
+		rec.namer = mustDefaultUserGroupNamer()
+	}
+
+	if rec.store == nil {
+		rec.store = store.NewMemory()
+	}
+
 	var err error
 
 	rec.ID, err = uuid.DefaultGenerator.NewV4()
@@ -165,114 +403,352 @@ func (r *Reconciler) Run(ctx context.Context) {
 		ws, err := r.Client.ListWorkspaces(ctx)
 		if err != nil {
 			r.Logger.Error(err.Error())
+			r.alertOnError(ctx, "", err)
 			panic(err)
 		}
 
 		r.Logger.Info("slack token has access to the following workspaces", zap.Any("workspaces", ws))
 	}
 
+	// user groups changed by hand are reconciled as soon as slack reports the change, slack only
+	// sends the event to one of the instances so the others record the change and leave the revert
+	// to the leader
+	go r.processDrift(ctx)
+
 	for {
 		select {
 		case <-ticker.C:
-			if r.Locker != nil {
-				isLead, err := r.Locker.AcquireLead(r.ID)
-				if err != nil {
-					r.Logger.Error("error checking for leader lock", zap.Error(err))
-					continue
-				}
+			isLead, err := r.isLeader()
+			if err != nil {
+				r.Logger.Error("error checking for leader lock", zap.Error(err))
+				continue
+			}
 
-				if !isLead {
-					r.Logger.Debug("not leader, skipping loop")
-					continue
-				}
+			if !isLead {
+				r.Logger.Debug("not leader, skipping loop")
+				continue
 			}
 
-			r.Logger.Info("executing reconciler loop",
+			r.reconcilePass(ctx)
+
+		case <-ctx.Done():
+			r.Logger.Info("shutting down reconciler",
 				zap.String("time", time.Now().UTC().Format(time.RFC3339)),
 			)
 
-			ctx = auctx.WithAuditEvent(ctx, auditevent.NewAuditEvent(
-				"", // eventType to be populated later
-				auditevent.EventSource{
-					Type:  "local",
-					Value: "ReconcileLoop",
-					Extra: map[string]interface{}{
-						"governor.url": r.GovernorClient.URL(),
-					},
-				},
-				auditevent.OutcomeSucceeded,
-				map[string]string{
-					"event": "reconciler",
-				},
-				"gov-slack-addon",
-			))
-
-			apps, err := r.GovernorClient.Applications(ctx)
-			if err != nil {
-				r.Logger.Error("error listing governor applications", zap.Error(err))
-				continue
-			}
+			return
+		}
+	}
+}
 
-			r.Logger.Debug("got applications", zap.Any("applications list", apps))
+// isLeader returns true if this instance holds the leader lock, or if locking is disabled
+func (r *Reconciler) isLeader() (bool, error) {
+	if r.Locker == nil {
+		return true, nil
+	}
 
-			appTypes, err := r.GovernorClient.ApplicationTypes(ctx)
-			if err != nil {
-				r.Logger.Error("error listing governor application types")
-				continue
-			}
+	return r.Locker.AcquireLead(r.ID)
+}
 
-			var desiredAppTypeID string
+// reconcilePass runs a single reconciliation pass of all the groups linked to slack applications
+func (r *Reconciler) reconcilePass(ctx context.Context) {
+	// the pass event is the parent of the audit events of the operations made during the pass, the
+	// contexts are scoped to the pass so they don't nest across passes
+	passEvent := auditevent.NewAuditEvent(
+		"", // the type of each operation is set when its event is written
+		auditevent.EventSource{
+			Type:  "local",
+			Value: "ReconcileLoop",
+			Extra: map[string]interface{}{
+				"governor.url": r.GovernorClient.URL(),
+			},
+		},
+		auditevent.OutcomeSucceeded, // the outcome of each operation is set when its event is written
+		map[string]string{
+			"event": "reconciler",
+		},
+		"gov-slack-addon",
+	)
+	ctx = auctx.WithAuditEvent(ctx, passEvent)
+
+	// deep passes reconcile all the groups, the others only the groups that changed
+	start := time.Now()
+	deep := r.deepInterval <= 0 || start.Sub(r.lastDeepPass) >= r.deepInterval
+
+	ctx, span := r.tracer.Start(ctx, "reconcile-pass", trace.WithAttributes(
+		attribute.String("reconciler.id", r.ID.String()),
+		attribute.String("reconciler.pass.id", passEvent.Metadata.AuditID),
+		attribute.Bool("reconciler.pass.deep", deep),
+		attribute.Bool("dryrun", r.dryrun),
+	))
+	defer span.End()
+
+	logger := tracing.Logger(ctx, r.Logger).With(
+		zap.String("reconciler.pass.id", passEvent.Metadata.AuditID),
+		zap.Bool("reconciler.pass.deep", deep),
+	)
 
-			for _, appType := range appTypes {
-				if appType.Slug == r.applicationType {
-					desiredAppTypeID = appType.ID
-				}
-			}
+	logger.Info("executing reconciler loop", zap.String("time", time.Now().UTC().Format(time.RFC3339)))
 
-			if desiredAppTypeID == "" {
-				r.Logger.Error("could not find the specified application type in governor")
-				continue
-			}
+	// notifications are sent once per user at the end of the pass
+	notifications := notify.NewBatch()
+	ctx = notify.WithBatch(ctx, notifications)
 
-			// if it's slack application, reconcile all of the groups linked to it
-			for _, app := range apps {
-				if app.TypeID.String != desiredAppTypeID {
-					continue
-				}
+	pass := newPassReport()
+
+	apps, err := r.SlackApplications(ctx)
+	if err != nil {
+		r.passFailure(ctx, pass, "", "list slack applications", "", err)
+		r.reportPass(ctx, pass)
+		span.SetStatus(codes.Error, err.Error())
+
+		return
+	}
+
+	groupCount := r.reconcileGroups(ctx, logger, pass, apps, deep)
+
+	// the rest of the pass is skipped when shutting down, but not when the pass deadline was reached
+	if err := ctx.Err(); err != nil {
+		logger.Warn("reconciler loop canceled", zap.Error(err))
+		span.SetStatus(codes.Error, err.Error())
+
+		return
+	}
+
+	r.sendNotifications(ctx, notifications)
+
+	if err := r.NotifyMembershipRequests(ctx, ""); err != nil {
+		logger.Warn("error notifying membership requests", zap.Error(err))
+	}
 
-				groups, err := r.GovernorClient.ApplicationGroups(ctx, app.ID)
-				if err != nil {
-					r.Logger.Error("error listing groups", zap.Error(err))
-					continue
+	if err := r.PurgeRetiredUserGroups(ctx); err != nil {
+		logger.Warn("error purging retired user groups", zap.Error(err))
+	}
+
+	r.postUnmatchedReport(ctx)
+
+	r.reportPass(ctx, pass)
+
+	t := pass.totals()
+
+	// a partial deep pass doesn't count, the next pass is deep again
+	if deep && t.skipped == 0 {
+		r.lastDeepPass = start
+	}
+
+	span.SetAttributes(
+		attribute.Int("reconciler.pass.groups", groupCount),
+		attribute.Int("reconciler.pass.unchanged", t.unchanged),
+		attribute.Int("reconciler.pass.skipped", t.skipped),
+		attribute.Int("reconciler.pass.failures", t.failures),
+		attribute.Bool("reconciler.pass.partial", t.skipped > 0),
+	)
+
+	logger.Info("finished reconciler loop",
+		zap.String("time", time.Now().UTC().Format(time.RFC3339)),
+		zap.Int("reconciler.pass.groups", groupCount),
+		zap.Int("reconciler.pass.unchanged", t.unchanged),
+		zap.Int("reconciler.pass.skipped", t.skipped),
+		zap.Int("reconciler.pass.failures", t.failures),
+	)
+}
+
+// reconcileGroups reconciles the groups linked to the slack applications concurrently, bounded by
+// the worker pool, and returns the number of groups. If the pass deadline is reached or the pass is
+// canceled, the groups that weren't started are counted as skipped and the pass is partial. Unless
+// the pass is deep, the user groups of the groups that haven't changed since they were last reconciled
+// are left as they are. The fingerprints are taken by the workers.
+func (r *Reconciler) reconcileGroups(ctx context.Context, logger *zap.Logger, pass *passReport, apps []*v1alpha1.Application, deep bool) int {
+	if r.passTimeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, r.passTimeout)
+		defer cancel()
+	}
+
+	var (
+		wg     sync.WaitGroup
+		synced syncedGroups
+		ugs    passUserGroups
+		count  int
+	)
+
+	// reconcile all of the groups linked to each slack application
+	for _, app := range apps {
+		groups, err := r.GovernorClient.ApplicationGroups(ctx, app.ID)
+		if err != nil {
+			logger.Error("error listing groups", zap.Error(err))
+			r.passFailure(ctx, pass, app.Name, "list groups", "", err)
+
+			continue
+		}
+
+		logger.Debug("got groups", zap.Any("groups list", groups), zap.String("application", app.Name))
+
+		count += len(groups)
+
+		for _, g := range groups {
+			pass.reconciled(app.Name)
+
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				if err := r.workers.acquire(ctx, app.Name); err != nil {
+					pass.skipped(app.Name)
+					return
 				}
+				defer r.workers.release(app.Name)
 
-				r.Logger.Debug("got groups", zap.Any("groups list", groups), zap.String("application", app.Name))
+				fp := r.groupFingerprint(ctx, logger, &ugs, app, g)
 
-				for _, g := range groups {
-					if err := r.CreateUserGroup(ctx, g.ID, app.ID); err != nil {
-						if !errors.Is(err, slack.ErrSlackGroupAlreadyExists) {
-							r.Logger.Warn("error creating user group", zap.Error(err))
-						}
-					}
+				// deep passes still count the unchanged groups, to show how much work the other passes skip
+				unchanged := r.fingerprints.unchanged(g.ID, app.ID, fp)
+				if unchanged {
+					pass.unchanged(app.Name)
 
-					if err := r.UpdateUserGroupMembers(ctx, g.ID, app.ID); err != nil {
-						r.Logger.Warn("error updating user group members", zap.Error(err))
+					if !deep {
+						logger.Debug("group unchanged, only syncing its channels", zap.String("governor.group.id", g.ID), zap.String("application", app.Name))
 					}
 				}
-			}
 
-			r.Logger.Info("finished reconciler loop",
-				zap.String("time", time.Now().UTC().Format(time.RFC3339)),
-			)
+				if r.reconcileGroup(ctx, pass, app, g, &synced, unchanged && !deep) {
+					r.fingerprints.set(g.ID, app.ID, fp)
+				}
+			}()
+		}
+	}
 
-		case <-ctx.Done():
-			r.Logger.Info("shutting down reconciler",
-				zap.String("time", time.Now().UTC().Format(time.RFC3339)),
-			)
+	wg.Wait()
 
-			return
+	if err := ctx.Err(); err != nil {
+		logger.Warn("reconciler pass ended before all the groups were reconciled", zap.Int("reconciler.pass.skipped", pass.totals().skipped), zap.Error(err))
+	}
+
+	return count
+}
+
+// reconcileGroup reconciles the slack user group of a governor group linked to a slack application,
+// recording the failures in the pass report. The members of org-wide user groups are synced once
+// per pass, synced keeps track of the groups already synced. A governor group is only reconciled in
+// one workspace at a time, and a rate limited workspace is paused. If unchanged is set, only the
+// channel members are synced, since the channels aren't fingerprinted. It returns true if there were
+// no failures.
+func (r *Reconciler) reconcileGroup(
+	ctx context.Context,
+	pass *passReport,
+	app *v1alpha1.Application,
+	g *v1alpha1.Group,
+	synced *syncedGroups,
+	unchanged bool,
+) bool {
+	ctx, span := r.tracer.Start(ctx, "reconcile-group", trace.WithAttributes(
+		attribute.String("governor.group.id", g.ID),
+		attribute.String("governor.group.slug", g.Slug),
+		attribute.String("governor.app.id", app.ID),
+		attribute.String("slack.workspace.name", app.Name),
+	))
+	defer span.End()
+
+	logger := tracing.Logger(ctx, r.Logger).With(zap.String("governor.group.id", g.ID), zap.String("governor.app.id", app.ID))
+
+	ctx, unlock := r.lockGroup(ctx, g.ID)
+	defer unlock()
+
+	ok := true
+
+	failed := func(op string, err error) {
+		ok = false
+
+		if r.workers.rateLimited(app.Name, err) {
+			logger.Warn("slack rate limited the workspace, pausing its workers", zap.String("operation", op))
+		}
+
+		span.RecordError(err, trace.WithAttributes(attribute.String("operation", op)))
+		span.SetStatus(codes.Error, op+": "+err.Error())
+		r.passFailure(ctx, pass, app.Name, op, g.Slug, err)
+	}
+
+	if unchanged {
+		if err := r.SyncChannelMembers(ctx, g.ID, app.ID); err != nil {
+			logger.Warn("error syncing channel members", zap.Error(err))
+			failed("sync channel members", err)
+		}
+
+		return ok
+	}
+
+	if err := r.CreateUserGroup(ctx, g.ID, app.ID); err != nil {
+		if !errors.Is(err, slack.ErrSlackGroupAlreadyExists) {
+			logger.Warn("error creating user group", zap.Error(err))
+			failed("create user group", err)
+		}
+	}
+
+	if err := r.AttachUserGroup(ctx, g.ID, app.ID); err != nil {
+		logger.Warn("error attaching user group to workspace", zap.Error(err))
+		failed("attach user group", err)
+	}
+
+	if synced.claim(g.ID, r.orgWide()) {
+		if err := r.UpdateUserGroupMembers(ctx, g.ID, app.ID); err != nil {
+			logger.Warn("error updating user group members", zap.Error(err))
+			failed("update members", err)
+		}
+
+		if err := r.UpdateUserGroupDefaultChannels(ctx, g.ID, app.ID); err != nil {
+			logger.Warn("error updating user group default channels", zap.Error(err))
+			failed("update default channels", err)
+		}
+	}
+
+	if err := r.SyncChannelMembers(ctx, g.ID, app.ID); err != nil {
+		logger.Warn("error syncing channel members", zap.Error(err))
+		failed("sync channel members", err)
+	}
+
+	return ok
+}
+
+// SlackApplications returns the governor applications with the configured slack application type
+func (r *Reconciler) SlackApplications(ctx context.Context) ([]*v1alpha1.Application, error) {
+	apps, err := r.GovernorClient.Applications(ctx)
+	if err != nil {
+		r.Logger.Error("error listing governor applications", zap.Error(err))
+		return nil, err
+	}
+
+	r.Logger.Debug("got applications", zap.Any("applications list", apps))
+
+	appTypes, err := r.GovernorClient.ApplicationTypes(ctx)
+	if err != nil {
+		r.Logger.Error("error listing governor application types")
+		return nil, err
+	}
+
+	var desiredAppTypeID string
+
+	for _, appType := range appTypes {
+		if appType.Slug == r.applicationType {
+			desiredAppTypeID = appType.ID
 		}
 	}
+
+	if desiredAppTypeID == "" {
+		r.Logger.Error("could not find the specified application type in governor")
+		return nil, ErrApplicationTypeNotFound
+	}
+
+	slackApps := []*v1alpha1.Application{}
+
+	for _, app := range apps {
+		if app.TypeID.String == desiredAppTypeID {
+			slackApps = append(slackApps, app)
+		}
+	}
+
+	return slackApps, nil
 }
 
 // Stop stops the reconciler loop and does any necessary cleanup
@@ -10,6 +10,8 @@ import (
 
 	"github.com/metal-toolbox/governor-api/pkg/api/v1alpha1"
 	"go.uber.org/zap"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/govapi"
 )
 
 // ErrMissingMockedResponse is returned when a mocked response is missing
@@ -139,6 +141,52 @@ func (m mockGovernorClient) User(_ context.Context, _ string, _ bool) (*v1alpha1
 	return &out, nil
 }
 
+func (m mockGovernorClient) UserByEmail(_ context.Context, _ string) (*v1alpha1.User, error) {
+	return m.User(context.Background(), "", false)
+}
+
+func (m mockGovernorClient) CreateGroupMembershipRequest(_ context.Context, _, _, _ string) (*govapi.MembershipRequest, error) {
+	return m.GroupMembershipRequest(context.Background(), "", "")
+}
+
+func (m mockGovernorClient) GroupMembershipRequests(_ context.Context, _ string) ([]*govapi.MembershipRequest, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	if m.resp == nil {
+		return nil, ErrMissingMockedResponse
+	}
+
+	out := []*govapi.MembershipRequest{}
+	if err := json.Unmarshal(m.resp, &out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (m mockGovernorClient) GroupMembershipRequest(_ context.Context, _, _ string) (*govapi.MembershipRequest, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	if m.resp == nil {
+		return nil, ErrMissingMockedResponse
+	}
+
+	out := govapi.MembershipRequest{}
+	if err := json.Unmarshal(m.resp, &out); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+func (m mockGovernorClient) DeleteGroupMembershipRequest(_ context.Context, _, _ string) error {
+	return m.err
+}
+
 func (m mockGovernorClient) URL() string {
 	return "https://governor.example.com"
 }
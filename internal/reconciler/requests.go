@@ -0,0 +1,267 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/govapi"
+	"github.com/metal-toolbox/gov-slack-addon/internal/store"
+	"github.com/metal-toolbox/gov-slack-addon/internal/tracing"
+)
+
+// MembershipRequestsEnabled returns true if users can request to join governor groups from slack
+func (r *Reconciler) MembershipRequestsEnabled() bool {
+	return r.membershipRequests
+}
+
+// RequestMembership files a request for the slack user to join the governor group, on behalf of
+// the governor user with the same email. The user is notified in slack when the request is
+// approved or denied.
+func (r *Reconciler) RequestMembership(ctx context.Context, slackUserID, groupID string) (*govapi.MembershipRequest, error) {
+	if slackUserID == "" || groupID == "" {
+		return nil, ErrBadParameter
+	}
+
+	if !r.membershipRequests {
+		return nil, ErrMembershipRequestsDisabled
+	}
+
+	logger := tracing.Logger(ctx, r.Logger).With(zap.String("slack.user.id", slackUserID), zap.String("governor.group.id", groupID))
+
+	su, err := r.Client.GetUser(ctx, slackUserID)
+	if err != nil {
+		logger.Error("failed to get slack user", zap.Error(err))
+		return nil, err
+	}
+
+	if su.Profile.Email == "" {
+		return nil, fmt.Errorf("%w: slack user has no email", ErrGovernorUserNotFound)
+	}
+
+	user, err := r.GovernorClient.UserByEmail(ctx, su.Profile.Email)
+	if err != nil {
+		if errors.Is(err, govapi.ErrUserNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrGovernorUserNotFound, su.Profile.Email)
+		}
+
+		logger.Error("failed to get governor user", zap.Error(err))
+
+		return nil, err
+	}
+
+	logger = logger.With(zap.String("governor.user.id", user.ID))
+
+	members, err := r.GovernorClient.GroupMembers(ctx, groupID)
+	if err != nil {
+		logger.Error("error getting governor group members", zap.Error(err))
+		return nil, err
+	}
+
+	for _, m := range members {
+		if m.ID == user.ID {
+			return nil, ErrAlreadyGroupMember
+		}
+	}
+
+	pending, err := r.GovernorClient.GroupMembershipRequests(ctx, groupID)
+	if err != nil {
+		logger.Error("failed to list governor membership requests", zap.Error(err))
+		return nil, err
+	}
+
+	for _, p := range pending {
+		if p.UserID == user.ID {
+			return p, ErrMembershipRequestPending
+		}
+	}
+
+	target := map[string]string{
+		"slack.user.id":       slackUserID,
+		"governor.user.id":    user.ID,
+		"governor.user.email": user.Email,
+		"governor.group.id":   groupID,
+	}
+
+	if r.dryrun {
+		logger.Info("SKIP requesting governor group membership")
+		r.audit(ctx, logger, "GroupMembershipRequest", target, nil, nil)
+
+		return nil, nil
+	}
+
+	req, err := r.GovernorClient.CreateGroupMembershipRequest(ctx, groupID, user.ID, "requested from slack")
+	if err != nil {
+		logger.Error("failed to request governor group membership", zap.Error(err))
+		r.audit(ctx, logger, "GroupMembershipRequest", target, nil, err)
+
+		return nil, err
+	}
+
+	// governor files the request for the user of the body only if the addon is allowed to request
+	// on behalf of other users, otherwise it may be filed for the addon itself
+	if req.UserID != user.ID {
+		logger.Error("governor filed the membership request for another user, withdrawing it",
+			zap.String("governor.request.id", req.ID), zap.String("governor.request.user.id", req.UserID))
+
+		if err := r.GovernorClient.DeleteGroupMembershipRequest(ctx, groupID, req.ID); err != nil {
+			logger.Error("failed to withdraw membership request", zap.String("governor.request.id", req.ID), zap.Error(err))
+		}
+
+		err := fmt.Errorf("%w: got %s", ErrMembershipRequestWrongUser, req.UserID)
+		r.audit(ctx, logger, "GroupMembershipRequest", target, nil, err)
+
+		return nil, err
+	}
+
+	logger.Info("requested governor group membership", zap.String("governor.request.id", req.ID))
+
+	if err := r.store.PutMembershipRequest(ctx, &store.MembershipRequest{
+		ID:              req.ID,
+		GovernorGroupID: groupID,
+		GovernorUserID:  user.ID,
+		SlackUserID:     slackUserID,
+		CreatedAt:       time.Now().UTC(),
+	}); err != nil {
+		logger.Error("failed to record membership request, the user won't be notified", zap.Error(err))
+	}
+
+	target["governor.request.id"] = req.ID
+
+	r.audit(ctx, logger, "GroupMembershipRequest", target, nil, nil)
+
+	return req, nil
+}
+
+// NotifyMembershipRequests notifies the users of the membership requests filed from slack that
+// have been settled since, according to the status of the request in governor. If groupID is set,
+// only the requests to join that group are checked.
+func (r *Reconciler) NotifyMembershipRequests(ctx context.Context, groupID string) error {
+	if !r.membershipRequests {
+		return nil
+	}
+
+	requests, err := r.store.ListMembershipRequests(ctx)
+	if err != nil {
+		r.Logger.Error("failed to list membership requests", zap.Error(err))
+		return err
+	}
+
+	byGroup := map[string][]*store.MembershipRequest{}
+
+	for _, req := range requests {
+		if groupID == "" || req.GovernorGroupID == groupID {
+			byGroup[req.GovernorGroupID] = append(byGroup[req.GovernorGroupID], req)
+		}
+	}
+
+	var errs []error
+
+	for gid, reqs := range byGroup {
+		if err := r.notifyGroupMembershipRequests(ctx, gid, reqs); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// notifyGroupMembershipRequests notifies the users of the settled membership requests of the group
+func (r *Reconciler) notifyGroupMembershipRequests(ctx context.Context, groupID string, reqs []*store.MembershipRequest) error {
+	logger := tracing.Logger(ctx, r.Logger).With(zap.String("governor.group.id", groupID))
+
+	pending, err := r.GovernorClient.GroupMembershipRequests(ctx, groupID)
+	if err != nil {
+		logger.Error("failed to list governor membership requests", zap.Error(err))
+		return err
+	}
+
+	pendingIDs := map[string]bool{}
+	for _, p := range pending {
+		pendingIDs[p.ID] = true
+	}
+
+	group, err := r.GovernorClient.Group(ctx, groupID, true)
+	if err != nil {
+		logger.Error("error getting governor group", zap.Error(err))
+		return err
+	}
+
+	members, err := r.GovernorClient.GroupMembers(ctx, groupID)
+	if err != nil {
+		logger.Error("error getting governor group members", zap.Error(err))
+		return err
+	}
+
+	memberIDs := map[string]bool{}
+	for _, m := range members {
+		memberIDs[m.ID] = true
+	}
+
+	for _, req := range reqs {
+		if pendingIDs[req.ID] {
+			continue
+		}
+
+		status, err := r.membershipRequestStatus(ctx, groupID, req.ID)
+		if err != nil {
+			logger.Error("failed to get governor membership request", zap.String("governor.request.id", req.ID), zap.Error(err))
+			continue
+		}
+
+		if status == govapi.MembershipRequestPending {
+			continue
+		}
+
+		text := membershipRequestOutcome(group.Name, status, memberIDs[req.GovernorUserID])
+
+		if r.dryrun {
+			logger.Info("SKIP notifying membership request outcome", zap.String("governor.request.id", req.ID), zap.String("slack.user.id", req.SlackUserID))
+			continue
+		}
+
+		if _, err := r.Client.PostMessage(ctx, req.SlackUserID, text); err != nil {
+			logger.Error("failed to notify membership request outcome", zap.String("governor.request.id", req.ID), zap.Error(err))
+			continue
+		}
+
+		if err := r.store.DeleteMembershipRequest(ctx, req.ID); err != nil {
+			logger.Error("failed to delete membership request record", zap.String("governor.request.id", req.ID), zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// membershipRequestStatus returns the status of the membership request in governor, the status is
+// empty if governor no longer has the request
+func (r *Reconciler) membershipRequestStatus(ctx context.Context, groupID, requestID string) (govapi.MembershipRequestStatus, error) {
+	req, err := r.GovernorClient.GroupMembershipRequest(ctx, groupID, requestID)
+	if err != nil {
+		if errors.Is(err, govapi.ErrNotFound) {
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	return req.Status, nil
+}
+
+// membershipRequestOutcome returns the message telling the user how their request was settled. The
+// membership is only used when governor doesn't tell the status of the request.
+func membershipRequestOutcome(groupName string, status govapi.MembershipRequestStatus, member bool) string {
+	switch {
+	case status == govapi.MembershipRequestApproved, status == "" && member:
+		return fmt.Sprintf("Your request to join the governor group *%s* was approved, welcome!", groupName)
+	case status == govapi.MembershipRequestDenied:
+		return fmt.Sprintf("Your request to join the governor group *%s* was denied.", groupName)
+	case status == govapi.MembershipRequestRevoked:
+		return fmt.Sprintf("Your request to join the governor group *%s* was withdrawn.", groupName)
+	default:
+		return fmt.Sprintf("Your request to join the governor group *%s* is no longer pending.", groupName)
+	}
+}
@@ -0,0 +1,74 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/govapi"
+)
+
+func TestReconciler_membershipRequestStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  mockGovernorClient
+		want    govapi.MembershipRequestStatus
+		wantErr bool
+	}{
+		{
+			name:   "denied",
+			client: mockGovernorClient{resp: []byte(`{"id":"req-1","status":"denied"}`)},
+			want:   govapi.MembershipRequestDenied,
+		},
+		{
+			name:   "not found",
+			client: mockGovernorClient{err: fmt.Errorf("%w: %w", govapi.ErrRequestNonSuccess, govapi.ErrNotFound)},
+			want:   "",
+		},
+		{
+			name:    "error",
+			client:  mockGovernorClient{err: errors.New("boom")}, //nolint:err113
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Reconciler{GovernorClient: tt.client}
+
+			got, err := r.membershipRequestStatus(context.Background(), "group-1", "req-1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("membershipRequestStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("membershipRequestStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_membershipRequestOutcome(t *testing.T) {
+	tests := []struct {
+		name   string
+		status govapi.MembershipRequestStatus
+		member bool
+		want   string
+	}{
+		{"approved", govapi.MembershipRequestApproved, false, "Your request to join the governor group *Ops* was approved, welcome!"},
+		{"denied", govapi.MembershipRequestDenied, false, "Your request to join the governor group *Ops* was denied."},
+		{"denied but member", govapi.MembershipRequestDenied, true, "Your request to join the governor group *Ops* was denied."},
+		{"revoked", govapi.MembershipRequestRevoked, false, "Your request to join the governor group *Ops* was withdrawn."},
+		{"unknown and member", "", true, "Your request to join the governor group *Ops* was approved, welcome!"},
+		{"unknown", "", false, "Your request to join the governor group *Ops* is no longer pending."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := membershipRequestOutcome("Ops", tt.status, tt.member); got != tt.want {
+				t.Errorf("membershipRequestOutcome() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
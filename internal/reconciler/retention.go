@@ -0,0 +1,342 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/metal-toolbox/governor-api/pkg/api/v1alpha1"
+	"go.uber.org/zap"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/slack"
+	"github.com/metal-toolbox/gov-slack-addon/internal/store"
+	"github.com/metal-toolbox/gov-slack-addon/internal/tracing"
+)
+
+var (
+	// retiredNamePattern matches the name given to a user group by DeleteUserGroup, with the original
+	// name and the retirement timestamp
+	retiredNamePattern = regexp.MustCompile(`^(.*) \(deleted (\d+)\)$`)
+	// retiredDescriptionPattern matches the description given to a user group by DeleteUserGroup
+	retiredDescriptionPattern = regexp.MustCompile(`\(deleted by gov-slack-addon (\d+)\)$`)
+)
+
+// recordRetirement records the user group retired by DeleteUserGroup, so it can be purged once
+// the retention period expires
+func (r *Reconciler) recordRetirement(ctx context.Context, logger *zap.Logger, ug *UserGroup, groupID, appID, workspace, teamID string) {
+	if err := r.store.PutRetirement(ctx, &store.Retirement{
+		TeamID:          teamID,
+		Workspace:       workspace,
+		UserGroupID:     ug.ID,
+		GovernorGroupID: groupID,
+		GovernorAppID:   appID,
+		OriginalName:    ug.Name,
+		OriginalHandle:  ug.Handle,
+		RetiredAt:       time.Now().UTC(),
+	}); err != nil {
+		logger.Error("failed to record user group retirement", zap.String("slack.usergroup.id", ug.ID), zap.Error(err))
+	}
+}
+
+// PurgeRetiredUserGroups purges the user groups retired by the addon longer ago than the retention
+// period. Slack doesn't allow deleting user groups, so their members are removed, except for the
+// addon's own user since a user group can't be empty, their description is cleared and they're
+// renamed to a short name derived from their id. The retirement record is kept, with the purge time.
+// User groups retired without a record, before the store was in use, are recorded first.
+func (r *Reconciler) PurgeRetiredUserGroups(ctx context.Context) error {
+	if r.retention <= 0 {
+		return nil
+	}
+
+	if err := r.recordUnrecordedRetirements(ctx); err != nil {
+		r.Logger.Warn("failed to record user groups retired without a record", zap.Error(err))
+	}
+
+	retirements, err := r.store.ListRetirements(ctx)
+	if err != nil {
+		r.Logger.Error("failed to list retired user groups", zap.Error(err))
+		return err
+	}
+
+	expired := expiredRetirements(retirements, time.Now().UTC().Add(-r.retention))
+	if len(expired) == 0 {
+		return nil
+	}
+
+	selfID := ""
+
+	if !r.dryrun {
+		if selfID, err = r.selfUserID(ctx); err != nil {
+			r.Logger.Error("failed to get the slack user of the token", zap.Error(err))
+			return err
+		}
+	}
+
+	var errs []error
+
+	for _, ret := range expired {
+		if err := r.purgeRetiredUserGroup(ctx, ret, selfID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// recordUnrecordedRetirements scans the disabled user groups of the slack workspaces for user groups
+// retired by the addon without a retirement record, and records them with the retirement time from
+// their name. The scan runs until it succeeds once in the process.
+func (r *Reconciler) recordUnrecordedRetirements(ctx context.Context) error {
+	if r.retirementsScanned {
+		return nil
+	}
+
+	apps, err := r.SlackApplications(ctx)
+	if err != nil {
+		return err
+	}
+
+	var (
+		errs    []error
+		scanned = map[string]bool{}
+	)
+
+	for _, app := range apps {
+		teamID, err := r.userGroupTeamID(ctx, app.Name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		// org-wide user groups are listed once for all the workspaces
+		if scanned[teamID] {
+			continue
+		}
+
+		scanned[teamID] = true
+
+		usergroups, err := r.Client.GetUserGroups(ctx, teamID, true)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, sug := range usergroups {
+			ret := r.unrecordedRetirement(toUserGroup(sug), teamID, app)
+			if ret == nil {
+				continue
+			}
+
+			if err := r.recordUnrecordedRetirement(ctx, ret); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	r.retirementsScanned = true
+
+	return nil
+}
+
+// unrecordedRetirement returns the retirement of a user group retired by DeleteUserGroup, or nil if the
+// user group wasn't retired by the addon. The governor group of the user group isn't known.
+func (r *Reconciler) unrecordedRetirement(ug *UserGroup, teamID string, app *v1alpha1.Application) *store.Retirement {
+	if !ug.Disabled {
+		return nil
+	}
+
+	name := retiredNamePattern.FindStringSubmatch(ug.Name)
+	if name == nil || !strings.HasPrefix(name[1], r.userGroupPrefix) {
+		return nil
+	}
+
+	// the description tells the user groups retired by the addon from the ones renamed by hand
+	description := retiredDescriptionPattern.FindStringSubmatch(ug.Description)
+	if description == nil || description[1] != name[2] {
+		return nil
+	}
+
+	ts, err := strconv.ParseInt(name[2], 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	return &store.Retirement{
+		TeamID:         teamID,
+		Workspace:      app.Name,
+		UserGroupID:    ug.ID,
+		GovernorAppID:  app.ID,
+		OriginalName:   name[1],
+		OriginalHandle: strings.TrimSuffix(ug.Handle, "-deleted-"+name[2]),
+		RetiredAt:      time.Unix(ts, 0).UTC(),
+	}
+}
+
+// recordUnrecordedRetirement records the retirement if the user group has no retirement record yet
+func (r *Reconciler) recordUnrecordedRetirement(ctx context.Context, ret *store.Retirement) error {
+	logger := r.Logger.With(
+		zap.String("slack.workspace.name", ret.Workspace),
+		zap.String("slack.usergroup.id", ret.UserGroupID),
+		zap.String("slack.usergroup.original.name", ret.OriginalName),
+		zap.Time("retired_at", ret.RetiredAt),
+	)
+
+	_, err := r.store.GetRetirement(ctx, ret.TeamID, ret.UserGroupID)
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, store.ErrNotFound) {
+		logger.Error("failed to get user group retirement", zap.Error(err))
+		return err
+	}
+
+	if r.dryrun {
+		logger.Info("SKIP recording retired user group without a record")
+		return nil
+	}
+
+	if err := r.store.PutRetirement(ctx, ret); err != nil {
+		logger.Error("failed to record user group retirement", zap.Error(err))
+		return err
+	}
+
+	logger.Info("recorded retired user group without a record")
+
+	return nil
+}
+
+// expiredRetirements returns the retirements that haven't been purged and were retired before the cutoff
+func expiredRetirements(retirements []*store.Retirement, cutoff time.Time) []*store.Retirement {
+	expired := []*store.Retirement{}
+
+	for _, ret := range retirements {
+		if ret.PurgedAt == nil && ret.RetiredAt.Before(cutoff) {
+			expired = append(expired, ret)
+		}
+	}
+
+	return expired
+}
+
+// purgedUserGroupName returns the name and handle of a purged user group
+func purgedUserGroupName(userGroupID string) (string, string) {
+	return "retired " + userGroupID, sanitizeHandle("retired-" + strings.ToLower(userGroupID))
+}
+
+// purgeRetiredUserGroup strips the retired user group and marks the retirement as purged
+func (r *Reconciler) purgeRetiredUserGroup(ctx context.Context, ret *store.Retirement, selfID string) error {
+	logger := tracing.Logger(ctx, r.Logger).With(
+		zap.String("slack.workspace.name", ret.Workspace),
+		zap.String("slack.usergroup.id", ret.UserGroupID),
+		zap.String("governor.group.id", ret.GovernorGroupID),
+		zap.Time("retired_at", ret.RetiredAt),
+	)
+
+	ug, err := r.userGroupFromID(ctx, ret.UserGroupID, ret.TeamID, true)
+	if err != nil && !errors.Is(err, ErrSlackUserGroupNotFound) {
+		logger.Error("failed to get retired user group", zap.Error(err))
+		return err
+	}
+
+	var (
+		target map[string]string
+		diff   map[string]any
+	)
+
+	if ug != nil {
+		target = map[string]string{
+			"slack.workspace.name":          ret.Workspace,
+			"slack.usergroup.id":            ug.ID,
+			"slack.usergroup.name":          ug.Name,
+			"slack.usergroup.original.name": ret.OriginalName,
+			"governor.app.id":               ret.GovernorAppID,
+			"governor.group.id":             ret.GovernorGroupID,
+		}
+		diff = purgeDiff(ug, selfID)
+
+		if members, ok := diff["members"].(*listDiff); ok {
+			r.withEmails(ctx, members)
+		}
+	}
+
+	switch {
+	case ug == nil:
+		logger.Info("retired user group not found, marking as purged")
+	case !ug.Disabled:
+		// the user group was re-enabled by hand, leave it alone
+		logger.Warn("retired user group has been re-enabled, not purging it", zap.String("slack.usergroup.name", ug.Name))
+		return nil
+	case r.dryrun:
+		logger.Info("SKIP purging retired slack user group", zap.String("slack.usergroup.name", ug.Name))
+		r.audit(ctx, logger, "UserGroupPurge", target, diff, nil)
+
+		return nil
+	default:
+		if err := r.stripUserGroup(ctx, ug, ret.TeamID, selfID); err != nil {
+			logger.Error("failed to purge retired user group", zap.Error(err))
+			r.audit(ctx, logger, "UserGroupPurge", target, diff, err)
+
+			return err
+		}
+
+		logger.Info("purged retired user group", zap.String("slack.usergroup.name", ug.Name))
+
+		r.audit(ctx, logger, "UserGroupPurge", target, diff, nil)
+	}
+
+	now := time.Now().UTC()
+	ret.PurgedAt = &now
+
+	if err := r.store.PutRetirement(ctx, ret); err != nil {
+		logger.Error("failed to record user group purge", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// purgeDiff computes the change made to the user group by stripUserGroup
+func purgeDiff(ug *UserGroup, selfID string) map[string]any {
+	name, handle := purgedUserGroupName(ug.ID)
+	description := ""
+
+	diff := userGroupDiff(ug, &slack.UserGroupReq{Name: &name, Handle: &handle, Description: &description})
+
+	if len(ug.Users) > 0 && !slices.Equal(ug.Users, []string{selfID}) {
+		diff["members"] = newListDiff(ug.Users, []string{selfID})
+	}
+
+	return diff
+}
+
+// stripUserGroup removes the members of the user group, except for the given user, clears its
+// description and renames it to its purged name
+func (r *Reconciler) stripUserGroup(ctx context.Context, ug *UserGroup, teamID, selfID string) error {
+	if len(ug.Users) > 0 && !slices.Equal(ug.Users, []string{selfID}) {
+		if _, err := r.Client.UpdateUserGroupMembers(ctx, ug.ID, teamID, []string{selfID}); err != nil {
+			return err
+		}
+	}
+
+	name, handle := purgedUserGroupName(ug.ID)
+	description := ""
+
+	if _, err := r.Client.UpdateUserGroup(ctx, ug.ID, teamID, slack.UserGroupReq{
+		Name:        &name,
+		Handle:      &handle,
+		Description: &description,
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
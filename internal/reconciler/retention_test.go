@@ -0,0 +1,98 @@
+package reconciler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/metal-toolbox/governor-api/pkg/api/v1alpha1"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/store"
+)
+
+func Test_expiredRetirements(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	purged := now.Add(-24 * time.Hour)
+
+	retirements := []*store.Retirement{
+		{UserGroupID: "S0001", RetiredAt: now.Add(-90 * 24 * time.Hour)},
+		{UserGroupID: "S0002", RetiredAt: now.Add(-time.Hour)},
+		{UserGroupID: "S0003", RetiredAt: now.Add(-90 * 24 * time.Hour), PurgedAt: &purged},
+	}
+
+	got := expiredRetirements(retirements, now.Add(-30*24*time.Hour))
+
+	if len(got) != 1 || got[0].UserGroupID != "S0001" {
+		t.Errorf("expiredRetirements() = %+v, want only S0001", got)
+	}
+
+	if got := expiredRetirements(nil, now); len(got) != 0 {
+		t.Errorf("expiredRetirements() = %+v, want none", got)
+	}
+}
+
+func Test_purgedUserGroupName(t *testing.T) {
+	name, handle := purgedUserGroupName("S0123ABCDEF")
+
+	if name != "retired S0123ABCDEF" {
+		t.Errorf("purgedUserGroupName() name = %q", name)
+	}
+
+	if handle != "retired-s0123abcdef" {
+		t.Errorf("purgedUserGroupName() handle = %q", handle)
+	}
+}
+
+func TestReconciler_unrecordedRetirement(t *testing.T) {
+	r := &Reconciler{userGroupPrefix: "[Governor] "}
+	app := &v1alpha1.Application{ID: "app-1", Name: "ws-a"}
+
+	retired := func(name, handle, description string, disabled bool) *UserGroup {
+		return &UserGroup{ID: "S0001", Name: name, Handle: handle, Description: description, Disabled: disabled}
+	}
+
+	tests := []struct {
+		name string
+		ug   *UserGroup
+		want *store.Retirement
+	}{
+		{
+			name: "retired by the addon",
+			ug:   retired("[Governor] Team X (deleted 1700000000)", "team-x-deleted-1700000000", "Team X (deleted by gov-slack-addon 1700000000)", true),
+			want: &store.Retirement{
+				TeamID:         "T0001",
+				Workspace:      "ws-a",
+				UserGroupID:    "S0001",
+				GovernorAppID:  "app-1",
+				OriginalName:   "[Governor] Team X",
+				OriginalHandle: "team-x",
+				RetiredAt:      time.Unix(1700000000, 0).UTC(),
+			},
+		},
+		{
+			name: "enabled",
+			ug:   retired("[Governor] Team X (deleted 1700000000)", "team-x-deleted-1700000000", "Team X (deleted by gov-slack-addon 1700000000)", false),
+		},
+		{
+			name: "without the prefix",
+			ug:   retired("Team X (deleted 1700000000)", "team-x-deleted-1700000000", "Team X (deleted by gov-slack-addon 1700000000)", true),
+		},
+		{
+			name: "renamed by hand",
+			ug:   retired("[Governor] Team X (deleted 1700000000)", "team-x", "Team X", true),
+		},
+		{
+			name: "disabled by hand",
+			ug:   retired("[Governor] Team X", "team-x", "Team X", true),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := r.unrecordedRetirement(tt.ug, "T0001", app)
+
+			if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+				t.Errorf("unrecordedRetirement() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package reconciler
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/govapi"
+	"github.com/metal-toolbox/gov-slack-addon/internal/notify"
+)
+
+const (
+	// SettingsERD is the plural slug of the extension resource definition of the group slack settings
+	SettingsERD = "slack-usergroup-settings"
+	// SettingsERDVersion is the version of the extension resource definition of the group slack settings
+	SettingsERDVersion = "v1alpha1"
+
+	// settingsTTL is how long the settings of a group are cached before they're listed again
+	settingsTTL = time.Minute
+)
+
+// GroupSettings are the slack settings of a governor group, set by its admins in governor as a
+// slack-usergroup-settings extension resource of the group
+type GroupSettings struct {
+	// GroupID is the id of the governor group the resource belongs to, it's never read from the resource
+	GroupID string `json:"-"`
+	// Handle overrides the handle rendered from the handle template
+	Handle string `json:"handle,omitempty"`
+	// DefaultChannels overrides the default channels rules if set, an empty list clears the default channels
+	DefaultChannels []string `json:"default_channels,omitempty"`
+	// Workspaces limits the slack workspaces the group is synced to, by name, it's synced to all the
+	// workspaces it's linked to if empty
+	Workspaces []string `json:"workspaces,omitempty"`
+	// Notifications turns the membership notifications of the group on or off, unset kinds are sent
+	// if notifications are enabled
+	Notifications NotificationSettings `json:"notifications"`
+}
+
+// NotificationSettings turns each kind of membership notification on or off
+type NotificationSettings struct {
+	Added          *bool `json:"added,omitempty"`
+	Removed        *bool `json:"removed,omitempty"`
+	NotInWorkspace *bool `json:"not_in_workspace,omitempty"`
+}
+
+// extensionResources manages the resources of the addon's governor extension
+type extensionResources interface {
+	ExtensionResources(ctx context.Context, extension, erd, version string) ([]*govapi.ExtensionResource, error)
+	GroupExtensionResources(ctx context.Context, groupID, extension, erd, version string) ([]*govapi.ExtensionResource, error)
+	CreateExtensionResource(ctx context.Context, extension, erd, version string, resource any) (*govapi.ExtensionResource, error)
+	UpdateExtensionResource(ctx context.Context, extension, erd, version, id string, resource any) (*govapi.ExtensionResource, error)
+	DeleteExtensionResource(ctx context.Context, extension, erd, version, id string) error
+}
+
+// groupSettingsEntry is the cached settings of a governor group, nil if it has none
+type groupSettingsEntry struct {
+	settings *GroupSettings
+	loadedAt time.Time
+}
+
+// groupSettingsCache keeps the group settings listed from governor, by governor group id
+type groupSettingsCache struct {
+	mu     sync.RWMutex
+	groups map[string]*groupSettingsEntry
+}
+
+func newGroupSettingsCache() *groupSettingsCache {
+	return &groupSettingsCache{groups: make(map[string]*groupSettingsEntry)}
+}
+
+// get returns the cached settings of the group, or nil if it has none
+func (c *groupSettingsCache) get(groupID string) *GroupSettings {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if e, ok := c.groups[groupID]; ok {
+		return e.settings
+	}
+
+	return nil
+}
+
+// stale returns true if the settings of the group need to be listed again
+func (c *groupSettingsCache) stale(groupID string, now time.Time) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	e, ok := c.groups[groupID]
+
+	return !ok || now.Sub(e.loadedAt) >= settingsTTL
+}
+
+// set replaces the cached settings of the group
+func (c *groupSettingsCache) set(groupID string, settings *GroupSettings, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.groups[groupID] = &groupSettingsEntry{settings: settings, loadedAt: now}
+}
+
+// refreshGroupSettings lists the settings resources of the group from governor if its cached settings
+// are stale. The cached settings are kept if they can't be listed.
+func (r *Reconciler) refreshGroupSettings(ctx context.Context, groupID string) {
+	if r.extensions == nil || !r.settings.stale(groupID, time.Now()) {
+		return
+	}
+
+	resources, err := r.extensions.GroupExtensionResources(ctx, groupID, r.extensionSlug, SettingsERD, SettingsERDVersion)
+	if err != nil {
+		r.Logger.Error("failed to list group settings",
+			zap.String("governor.group.id", groupID),
+			zap.String("governor.extension", r.extensionSlug),
+			zap.Error(err),
+		)
+
+		return
+	}
+
+	r.settings.set(groupID, parseGroupSettings(r.Logger, groupID, resources), time.Now())
+}
+
+// parseGroupSettings decodes the settings resources of the governor group, skipping invalid resources.
+// A group should have one settings resource, the most recently updated one is used if it has more.
+func parseGroupSettings(logger *zap.Logger, groupID string, resources []*govapi.ExtensionResource) *GroupSettings {
+	var (
+		out     *GroupSettings
+		updated time.Time
+	)
+
+	for _, res := range resources {
+		s := &GroupSettings{}
+
+		if err := json.Unmarshal(res.Resource, s); err != nil {
+			logger.Warn("skipping invalid group settings", zap.String("governor.group.id", groupID), zap.String("governor.resource.id", res.ID), zap.Error(err))
+			continue
+		}
+
+		if out != nil {
+			logger.Warn("group has several settings resources, using the most recently updated",
+				zap.String("governor.group.id", groupID),
+				zap.String("governor.resource.id", res.ID),
+			)
+
+			if !res.UpdatedAt.After(updated) {
+				continue
+			}
+		}
+
+		s.GroupID = groupID
+		out, updated = s, res.UpdatedAt
+	}
+
+	return out
+}
+
+// groupSettings returns the settings of the governor group, or nil if it has none
+func (r *Reconciler) groupSettings(ctx context.Context, groupID string) *GroupSettings {
+	r.refreshGroupSettings(ctx, groupID)
+
+	return r.settings.get(groupID)
+}
+
+// syncsToWorkspace returns false if the group settings limit the group to other workspaces
+func (r *Reconciler) syncsToWorkspace(ctx context.Context, groupID, workspace string) bool {
+	s := r.groupSettings(ctx, groupID)
+	if s == nil || len(s.Workspaces) == 0 {
+		return true
+	}
+
+	return slices.Contains(s.Workspaces, workspace)
+}
+
+// notificationEnabled returns false if the group settings turned off the kind of notification
+func (s *GroupSettings) notificationEnabled(kind notify.Kind) bool {
+	if s == nil {
+		return true
+	}
+
+	var on *bool
+
+	switch kind {
+	case notify.KindAdded:
+		on = s.Notifications.Added
+	case notify.KindRemoved:
+		on = s.Notifications.Removed
+	case notify.KindNotInWorkspace:
+		on = s.Notifications.NotInWorkspace
+	}
+
+	return on == nil || *on
+}
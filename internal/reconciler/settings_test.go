@@ -0,0 +1,177 @@
+package reconciler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/metal-toolbox/governor-api/pkg/api/v1alpha1"
+	"go.uber.org/zap"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/govapi"
+	"github.com/metal-toolbox/gov-slack-addon/internal/notify"
+)
+
+type mockExtensionResources struct {
+	resources      []*govapi.ExtensionResource
+	groupResources map[string][]*govapi.ExtensionResource
+	calls          int
+	groupCalls     int
+
+	created []any
+	updated map[string]any
+	deleted []string
+}
+
+func (m *mockExtensionResources) ExtensionResources(_ context.Context, _, _, _ string) ([]*govapi.ExtensionResource, error) {
+	m.calls++
+	return m.resources, nil
+}
+
+func (m *mockExtensionResources) GroupExtensionResources(_ context.Context, groupID, _, _, _ string) ([]*govapi.ExtensionResource, error) {
+	m.groupCalls++
+	return m.groupResources[groupID], nil
+}
+
+func (m *mockExtensionResources) CreateExtensionResource(_ context.Context, _, _, _ string, resource any) (*govapi.ExtensionResource, error) {
+	m.created = append(m.created, resource)
+
+	return &govapi.ExtensionResource{ID: fmt.Sprintf("new-%d", len(m.created))}, nil
+}
+
+func (m *mockExtensionResources) UpdateExtensionResource(_ context.Context, _, _, _, id string, resource any) (*govapi.ExtensionResource, error) {
+	if m.updated == nil {
+		m.updated = map[string]any{}
+	}
+
+	m.updated[id] = resource
+
+	return &govapi.ExtensionResource{ID: id}, nil
+}
+
+func (m *mockExtensionResources) DeleteExtensionResource(_ context.Context, _, _, _, id string) error {
+	m.deleted = append(m.deleted, id)
+	return nil
+}
+
+func testSettingsReconciler(settings map[string]string) (*Reconciler, *mockExtensionResources) {
+	m := &mockExtensionResources{groupResources: map[string][]*govapi.ExtensionResource{}}
+
+	for groupID, s := range settings {
+		m.groupResources[groupID] = append(m.groupResources[groupID], &govapi.ExtensionResource{ID: "res-" + groupID, Resource: json.RawMessage(s)})
+	}
+
+	return New(WithLogger(zap.NewNop()), func(r *Reconciler) {
+		r.extensions = m
+		r.extensionSlug = "gov-slack-addon"
+	}), m
+}
+
+func Test_parseGroupSettings(t *testing.T) {
+	now := time.Now()
+
+	got := parseGroupSettings(zap.NewNop(), "group-1", []*govapi.ExtensionResource{
+		{ID: "res-1", Resource: json.RawMessage(`{"handle":"team-x"}`), UpdatedAt: now.Add(-time.Hour)},
+		{ID: "res-2", Resource: json.RawMessage(`nope`), UpdatedAt: now},
+		{ID: "res-3", Resource: json.RawMessage(`{"handle":"team-y"}`), UpdatedAt: now},
+		{ID: "res-4", Resource: json.RawMessage(`{"handle":"team-z"}`), UpdatedAt: now.Add(-time.Minute)},
+	})
+
+	if got == nil || got.GroupID != "group-1" || got.Handle != "team-y" {
+		t.Errorf("parseGroupSettings() = %+v, want the most recently updated settings of group-1", got)
+	}
+
+	if got := parseGroupSettings(zap.NewNop(), "group-1", nil); got != nil {
+		t.Errorf("parseGroupSettings() = %+v, want nil without resources", got)
+	}
+}
+
+func TestReconciler_groupSettings(t *testing.T) {
+	r, m := testSettingsReconciler(map[string]string{"group-1": `{"handle":"team-x"}`})
+
+	// a group id in the body can't point the settings at another group
+	m.groupResources["group-2"] = []*govapi.ExtensionResource{
+		{ID: "res-2", Resource: json.RawMessage(`{"group_id":"group-1","handle":"team-y"}`)},
+	}
+
+	if got := r.groupSettings(context.Background(), "group-1"); got == nil || got.Handle != "team-x" {
+		t.Errorf("groupSettings() = %+v, want the settings of group-1", got)
+	}
+
+	if got := r.groupSettings(context.Background(), "group-2"); got == nil || got.GroupID != "group-2" {
+		t.Errorf("groupSettings() = %+v, want the settings of group-2", got)
+	}
+}
+
+func TestReconciler_syncsToWorkspace(t *testing.T) {
+	r, m := testSettingsReconciler(map[string]string{"group-1": `{"workspaces":["ws-a"]}`})
+	ctx := context.Background()
+
+	if !r.syncsToWorkspace(ctx, "group-1", "ws-a") {
+		t.Error("expected group-1 to sync to ws-a")
+	}
+
+	if r.syncsToWorkspace(ctx, "group-1", "ws-b") {
+		t.Error("expected group-1 not to sync to ws-b")
+	}
+
+	if !r.syncsToWorkspace(ctx, "group-2", "ws-b") {
+		t.Error("expected a group without settings to sync to all workspaces")
+	}
+
+	if !r.syncsToWorkspace(ctx, "group-2", "ws-a") {
+		t.Error("expected a group without settings to sync to all workspaces")
+	}
+
+	if m.groupCalls != 2 {
+		t.Errorf("expected the settings to be listed once per group, got %d calls", m.groupCalls)
+	}
+}
+
+func TestGroupSettings_notificationEnabled(t *testing.T) {
+	off := false
+
+	s := &GroupSettings{Notifications: NotificationSettings{Removed: &off}}
+
+	if !s.notificationEnabled(notify.KindAdded) {
+		t.Error("expected unset notifications to be enabled")
+	}
+
+	if s.notificationEnabled(notify.KindRemoved) {
+		t.Error("expected removed notifications to be disabled")
+	}
+
+	var none *GroupSettings
+
+	if !none.notificationEnabled(notify.KindNotInWorkspace) {
+		t.Error("expected notifications to be enabled without settings")
+	}
+}
+
+func TestReconciler_groupSettingsOverrides(t *testing.T) {
+	r, _ := testSettingsReconciler(map[string]string{"group-1": `{"handle":"Team X","default_channels":[]}`})
+
+	r.defaultChannelRules = []DefaultChannels{{Channels: []string{"C0001"}}}
+
+	group := &v1alpha1.Group{ID: "group-1", Slug: "group-one"}
+
+	if got := r.userGroupHandle(context.Background(), group, "app-1", "ws-a"); got != "team-x" {
+		t.Errorf("userGroupHandle() = %q, want the handle from the settings", got)
+	}
+
+	if got := r.defaultChannels(context.Background(), group, "app-1", "ws-a"); got == nil || len(*got) != 0 {
+		t.Errorf("defaultChannels() = %v, want the empty list from the settings", got)
+	}
+
+	other := &v1alpha1.Group{ID: "group-2", Slug: "group-two"}
+
+	if got := r.userGroupHandle(context.Background(), other, "app-1", "ws-a"); got != "group-two" {
+		t.Errorf("userGroupHandle() = %q, want the rendered handle", got)
+	}
+
+	if got := r.defaultChannels(context.Background(), other, "app-1", "ws-a"); got == nil || len(*got) != 1 {
+		t.Errorf("defaultChannels() = %v, want the rule channels", got)
+	}
+}
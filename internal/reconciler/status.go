@@ -0,0 +1,312 @@
+package reconciler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/metal-toolbox/governor-api/pkg/api/v1alpha1"
+	"go.uber.org/zap"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/govapi"
+	"github.com/metal-toolbox/gov-slack-addon/internal/tracing"
+)
+
+const (
+	// StatusERD is the plural slug of the extension resource definition of the user group sync status
+	StatusERD = "slack-usergroup-statuses"
+	// StatusERDVersion is the version of the extension resource definition of the user group sync status
+	StatusERDVersion = "v1alpha1"
+)
+
+// SyncStatus is the sync status of the slack user group of a governor group in a workspace, written
+// to governor as a slack-usergroup-status extension resource so the governor ui can show it
+type SyncStatus struct {
+	GroupID         string            `json:"group_id"`
+	Workspace       string            `json:"workspace"`
+	UserGroupID     string            `json:"usergroup_id,omitempty"`
+	UserGroupHandle string            `json:"usergroup_handle,omitempty"`
+	LastSyncAt      *time.Time        `json:"last_sync_at,omitempty"`
+	Members         int               `json:"members"`
+	SlackMembers    int               `json:"slack_members"`
+	Unmatched       []UnmatchedMember `json:"unmatched_members"`
+	LastError       string            `json:"last_error,omitempty"`
+	LastErrorAt     *time.Time        `json:"last_error_at,omitempty"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+// statusResource is a sync status written to governor and the id of its resource
+type statusResource struct {
+	id     string
+	status SyncStatus
+}
+
+// statusRefreshInterval is how long the sync statuses listed from governor are trusted. Any replica
+// may write them, so they're listed again once stale or when a status is missing.
+const statusRefreshInterval = 10 * time.Minute
+
+// syncStatuses keeps the sync statuses written to governor, by governor group id and workspace. The
+// statuses are written without holding mu, the writes of a status are serialized by the key locks.
+type syncStatuses struct {
+	mu       sync.Mutex
+	listedAt time.Time
+	statuses map[string]*statusResource
+
+	listMu sync.Mutex
+	keys   *groupLocks
+}
+
+func newSyncStatuses() *syncStatuses {
+	return &syncStatuses{statuses: make(map[string]*statusResource), keys: newGroupLocks()}
+}
+
+// get returns a copy of the sync status and whether it's fresh enough to be used without listing the
+// statuses again
+func (s *syncStatuses) get(key string, now time.Time) (*statusResource, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev, ok := s.statuses[key]
+	if !ok || now.Sub(s.listedAt) > statusRefreshInterval {
+		return nil, false
+	}
+
+	c := *prev
+
+	return &c, true
+}
+
+// set records the sync status written to governor, or forgets it if res is nil
+func (s *syncStatuses) set(key string, res *statusResource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if res == nil {
+		delete(s.statuses, key)
+		return
+	}
+
+	s.statuses[key] = res
+}
+
+// statusKey returns the key of the sync status of the governor group in the workspace
+func statusKey(groupID, workspace string) string {
+	return groupID + "/" + workspace
+}
+
+// syncStatus returns the sync status of the key written to governor, or nil if there's none. The
+// statuses are listed from governor again if the status is missing or stale, unless they've been
+// listed since, so concurrent misses share a single listing.
+func (r *Reconciler) syncStatus(ctx context.Context, key string) (*statusResource, error) {
+	missedAt := time.Now()
+
+	if prev, ok := r.statuses.get(key, missedAt); ok {
+		return prev, nil
+	}
+
+	r.statuses.listMu.Lock()
+	defer r.statuses.listMu.Unlock()
+
+	r.statuses.mu.Lock()
+	listed := r.statuses.listedAt.After(missedAt)
+	r.statuses.mu.Unlock()
+
+	if !listed {
+		if err := r.listSyncStatuses(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	r.statuses.mu.Lock()
+	defer r.statuses.mu.Unlock()
+
+	prev, ok := r.statuses.statuses[key]
+	if !ok {
+		return nil, nil
+	}
+
+	c := *prev
+
+	return &c, nil
+}
+
+// listSyncStatuses lists the sync statuses from governor, the statuses lock must not be held
+func (r *Reconciler) listSyncStatuses(ctx context.Context) error {
+	listedAt := time.Now()
+
+	resources, err := r.extensions.ExtensionResources(ctx, r.extensionSlug, StatusERD, StatusERDVersion)
+	if err != nil {
+		return err
+	}
+
+	statuses := make(map[string]*statusResource, len(resources))
+
+	for _, res := range resources {
+		s := SyncStatus{}
+
+		if err := json.Unmarshal(res.Resource, &s); err != nil || s.GroupID == "" {
+			r.Logger.Warn("skipping invalid sync status", zap.String("governor.resource.id", res.ID), zap.Error(err))
+			continue
+		}
+
+		statuses[statusKey(s.GroupID, s.Workspace)] = &statusResource{id: res.ID, status: s}
+	}
+
+	r.statuses.mu.Lock()
+	defer r.statuses.mu.Unlock()
+
+	r.statuses.statuses = statuses
+	r.statuses.listedAt = listedAt
+
+	return nil
+}
+
+// writeSyncStatus writes the sync status of a user group to governor. If the sync failed, the error is
+// recorded along with the details of the last successful sync. Nothing is written if only the sync
+// times changed.
+func (r *Reconciler) writeSyncStatus(ctx context.Context, status *SyncStatus, syncErr error) {
+	if r.extensions == nil || status == nil {
+		return
+	}
+
+	logger := tracing.Logger(ctx, r.Logger).With(zap.String("governor.group.id", status.GroupID), zap.String("slack.workspace.name", status.Workspace))
+
+	if r.dryrun {
+		logger.Info("SKIP writing user group sync status", zap.Any("status", status), zap.Error(syncErr))
+		return
+	}
+
+	key := statusKey(status.GroupID, status.Workspace)
+
+	unlock := r.statuses.keys.lock(key)
+	defer unlock()
+
+	prev, err := r.syncStatus(ctx, key)
+	if err != nil {
+		logger.Error("failed to list user group sync statuses", zap.Error(err))
+		return
+	}
+
+	next := mergeSyncStatus(prev, status, syncErr, time.Now().UTC())
+
+	if prev != nil {
+		if sameSyncStatus(prev.status, next) {
+			return
+		}
+
+		_, err := r.extensions.UpdateExtensionResource(ctx, r.extensionSlug, StatusERD, StatusERDVersion, prev.id, next)
+		if err == nil {
+			r.statuses.set(key, &statusResource{id: prev.id, status: next})
+			return
+		}
+
+		if !errors.Is(err, govapi.ErrNotFound) {
+			logger.Error("failed to update user group sync status", zap.Error(err))
+			return
+		}
+
+		// the status was deleted since it was listed, e.g. by another replica
+		logger.Debug("user group sync status not found, creating it", zap.String("governor.resource.id", prev.id))
+		r.statuses.set(key, nil)
+	}
+
+	res, err := r.extensions.CreateExtensionResource(ctx, r.extensionSlug, StatusERD, StatusERDVersion, next)
+	if err != nil {
+		logger.Error("failed to create user group sync status", zap.Error(err))
+		return
+	}
+
+	r.statuses.set(key, &statusResource{id: res.ID, status: next})
+}
+
+// sameSyncStatus returns true if the sync statuses only differ by their sync and update times
+func sameSyncStatus(a, b SyncStatus) bool {
+	a.LastSyncAt, b.LastSyncAt = nil, nil
+	a.LastErrorAt, b.LastErrorAt = nil, nil
+	a.UpdatedAt, b.UpdatedAt = time.Time{}, time.Time{}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// memberEventStatus returns the sync status of the user group of the governor group after a member was
+// added or removed, with the unmatched members of the last reconciliation
+func (r *Reconciler) memberEventStatus(group *v1alpha1.Group, appID, workspace string, ug *UserGroup, users []string) *SyncStatus {
+	return &SyncStatus{
+		GroupID:         group.ID,
+		Workspace:       workspace,
+		UserGroupID:     ug.ID,
+		UserGroupHandle: ug.Handle,
+		Members:         len(group.Members),
+		SlackMembers:    len(users),
+		Unmatched:       r.unmatched.get(group.ID, appID).Members,
+	}
+}
+
+// deleteSyncStatus deletes the sync status of the user group of the governor group in the workspace
+func (r *Reconciler) deleteSyncStatus(ctx context.Context, groupID, workspace string) {
+	if r.extensions == nil || r.dryrun {
+		return
+	}
+
+	logger := tracing.Logger(ctx, r.Logger).With(zap.String("governor.group.id", groupID), zap.String("slack.workspace.name", workspace))
+
+	key := statusKey(groupID, workspace)
+
+	unlock := r.statuses.keys.lock(key)
+	defer unlock()
+
+	prev, err := r.syncStatus(ctx, key)
+	if err != nil {
+		logger.Error("failed to list user group sync statuses", zap.Error(err))
+		return
+	}
+
+	if prev == nil {
+		return
+	}
+
+	if err := r.extensions.DeleteExtensionResource(ctx, r.extensionSlug, StatusERD, StatusERDVersion, prev.id); err != nil && !errors.Is(err, govapi.ErrNotFound) {
+		logger.Error("failed to delete user group sync status", zap.Error(err))
+		return
+	}
+
+	r.statuses.set(key, nil)
+}
+
+// mergeSyncStatus returns the status to write. A failed sync keeps the details of the previous status
+// and only records the error, and a successful sync clears it.
+func mergeSyncStatus(prev *statusResource, status *SyncStatus, syncErr error, now time.Time) SyncStatus {
+	next := *status
+
+	if syncErr != nil {
+		if prev != nil {
+			next = prev.status
+		}
+
+		next.GroupID = status.GroupID
+		next.Workspace = status.Workspace
+
+		if status.UserGroupID != "" {
+			next.UserGroupID = status.UserGroupID
+			next.UserGroupHandle = status.UserGroupHandle
+		}
+
+		next.LastError = syncErr.Error()
+		next.LastErrorAt = &now
+	} else {
+		next.LastSyncAt = &now
+		next.LastError = ""
+		next.LastErrorAt = nil
+	}
+
+	if next.Unmatched == nil {
+		next.Unmatched = []UnmatchedMember{}
+	}
+
+	next.UpdatedAt = now
+
+	return next
+}
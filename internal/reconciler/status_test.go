@@ -0,0 +1,159 @@
+package reconciler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/govapi"
+)
+
+func Test_mergeSyncStatus(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	synced := now.Add(-time.Hour)
+
+	prev := &statusResource{id: "res-1", status: SyncStatus{
+		GroupID:      "group-1",
+		Workspace:    "ws-a",
+		UserGroupID:  "S1",
+		LastSyncAt:   &synced,
+		Members:      3,
+		SlackMembers: 2,
+		Unmatched:    []UnmatchedMember{{Email: "nope@example.com", Reason: UnmatchedNoSlackAccount}},
+	}}
+
+	t.Run("failed sync keeps the previous details", func(t *testing.T) {
+		got := mergeSyncStatus(prev, &SyncStatus{GroupID: "group-1", Workspace: "ws-a"}, errors.New("boom"), now) //nolint:err113
+
+		if got.LastError != "boom" || got.LastErrorAt == nil || !got.LastErrorAt.Equal(now) {
+			t.Errorf("expected error to be recorded, got %+v", got)
+		}
+
+		if got.LastSyncAt == nil || !got.LastSyncAt.Equal(synced) || got.Members != 3 || len(got.Unmatched) != 1 {
+			t.Errorf("expected previous details to be kept, got %+v", got)
+		}
+	})
+
+	t.Run("successful sync clears the error", func(t *testing.T) {
+		failed := *prev
+		failed.status.LastError = "boom"
+		failed.status.LastErrorAt = &synced
+
+		got := mergeSyncStatus(&failed, &SyncStatus{GroupID: "group-1", Workspace: "ws-a", Members: 4, SlackMembers: 4}, nil, now)
+
+		if got.LastError != "" || got.LastErrorAt != nil {
+			t.Errorf("expected error to be cleared, got %+v", got)
+		}
+
+		if got.LastSyncAt == nil || !got.LastSyncAt.Equal(now) || got.Members != 4 {
+			t.Errorf("expected new details, got %+v", got)
+		}
+
+		if got.Unmatched == nil {
+			t.Error("expected unmatched members to be an empty list")
+		}
+	})
+
+	t.Run("failed first sync", func(t *testing.T) {
+		got := mergeSyncStatus(nil, &SyncStatus{GroupID: "group-2", Workspace: "ws-a"}, errors.New("boom"), now) //nolint:err113
+
+		if got.GroupID != "group-2" || got.LastSyncAt != nil || got.LastError != "boom" {
+			t.Errorf("unexpected status %+v", got)
+		}
+	})
+}
+
+func TestReconciler_writeSyncStatus(t *testing.T) {
+	r, m := testSettingsReconciler(nil)
+	ctx := context.Background()
+
+	r.writeSyncStatus(ctx, &SyncStatus{GroupID: "group-1", Workspace: "ws-a", Members: 1}, nil)
+
+	if len(m.created) != 1 || len(m.updated) != 0 {
+		t.Fatalf("expected the status to be created, got %d created and %d updated", len(m.created), len(m.updated))
+	}
+
+	r.writeSyncStatus(ctx, &SyncStatus{GroupID: "group-1", Workspace: "ws-a", Members: 2}, nil)
+
+	if len(m.created) != 1 || len(m.updated) != 1 {
+		t.Fatalf("expected the status to be updated, got %d created and %d updated", len(m.created), len(m.updated))
+	}
+
+	if got, ok := m.updated["new-1"].(SyncStatus); !ok || got.Members != 2 {
+		t.Errorf("unexpected updated status %+v", m.updated["new-1"])
+	}
+
+	// only the sync time changed
+	r.writeSyncStatus(ctx, &SyncStatus{GroupID: "group-1", Workspace: "ws-a", Members: 2}, nil)
+
+	if len(m.created) != 1 || len(m.updated) != 1 {
+		t.Fatalf("expected the unchanged status not to be written, got %d created and %d updated", len(m.created), len(m.updated))
+	}
+
+	if m.calls != 1 {
+		t.Errorf("expected sync statuses to be listed once, got %d", m.calls)
+	}
+
+	r.deleteSyncStatus(ctx, "group-1", "ws-a")
+	r.deleteSyncStatus(ctx, "group-1", "ws-b")
+
+	if len(m.deleted) != 1 || m.deleted[0] != "new-1" {
+		t.Errorf("expected only the known status to be deleted, got %v", m.deleted)
+	}
+
+	// the missing status was listed again in case another replica wrote it
+	if m.calls != 2 {
+		t.Errorf("expected sync statuses to be listed twice, got %d", m.calls)
+	}
+}
+
+func TestReconciler_writeSyncStatusWrittenElsewhere(t *testing.T) {
+	r, m := testSettingsReconciler(nil)
+	ctx := context.Background()
+
+	r.writeSyncStatus(ctx, &SyncStatus{GroupID: "group-1", Workspace: "ws-a"}, nil)
+
+	// another replica wrote the status of group-2 after the statuses were listed
+	m.resources = append(m.resources, &govapi.ExtensionResource{ID: "res-2", Resource: json.RawMessage(`{"group_id":"group-2","workspace":"ws-a"}`)})
+
+	r.writeSyncStatus(ctx, &SyncStatus{GroupID: "group-2", Workspace: "ws-a", Members: 1}, nil)
+
+	if len(m.created) != 1 {
+		t.Errorf("expected no duplicate status to be created, got %d created", len(m.created))
+	}
+
+	if _, ok := m.updated["res-2"]; !ok {
+		t.Errorf("expected the status written elsewhere to be updated, got %v", m.updated)
+	}
+}
+
+func Test_sameSyncStatus(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Minute)
+
+	a := SyncStatus{GroupID: "group-1", Members: 1, LastSyncAt: &now, UpdatedAt: now, Unmatched: []UnmatchedMember{}}
+	b := SyncStatus{GroupID: "group-1", Members: 1, LastSyncAt: &later, UpdatedAt: later, Unmatched: []UnmatchedMember{}}
+
+	if !sameSyncStatus(a, b) {
+		t.Error("expected statuses only differing by their times to be the same")
+	}
+
+	b.Members = 2
+
+	if sameSyncStatus(a, b) {
+		t.Error("expected statuses with different members to differ")
+	}
+}
+
+func TestReconciler_writeSyncStatusDryRun(t *testing.T) {
+	r, m := testSettingsReconciler(nil)
+	r.dryrun = true
+
+	r.writeSyncStatus(context.Background(), &SyncStatus{GroupID: "group-1", Workspace: "ws-a"}, nil)
+
+	if len(m.created) != 0 || m.calls != 0 {
+		t.Error("expected nothing to be written in dry-run")
+	}
+}
@@ -0,0 +1,257 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/tracing"
+)
+
+// UnmatchedReason is the reason a governor group member couldn't be matched to a slack user
+type UnmatchedReason string
+
+const (
+	// UnmatchedNoSlackAccount is used when no slack user was found for the member
+	UnmatchedNoSlackAccount UnmatchedReason = "no_slack_account"
+	// UnmatchedDeactivated is used when the matching slack user is deactivated
+	UnmatchedDeactivated UnmatchedReason = "deactivated"
+	// UnmatchedNotInWorkspace is used when the slack user hasn't joined the workspace
+	UnmatchedNotInWorkspace UnmatchedReason = "not_in_workspace"
+	// UnmatchedPending is used when the member has a pending status in governor
+	UnmatchedPending UnmatchedReason = "pending"
+
+	// maxUnmatchedReportLength is a safe length for a slack message, which is limited to 40k characters
+	maxUnmatchedReportLength = 30000
+)
+
+// UnmatchedMember is a governor group member that couldn't be matched to a slack user
+type UnmatchedMember struct {
+	GovernorUserID string          `json:"governor_user_id"`
+	Name           string          `json:"name"`
+	Email          string          `json:"email"`
+	SlackUserID    string          `json:"slack_user_id,omitempty"`
+	Reason         UnmatchedReason `json:"reason"`
+}
+
+// UnmatchedReport lists the unmatched members of a governor group in a slack workspace
+type UnmatchedReport struct {
+	Workspace         string            `json:"workspace"`
+	GovernorAppID     string            `json:"governor_app_id"`
+	GovernorGroupID   string            `json:"governor_group_id"`
+	GovernorGroupSlug string            `json:"governor_group_slug"`
+	UserGroupName     string            `json:"usergroup_name"`
+	Members           []UnmatchedMember `json:"members"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+}
+
+// unmatchedReports keeps the latest unmatched report for each governor group and application
+type unmatchedReports struct {
+	mu      sync.RWMutex
+	reports map[string]UnmatchedReport
+}
+
+func newUnmatchedReports() *unmatchedReports {
+	return &unmatchedReports{
+		reports: make(map[string]UnmatchedReport),
+	}
+}
+
+// set records the report, or clears it if all the members were matched
+func (u *unmatchedReports) set(report *UnmatchedReport) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	key := report.GovernorGroupID + "/" + report.GovernorAppID
+
+	if len(report.Members) == 0 {
+		delete(u.reports, key)
+		return
+	}
+
+	u.reports[key] = *report
+}
+
+// newlyNotInWorkspace returns the slack users of the report that haven't joined the workspace and
+// weren't in the previous report of the group
+func (u *unmatchedReports) newlyNotInWorkspace(report *UnmatchedReport) []string {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	known := map[string]bool{}
+
+	if prev, ok := u.reports[report.GovernorGroupID+"/"+report.GovernorAppID]; ok {
+		for _, m := range prev.Members {
+			if m.Reason == UnmatchedNotInWorkspace {
+				known[m.SlackUserID] = true
+			}
+		}
+	}
+
+	out := []string{}
+
+	for _, m := range report.Members {
+		if m.Reason == UnmatchedNotInWorkspace && m.SlackUserID != "" && !known[m.SlackUserID] {
+			out = append(out, m.SlackUserID)
+		}
+	}
+
+	return out
+}
+
+// get returns the report for the given governor group and application, it has no members if there's none
+func (u *unmatchedReports) get(groupID, appID string) UnmatchedReport {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	return u.reports[groupID+"/"+appID]
+}
+
+// clear removes the report for the given governor group and application
+func (u *unmatchedReports) clear(groupID, appID string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	delete(u.reports, groupID+"/"+appID)
+}
+
+// list returns all the reports sorted by workspace and group slug
+func (u *unmatchedReports) list() []UnmatchedReport {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	out := make([]UnmatchedReport, 0, len(u.reports))
+	for _, r := range u.reports {
+		out = append(out, r)
+	}
+
+	sortUnmatchedReports(out)
+
+	return out
+}
+
+// UnmatchedReports returns the unmatched members recorded by the latest reconciliation of each group
+func (r *Reconciler) UnmatchedReports() []UnmatchedReport {
+	return r.unmatched.list()
+}
+
+// UnmatchedMembers looks up the unmatched members of a governor group in the slack workspace of the
+// given application without making any changes in slack. A nil report is returned if the application
+// isn't a slack application.
+func (r *Reconciler) UnmatchedMembers(ctx context.Context, groupID, appID string) (*UnmatchedReport, error) {
+	if groupID == "" || appID == "" {
+		return nil, ErrBadParameter
+	}
+
+	isSlack, workspace, err := r.isSlackApplication(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isSlack {
+		return nil, nil
+	}
+
+	logger := tracing.Logger(ctx, r.Logger).With(zap.String("slack.workspace.name", workspace), zap.String("governor.app.id", appID))
+
+	group, err := r.GovernorClient.Group(ctx, groupID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := r.GovernorClient.GroupMembers(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	teamID, err := r.userGroupTeamID(ctx, workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	// the user group isn't looked up, so the rules excluding users from a single user group don't apply
+	members = r.exclusions(group, workspace, nil).withoutIgnored(logger, members)
+
+	_, unmatched, err := r.matchSlackUsers(ctx, logger, teamID, members)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UnmatchedReport{
+		Workspace:         workspace,
+		GovernorAppID:     appID,
+		GovernorGroupID:   group.ID,
+		GovernorGroupSlug: group.Slug,
+		UserGroupName:     r.userGroupName(group, appID, workspace),
+		Members:           unmatched,
+		UpdatedAt:         time.Now().UTC(),
+	}, nil
+}
+
+// postUnmatchedReport posts the recorded unmatched members to the configured slack channel, if the
+// report interval has elapsed since the last post
+func (r *Reconciler) postUnmatchedReport(ctx context.Context) {
+	if r.unmatchedReportChannel == "" || time.Since(r.unmatchedReportPostedAt) < r.unmatchedReportInterval {
+		return
+	}
+
+	reports := r.UnmatchedReports()
+	if len(reports) == 0 {
+		r.unmatchedReportPostedAt = time.Now()
+		return
+	}
+
+	if r.dryrun {
+		r.Logger.Info("SKIP posting unmatched members report", zap.String("slack.channel.id", r.unmatchedReportChannel))
+		r.unmatchedReportPostedAt = time.Now()
+
+		return
+	}
+
+	if _, err := r.Client.PostMessage(ctx, r.unmatchedReportChannel, FormatUnmatchedReports(reports)); err != nil {
+		r.Logger.Error("failed to post unmatched members report", zap.String("slack.channel.id", r.unmatchedReportChannel), zap.Error(err))
+		return
+	}
+
+	r.unmatchedReportPostedAt = time.Now()
+}
+
+// FormatUnmatchedReports renders the reports as a plain text message suitable for posting to slack
+func FormatUnmatchedReports(reports []UnmatchedReport) string {
+	var b strings.Builder
+
+	b.WriteString("*Governor group members that could not be matched to Slack users*\n")
+
+	for _, rep := range reports {
+		line := fmt.Sprintf("\n*%s* / %s (%d)\n", rep.Workspace, rep.GovernorGroupSlug, len(rep.Members))
+
+		for _, m := range rep.Members {
+			line += fmt.Sprintf("• %s: %s\n", m.Email, m.Reason)
+		}
+
+		if b.Len()+len(line) > maxUnmatchedReportLength {
+			b.WriteString("\n_report truncated_\n")
+			break
+		}
+
+		b.WriteString(line)
+	}
+
+	return b.String()
+}
+
+// sortUnmatchedReports sorts the reports by workspace and governor group slug
+func sortUnmatchedReports(reports []UnmatchedReport) {
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Workspace != reports[j].Workspace {
+			return reports[i].Workspace < reports[j].Workspace
+		}
+
+		return reports[i].GovernorGroupSlug < reports[j].GovernorGroupSlug
+	})
+}
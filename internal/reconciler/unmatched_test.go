@@ -0,0 +1,104 @@
+package reconciler
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_unmatchedReports(t *testing.T) {
+	u := newUnmatchedReports()
+
+	u.set(&UnmatchedReport{
+		Workspace:         "workspace-b",
+		GovernorAppID:     "app-b",
+		GovernorGroupID:   "group-1",
+		GovernorGroupSlug: "group-1",
+		Members:           []UnmatchedMember{{Email: "user1@example.com", Reason: UnmatchedNoSlackAccount}},
+	})
+
+	u.set(&UnmatchedReport{
+		Workspace:         "workspace-a",
+		GovernorAppID:     "app-a",
+		GovernorGroupID:   "group-2",
+		GovernorGroupSlug: "group-2",
+		Members:           []UnmatchedMember{{Email: "user2@example.com", Reason: UnmatchedPending}},
+	})
+
+	got := u.list()
+	if len(got) != 2 {
+		t.Fatalf("expected 2 reports, got %d", len(got))
+	}
+
+	if got[0].Workspace != "workspace-a" || got[1].Workspace != "workspace-b" {
+		t.Errorf("expected reports sorted by workspace, got %s, %s", got[0].Workspace, got[1].Workspace)
+	}
+
+	// a report without members clears the previous one
+	u.set(&UnmatchedReport{GovernorAppID: "app-b", GovernorGroupID: "group-1"})
+
+	if got := u.list(); len(got) != 1 || got[0].GovernorGroupID != "group-2" {
+		t.Errorf("expected only group-2 report, got %v", got)
+	}
+
+	u.clear("group-2", "app-a")
+
+	if got := u.list(); len(got) != 0 {
+		t.Errorf("expected no reports, got %v", got)
+	}
+}
+
+func Test_unmatchedReports_newlyNotInWorkspace(t *testing.T) {
+	u := newUnmatchedReports()
+
+	report := &UnmatchedReport{
+		GovernorGroupID: "group-1",
+		GovernorAppID:   "app-a",
+		Members: []UnmatchedMember{
+			{Email: "user1@example.com", SlackUserID: "U0001", Reason: UnmatchedNotInWorkspace},
+			{Email: "user2@example.com", SlackUserID: "U0002", Reason: UnmatchedDeactivated},
+		},
+	}
+
+	if got := u.newlyNotInWorkspace(report); len(got) != 1 || got[0] != "U0001" {
+		t.Errorf("expected U0001 to be new, got %v", got)
+	}
+
+	u.set(report)
+
+	next := &UnmatchedReport{
+		GovernorGroupID: "group-1",
+		GovernorAppID:   "app-a",
+		Members: append(report.Members,
+			UnmatchedMember{Email: "user3@example.com", SlackUserID: "U0003", Reason: UnmatchedNotInWorkspace},
+		),
+	}
+
+	if got := u.newlyNotInWorkspace(next); len(got) != 1 || got[0] != "U0003" {
+		t.Errorf("expected only U0003 to be new, got %v", got)
+	}
+}
+
+func TestFormatUnmatchedReports(t *testing.T) {
+	reports := []UnmatchedReport{
+		{
+			Workspace:         "workspace-a",
+			GovernorGroupSlug: "group-1",
+			Members: []UnmatchedMember{
+				{Email: "user1@example.com", Reason: UnmatchedNoSlackAccount},
+				{Email: "user2@example.com", Reason: UnmatchedDeactivated},
+			},
+		},
+	}
+
+	got := FormatUnmatchedReports(reports)
+
+	for _, want := range []string{
+		"*workspace-a* / group-1 (2)",
+		"user1@example.com: no_slack_account",
+		"user2@example.com: deactivated",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("FormatUnmatchedReports() = %q, expected to contain %q", got, want)
+		}
+	}
+}
@@ -4,12 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
-	"github.com/metal-toolbox/gov-slack-addon/internal/auctx"
+	"github.com/metal-toolbox/gov-slack-addon/internal/identity"
+	"github.com/metal-toolbox/gov-slack-addon/internal/notify"
 	"github.com/metal-toolbox/gov-slack-addon/internal/slack"
+	"github.com/metal-toolbox/gov-slack-addon/internal/tracing"
 	"github.com/metal-toolbox/governor-api/pkg/api/v1alpha1"
+	slackgo "github.com/slack-go/slack"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -20,6 +26,9 @@ type UserGroup struct {
 	Handle      string
 	Description string
 	Users       []string
+	Channels    []string
+	Disabled    bool
+	UpdatedBy   string
 }
 
 // AddUserGroupMember adds a user to a user group if they are not already a member
@@ -28,6 +37,12 @@ func (r *Reconciler) AddUserGroupMember(ctx context.Context, groupID, userID str
 		return ErrBadParameter
 	}
 
+	ctx, unlock := r.lockGroup(ctx, groupID)
+	defer unlock()
+
+	// the group is reconciled again in the next pass, whether or not the event is processed
+	r.fingerprints.forget(groupID)
+
 	group, err := r.GovernorClient.Group(ctx, groupID, false)
 	if err != nil {
 		r.Logger.Error("error getting governor group", zap.String("governor.group.id", groupID), zap.Error(err))
@@ -59,6 +74,8 @@ func (r *Reconciler) AddUserGroupMember(ctx context.Context, groupID, userID str
 		return nil
 	}
 
+	handled := false
+
 	// check all applications linked to the group and add group member if a slack application
 	for _, appID := range group.Applications {
 		isSlack, workspace, err := r.isSlackApplication(ctx, appID)
@@ -72,7 +89,19 @@ func (r *Reconciler) AddUserGroupMember(ctx context.Context, groupID, userID str
 			continue
 		}
 
-		logger := r.Logger.With(
+		if !r.syncsToWorkspace(ctx, group.ID, workspace) {
+			r.Logger.Debug("workspace not in the group settings, skipping", zap.String("governor.group.id", group.ID), zap.String("slack.workspace.name", workspace))
+			continue
+		}
+
+		// in org-wide mode all the slack applications share a single user group
+		if handled && r.orgWide() {
+			break
+		}
+
+		handled = true
+
+		logger := tracing.Logger(ctx, r.Logger).With(
 			zap.String("slack.workspace.name", workspace),
 			zap.String("governor.app.id", appID),
 			zap.String("governor.group.id", group.ID),
@@ -81,19 +110,24 @@ func (r *Reconciler) AddUserGroupMember(ctx context.Context, groupID, userID str
 			zap.String("governor.user.email", user.Email),
 		)
 
-		teamID, err := r.teamIDFromName(ctx, workspace)
+		teamID, err := r.userGroupTeamID(ctx, workspace)
 		if err != nil {
 			logger.Error("failed to get workspace id", zap.Error(err))
 			continue
 		}
 
-		ug, err := r.userGroupFromName(ctx, r.userGroupName(group.Name), teamID, false)
+		ug, err := r.userGroup(ctx, group, appID, workspace, teamID)
 		if err != nil {
 			logger.Error("failed to get slack user group", zap.Error(err))
 			continue
 		}
 
-		u, err := r.Client.GetUserByEmail(ctx, user.Email)
+		if r.exclusions(group, workspace, ug).ignored(user.ID, user.Email) {
+			logger.Info("user excluded from sync, skipping")
+			continue
+		}
+
+		u, err := r.identity.Resolve(ctx, identity.User{ID: user.ID, Email: user.Email})
 		if err != nil {
 			logger.Error("failed to get slack user", zap.Error(err))
 			continue
@@ -109,31 +143,41 @@ func (r *Reconciler) AddUserGroupMember(ctx context.Context, groupID, userID str
 
 		logger.Debug("updating user group members", zap.Any("slack.usergroup.existing", ug.Users), zap.Any("slack.usergroup.new", newUsers))
 
+		target := map[string]string{
+			"slack.workspace.name": workspace,
+			"slack.usergroup.name": ug.Name,
+			"slack.usergroup.id":   ug.ID,
+			"slack.user.id":        u.ID,
+			"governor.app.id":      appID,
+			"governor.group.id":    group.ID,
+			"governor.group.slug":  group.Slug,
+			"governor.user.id":     userID,
+			"governor.user.email":  user.Email,
+		}
+		r.emails.set(u.ID, user.Email)
+		diff := r.withEmails(ctx, newListDiff(ug.Users, newUsers))
+
 		if r.dryrun {
 			logger.Info("SKIP adding user to group", zap.Any("slack.usergroup", *ug))
+			r.audit(ctx, logger, "UserGroupAddMember", target, diff, nil)
+
 			continue
 		}
 
 		_, err = r.Client.UpdateUserGroupMembers(ctx, ug.ID, teamID, newUsers)
 		if err != nil {
-			logger.Error("failed to create user group", zap.String("slack.usergroup.name", r.userGroupName(group.Name)), zap.Error(err))
+			logger.Error("failed to create user group", zap.String("slack.usergroup.name", r.userGroupName(group, appID, workspace)), zap.Error(err))
+			r.audit(ctx, logger, "UserGroupAddMember", target, diff, err)
+			r.writeSyncStatus(ctx, r.memberEventStatus(group, appID, workspace, ug, ug.Users), err)
+
 			return err
 		}
 
 		logger.Info("added user to group")
 
-		if err := auctx.WriteAuditEvent(ctx, r.auditEventWriter, "UserGroupAddMember", map[string]string{
-			"slack.workspace.name": workspace,
-			"slack.usergroup.name": ug.Name,
-			"slack.usergroup.id":   ug.ID,
-			"slack.user.id":        u.ID,
-			"governor.app.id":      appID,
-			"governor.group.id":    group.ID,
-			"governor.group.slug":  group.Slug,
-			"governor.user.id":     userID,
-		}); err != nil {
-			logger.Error("error writing audit event", zap.Error(err))
-		}
+		r.writeSyncStatus(ctx, r.memberEventStatus(group, appID, workspace, ug, newUsers), nil)
+		r.audit(ctx, logger, "UserGroupAddMember", target, diff, nil)
+		r.notify(ctx, membershipNotification(notify.KindAdded, u.ID, workspace, ug, group))
 	}
 
 	return nil
@@ -146,6 +190,9 @@ func (r *Reconciler) CreateUserGroup(ctx context.Context, groupID, appID string)
 		return ErrBadParameter
 	}
 
+	ctx, unlock := r.lockGroup(ctx, groupID)
+	defer unlock()
+
 	isSlack, workspace, err := r.isSlackApplication(ctx, appID)
 	if err != nil {
 		r.Logger.Error("failed to get application from governor", zap.String("governor.app.id", appID), zap.Error(err))
@@ -157,9 +204,14 @@ func (r *Reconciler) CreateUserGroup(ctx context.Context, groupID, appID string)
 		return nil
 	}
 
-	logger := r.Logger.With(zap.String("slack.workspace.name", workspace), zap.String("governor.app.id", appID))
+	if !r.syncsToWorkspace(ctx, groupID, workspace) {
+		r.Logger.Debug("workspace not in the group settings, skipping", zap.String("governor.group.id", groupID), zap.String("slack.workspace.name", workspace))
+		return nil
+	}
 
-	teamID, err := r.teamIDFromName(ctx, workspace)
+	logger := tracing.Logger(ctx, r.Logger).With(zap.String("slack.workspace.name", workspace), zap.String("governor.app.id", appID))
+
+	teamID, err := r.userGroupTeamID(ctx, workspace)
 	if err != nil {
 		return err
 	}
@@ -170,31 +222,73 @@ func (r *Reconciler) CreateUserGroup(ctx context.Context, groupID, appID string)
 		return err
 	}
 
+	// the user group may already exist, either bound in the mapping or found by name
+	existing, err := r.userGroup(ctx, group, appID, workspace, teamID)
+	if err == nil {
+		// the org-wide user group is shared with the other workspaces linked to the group
+		if r.orgWide() {
+			logger.Debug("org-wide user group already exists", zap.String("slack.usergroup.id", existing.ID))
+			return nil
+		}
+
+		return slack.ErrSlackGroupAlreadyExists
+	}
+
+	if !errors.Is(err, ErrSlackUserGroupNotFound) {
+		return err
+	}
+
+	// bind an existing user group instead of creating one if an adopt rule is configured
+	adopted, err := r.adoptFromRules(ctx, group, appID, workspace, teamID)
+	if err != nil {
+		logger.Error("failed to adopt user group", zap.Error(err))
+		return err
+	}
+
+	if adopted {
+		return nil
+	}
+
+	req := r.userGroupReq(ctx, group, appID, workspace)
+	target := map[string]string{
+		"slack.workspace.name":   workspace,
+		"slack.usergroup.name":   *req.Name,
+		"slack.usergroup.handle": *req.Handle,
+		"governor.app.id":        appID,
+		"governor.group.id":      groupID,
+	}
+
 	if r.dryrun {
-		logger.Info("SKIP creating slack user group", zap.String("slack.usergroup.name", r.userGroupName(group.Name)))
+		logger.Info("SKIP creating slack user group", zap.String("slack.usergroup.name", *req.Name))
+		r.audit(ctx, logger, "UserGroupCreate", target, userGroupDiff(nil, req), nil)
+
 		return nil
 	}
 
-	ug, err := r.Client.CreateUserGroup(ctx, teamID, r.userGroupReq(group))
+	ug, err := r.createUserGroup(ctx, logger, teamID, workspace, req)
 	if err != nil {
 		if !errors.Is(err, slack.ErrSlackGroupAlreadyExists) {
-			logger.Error("failed to create user group", zap.String("slack.usergroup.name", r.userGroupName(group.Name)), zap.Error(err))
+			logger.Error("failed to create user group", zap.String("slack.usergroup.name", *req.Name), zap.Error(err))
+			r.audit(ctx, logger, "UserGroupCreate", target, userGroupDiff(nil, req), err)
 		}
 
 		return err
 	}
 
+	r.putMapping(ctx, logger, group.ID, appID, teamID, ug.ID, ug.Name, ug.Handle)
+
 	logger.Info("created user group", zap.Any("slack.usergroup", ug))
 
-	if err := auctx.WriteAuditEvent(ctx, r.auditEventWriter, "UserGroupCreate", map[string]string{
-		"slack.workspace.name": workspace,
-		"slack.usergroup.name": ug.Name,
-		"slack.usergroup.id":   ug.ID,
-		"governor.app.id":      appID,
-		"governor.group.id":    groupID,
-	}); err != nil {
-		logger.Error("error writing audit event", zap.Error(err))
-	}
+	target["slack.usergroup.name"] = ug.Name
+	target["slack.usergroup.id"] = ug.ID
+	target["slack.usergroup.handle"] = ug.Handle
+
+	r.audit(ctx, logger, "UserGroupCreate", target, userGroupDiff(nil, &slack.UserGroupReq{
+		Name:        &ug.Name,
+		Handle:      &ug.Handle,
+		Description: &ug.Description,
+		Channels:    &ug.Prefs.Channels,
+	}), nil)
 
 	return nil
 }
@@ -207,6 +301,12 @@ func (r *Reconciler) DeleteUserGroup(ctx context.Context, groupID, appID string)
 		return ErrBadParameter
 	}
 
+	ctx, unlock := r.lockGroup(ctx, groupID)
+	defer unlock()
+
+	// the group is reconciled again in the next pass, whether or not the event is processed
+	r.fingerprints.forget(groupID)
+
 	isSlack, workspace, err := r.isSlackApplication(ctx, appID)
 	if err != nil {
 		r.Logger.Error("failed to get application from governor", zap.String("governor.app.id", appID), zap.Error(err))
@@ -218,7 +318,7 @@ func (r *Reconciler) DeleteUserGroup(ctx context.Context, groupID, appID string)
 		return nil
 	}
 
-	logger := r.Logger.With(zap.String("slack.workspace.name", workspace), zap.String("governor.app.id", appID))
+	logger := tracing.Logger(ctx, r.Logger).With(zap.String("slack.workspace.name", workspace), zap.String("governor.app.id", appID))
 
 	group, err := r.GovernorClient.Group(ctx, groupID, true)
 	if err != nil {
@@ -226,19 +326,34 @@ func (r *Reconciler) DeleteUserGroup(ctx context.Context, groupID, appID string)
 		return err
 	}
 
-	teamID, err := r.teamIDFromName(ctx, workspace)
-	if err != nil {
-		return err
+	if r.orgWide() {
+		others, err := r.otherSlackApplications(ctx, group, appID)
+		if err != nil {
+			logger.Error("failed to get application from governor", zap.Error(err))
+			return err
+		}
+
+		// slack doesn't provide a way to detach an org-wide user group from a workspace
+		if len(others) > 0 {
+			logger.Warn("org-wide user group is still linked to other workspaces and must be removed from this workspace manually",
+				zap.String("slack.usergroup.name", r.userGroupName(group, appID, workspace)),
+				zap.Strings("slack.workspace.names", others),
+			)
+
+			r.unmatched.clear(groupID, appID)
+
+			return nil
+		}
 	}
 
-	ug, err := r.userGroupFromName(ctx, r.userGroupName(group.Name), teamID, false)
+	teamID, err := r.userGroupTeamID(ctx, workspace)
 	if err != nil {
 		return err
 	}
 
-	if r.dryrun {
-		logger.Info("SKIP deleting slack user group", zap.Any("slack.usergroup", *ug))
-		return nil
+	ug, err := r.userGroup(ctx, group, appID, workspace, teamID)
+	if err != nil {
+		return err
 	}
 
 	// we'll rename the group first to avoid future conflicts, and then disable it,
@@ -249,17 +364,40 @@ func (r *Reconciler) DeleteUserGroup(ctx context.Context, groupID, appID string)
 	handleR := fmt.Sprintf("%s-deleted-%s", ug.Handle, ts)
 	descriptionR := fmt.Sprintf("%s (deleted by gov-slack-addon %s)", ug.Description, ts)
 
-	if _, err := r.Client.UpdateUserGroup(ctx, ug.ID, teamID, slack.UserGroupReq{
+	req := slack.UserGroupReq{
 		Name:        &nameR,
 		Handle:      &handleR,
 		Description: &descriptionR,
-	}); err != nil {
+	}
+
+	target := map[string]string{
+		"slack.workspace.name": workspace,
+		"slack.usergroup.name": ug.Name,
+		"slack.usergroup.id":   ug.ID,
+		"governor.app.id":      appID,
+		"governor.group.id":    groupID,
+	}
+
+	diff := userGroupDiff(ug, &req)
+	diff["disabled"] = valueDiff{Old: "false", New: "true"}
+
+	if r.dryrun {
+		logger.Info("SKIP deleting slack user group", zap.Any("slack.usergroup", *ug))
+		r.audit(ctx, logger, "UserGroupDelete", target, diff, nil)
+
+		return nil
+	}
+
+	if _, err := r.Client.UpdateUserGroup(ctx, ug.ID, teamID, req); err != nil {
 		logger.Error("failed to rename user group", zap.String("slack.usergroup.name", nameR), zap.Error(err))
+		r.audit(ctx, logger, "UserGroupDelete", target, diff, err)
+
 		return err
 	}
 
 	if _, err := r.Client.DisableUserGroup(ctx, ug.ID, teamID); err != nil {
 		logger.Error("failed to disable user group", zap.Any("slack.usergroup", *ug), zap.Error(err))
+		r.audit(ctx, logger, "UserGroupDelete", target, diff, err)
 
 		// try to restore the original user group details
 		if _, err := r.Client.UpdateUserGroup(ctx, ug.ID, teamID, slack.UserGroupReq{
@@ -275,16 +413,18 @@ func (r *Reconciler) DeleteUserGroup(ctx context.Context, groupID, appID string)
 
 	logger.Info("deleted user group", zap.Any("slack.usergroup", ug))
 
-	if err := auctx.WriteAuditEvent(ctx, r.auditEventWriter, "UserGroupDelete", map[string]string{
-		"slack.workspace.name": workspace,
-		"slack.usergroup.name": ug.Name,
-		"slack.usergroup.id":   ug.ID,
-		"governor.app.id":      appID,
-		"governor.group.id":    groupID,
-	}); err != nil {
-		logger.Error("error writing audit event", zap.Error(err))
+	r.unmatched.clear(groupID, appID)
+
+	r.recordRetirement(ctx, logger, ug, groupID, appID, workspace, teamID)
+
+	if err := r.store.DeleteMapping(ctx, groupID, teamID); err != nil {
+		logger.Error("failed to delete user group mapping", zap.Error(err))
 	}
 
+	r.deleteSyncStatus(ctx, groupID, workspace)
+
+	r.audit(ctx, logger, "UserGroupDelete", target, diff, nil)
+
 	return nil
 }
 
@@ -295,6 +435,12 @@ func (r *Reconciler) RemoveUserGroupMember(ctx context.Context, groupID, userID
 		return ErrBadParameter
 	}
 
+	ctx, unlock := r.lockGroup(ctx, groupID)
+	defer unlock()
+
+	// the group is reconciled again in the next pass, whether or not the event is processed
+	r.fingerprints.forget(groupID)
+
 	group, err := r.GovernorClient.Group(ctx, groupID, false)
 	if err != nil {
 		r.Logger.Error("error getting governor group", zap.String("governor.group.id", groupID), zap.Error(err))
@@ -326,6 +472,8 @@ func (r *Reconciler) RemoveUserGroupMember(ctx context.Context, groupID, userID
 		return nil
 	}
 
+	handled := false
+
 	// check all applications linked to the group and remove group member if a slack application
 	for _, appID := range group.Applications {
 		isSlack, workspace, err := r.isSlackApplication(ctx, appID)
@@ -339,7 +487,19 @@ func (r *Reconciler) RemoveUserGroupMember(ctx context.Context, groupID, userID
 			continue
 		}
 
-		logger := r.Logger.With(
+		if !r.syncsToWorkspace(ctx, group.ID, workspace) {
+			r.Logger.Debug("workspace not in the group settings, skipping", zap.String("governor.group.id", group.ID), zap.String("slack.workspace.name", workspace))
+			continue
+		}
+
+		// in org-wide mode all the slack applications share a single user group
+		if handled && r.orgWide() {
+			break
+		}
+
+		handled = true
+
+		logger := tracing.Logger(ctx, r.Logger).With(
 			zap.String("slack.workspace.name", workspace),
 			zap.String("governor.app.id", appID),
 			zap.String("governor.group.id", group.ID),
@@ -348,19 +508,19 @@ func (r *Reconciler) RemoveUserGroupMember(ctx context.Context, groupID, userID
 			zap.String("governor.user.email", user.Email),
 		)
 
-		teamID, err := r.teamIDFromName(ctx, workspace)
+		teamID, err := r.userGroupTeamID(ctx, workspace)
 		if err != nil {
 			logger.Error("failed to get workspace id", zap.Error(err))
 			continue
 		}
 
-		ug, err := r.userGroupFromName(ctx, r.userGroupName(group.Name), teamID, false)
+		ug, err := r.userGroup(ctx, group, appID, workspace, teamID)
 		if err != nil {
 			logger.Error("failed to get slack user group", zap.Error(err))
 			continue
 		}
 
-		u, err := r.Client.GetUserByEmail(ctx, user.Email)
+		u, err := r.identity.Resolve(ctx, identity.User{ID: user.ID, Email: user.Email})
 		if err != nil {
 			logger.Error("failed to get slack user", zap.Error(err))
 			continue
@@ -371,46 +531,65 @@ func (r *Reconciler) RemoveUserGroupMember(ctx context.Context, groupID, userID
 			continue
 		}
 
+		if len(r.protectedUsers(ctx, logger, r.exclusions(group, workspace, ug), []string{u.ID})) != 0 {
+			logger.Info("slack user is protected, skipping", zap.String("slack.user.id", u.ID))
+			continue
+		}
+
 		newUsers := remove(ug.Users, u.ID)
 
 		logger.Debug("updating user group members", zap.Any("slack.usergroup.existing", ug.Users), zap.Any("slack.usergroup.new", newUsers))
 
+		target := map[string]string{
+			"slack.workspace.name": workspace,
+			"slack.usergroup.name": ug.Name,
+			"slack.usergroup.id":   ug.ID,
+			"slack.user.id":        u.ID,
+			"governor.app.id":      appID,
+			"governor.group.id":    group.ID,
+			"governor.group.slug":  group.Slug,
+			"governor.user.id":     userID,
+			"governor.user.email":  user.Email,
+		}
+		r.emails.set(u.ID, user.Email)
+		diff := r.withEmails(ctx, newListDiff(ug.Users, newUsers))
+
 		if r.dryrun {
 			logger.Info("SKIP removing user to group", zap.Any("slack.usergroup", *ug))
+			r.audit(ctx, logger, "UserGroupRemoveMember", target, diff, nil)
+
 			continue
 		}
 
 		_, err = r.Client.UpdateUserGroupMembers(ctx, ug.ID, teamID, newUsers)
 		if err != nil {
-			logger.Error("failed to remove user group", zap.String("slack.usergroup.name", r.userGroupName(group.Name)), zap.Error(err))
+			logger.Error("failed to remove user group", zap.String("slack.usergroup.name", r.userGroupName(group, appID, workspace)), zap.Error(err))
+			r.audit(ctx, logger, "UserGroupRemoveMember", target, diff, err)
+			r.writeSyncStatus(ctx, r.memberEventStatus(group, appID, workspace, ug, ug.Users), err)
+
 			return err
 		}
 
 		logger.Info("removed user from group")
 
-		if err := auctx.WriteAuditEvent(ctx, r.auditEventWriter, "UserGroupRemoveMember", map[string]string{
-			"slack.workspace.name": workspace,
-			"slack.usergroup.name": ug.Name,
-			"slack.usergroup.id":   ug.ID,
-			"slack.user.id":        u.ID,
-			"governor.app.id":      appID,
-			"governor.group.id":    group.ID,
-			"governor.group.slug":  group.Slug,
-			"governor.user.id":     userID,
-		}); err != nil {
-			logger.Error("error writing audit event", zap.Error(err))
-		}
+		r.writeSyncStatus(ctx, r.memberEventStatus(group, appID, workspace, ug, newUsers), nil)
+		r.audit(ctx, logger, "UserGroupRemoveMember", target, diff, nil)
+
+		r.notify(ctx, membershipNotification(notify.KindRemoved, u.ID, workspace, ug, group))
 	}
 
 	return nil
 }
 
 // UpdateUserGroupMembers updates the members of a slack user group to match the members of the governor group
-func (r *Reconciler) UpdateUserGroupMembers(ctx context.Context, groupID, appID string) error {
+func (r *Reconciler) UpdateUserGroupMembers(ctx context.Context, groupID, appID string) (err error) {
 	if groupID == "" || appID == "" {
 		return ErrBadParameter
 	}
 
+	ctx, unlock := r.lockGroup(ctx, groupID)
+	defer unlock()
+
 	isSlack, workspace, err := r.isSlackApplication(ctx, appID)
 	if err != nil {
 		r.Logger.Error("failed to get application from governor", zap.String("governor.app.id", appID), zap.Error(err))
@@ -422,7 +601,17 @@ func (r *Reconciler) UpdateUserGroupMembers(ctx context.Context, groupID, appID
 		return nil
 	}
 
-	logger := r.Logger.With(zap.String("slack.workspace.name", workspace), zap.String("governor.app.id", appID))
+	if !r.syncsToWorkspace(ctx, groupID, workspace) {
+		r.Logger.Debug("workspace not in the group settings, skipping", zap.String("governor.group.id", groupID), zap.String("slack.workspace.name", workspace))
+		return nil
+	}
+
+	status := &SyncStatus{GroupID: groupID, Workspace: workspace}
+
+	// the sync status is written to governor whether or not the members needed an update
+	defer func() { r.writeSyncStatus(ctx, status, err) }()
+
+	logger := tracing.Logger(ctx, r.Logger).With(zap.String("slack.workspace.name", workspace), zap.String("governor.app.id", appID))
 
 	group, err := r.GovernorClient.Group(ctx, groupID, false)
 	if err != nil {
@@ -437,42 +626,51 @@ func (r *Reconciler) UpdateUserGroupMembers(ctx context.Context, groupID, appID
 		return err
 	}
 
-	var memberEmails []string
-	for _, m := range members {
-		memberEmails = append(memberEmails, strings.ToLower(m.Email))
-	}
-
-	logger.Debug("got governor group members", zap.Any("governor.members", memberEmails))
+	logger.Debug("got governor group members", zap.Int("governor.members.count", len(members)))
 
-	teamID, err := r.teamIDFromName(ctx, workspace)
+	teamID, err := r.userGroupTeamID(ctx, workspace)
 	if err != nil {
 		return err
 	}
 
-	ug, err := r.userGroupFromName(ctx, r.userGroupName(group.Name), teamID, false)
+	ug, err := r.userGroup(ctx, group, appID, workspace, teamID)
 	if err != nil {
 		return err
 	}
 
-	var newUsers []string
+	status.UserGroupID = ug.ID
+	status.UserGroupHandle = ug.Handle
 
-	for _, m := range memberEmails {
-		u, err := r.Client.GetUserByEmail(ctx, m)
-		if err != nil {
-			logger.Info("didn't find slack user", zap.String("user.email", m), zap.Error(err))
+	// the span of the group reconciliation, or of the governor event, names the user group
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("slack.usergroup.id", ug.ID),
+		attribute.String("slack.usergroup.handle", ug.Handle),
+	)
 
-			// exit out to prevent deleting valid users
-			// only continue if user is not found (404 error)
-			if !errors.Is(err, slack.ErrSlackUserNotFound) {
-				return err
-			}
+	newUsers, unmatched, err := r.desiredMembers(ctx, logger, group, workspace, teamID, ug, members)
+	if err != nil {
+		return err
+	}
 
-			continue
-		}
+	status.Members = len(members)
+	status.SlackMembers = len(newUsers)
+	status.Unmatched = unmatched
 
-		newUsers = append(newUsers, u.ID)
+	report := &UnmatchedReport{
+		Workspace:         workspace,
+		GovernorAppID:     appID,
+		GovernorGroupID:   group.ID,
+		GovernorGroupSlug: group.Slug,
+		UserGroupName:     ug.Name,
+		Members:           unmatched,
+		UpdatedAt:         time.Now().UTC(),
 	}
 
+	// members are only told once that they can't be added until they join the workspace
+	r.notifyMembers(ctx, notify.KindNotInWorkspace, r.unmatched.newlyNotInWorkspace(report), workspace, ug, group)
+
+	r.unmatched.set(report)
+
 	if equal(ug.Users, newUsers) {
 		logger.Debug("no need to update members", zap.Any("slack.usergroup.existing", ug.Users), zap.Any("slack.usergroup.new", newUsers))
 		return nil
@@ -480,35 +678,133 @@ func (r *Reconciler) UpdateUserGroupMembers(ctx context.Context, groupID, appID
 
 	logger.Debug("updating user group members", zap.Any("slack.usergroup.existing", ug.Users), zap.Any("slack.usergroup.new", newUsers))
 
+	target := map[string]string{
+		"slack.workspace.name": workspace,
+		"slack.usergroup.name": ug.Name,
+		"slack.usergroup.id":   ug.ID,
+		"governor.app.id":      appID,
+		"governor.group.id":    group.ID,
+		"governor.group.slug":  group.Slug,
+	}
+
 	if r.dryrun {
 		logger.Info("SKIP updating slack user group members", zap.Any("slack.usergroup", *ug))
+		r.audit(ctx, logger, "UserGroupUpdateMembers", target, r.withEmails(ctx, newListDiff(ug.Users, newUsers)), nil)
+
 		return nil
 	}
 
 	ugUpdated, err := r.Client.UpdateUserGroupMembers(ctx, ug.ID, teamID, newUsers)
 	if err != nil {
-		logger.Error("failed to update user group", zap.String("slack.usergroup.name", r.userGroupName(group.Name)), zap.Error(err))
+		logger.Error("failed to update user group", zap.String("slack.usergroup.name", r.userGroupName(group, appID, workspace)), zap.Error(err))
+		r.audit(ctx, logger, "UserGroupUpdateMembers", target, r.withEmails(ctx, newListDiff(ug.Users, newUsers)), err)
+
 		return err
 	}
 
 	logger.Info("updated user group members", zap.Any("slack.group.name", ugUpdated.Name), zap.Any("slack.usergroup.users", ugUpdated.Users))
 
-	if err := auctx.WriteAuditEvent(ctx, r.auditEventWriter, "UserGroupUpdateMembers", map[string]string{
-		"slack.workspace.name": workspace,
-		"slack.usergroup.name": ug.Name,
-		"slack.usergroup.id":   ug.ID,
-		"slack.user.old":       strings.Join(ug.Users, ","),
-		"slack.user.new":       strings.Join(ugUpdated.Users, ","),
-		"governor.app.id":      appID,
-		"governor.group.id":    group.ID,
-		"governor.group.slug":  group.Slug,
-	}); err != nil {
-		logger.Error("error writing audit event", zap.Error(err))
-	}
+	status.SlackMembers = len(ugUpdated.Users)
+
+	r.audit(ctx, logger, "UserGroupUpdateMembers", target, r.withEmails(ctx, newListDiff(ug.Users, ugUpdated.Users)), nil)
+
+	r.notifyMembers(ctx, notify.KindAdded, difference(ugUpdated.Users, ug.Users), workspace, ug, group)
+	r.notifyMembers(ctx, notify.KindRemoved, difference(ug.Users, ugUpdated.Users), workspace, ug, group)
 
 	return nil
 }
 
+// matchSlackUsers returns the slack user ids for the given governor group members, along with
+// the members that couldn't be matched to a slack user in the workspace. Deactivated slack users
+// and users that haven't joined the workspace are reported, but they're still part of the returned
+// ids so they aren't removed from the user groups they're in. An error is returned if a slack
+// lookup fails for any reason other than the user not being found, so we don't end up removing
+// valid users from the user group.
+func (r *Reconciler) matchSlackUsers(
+	ctx context.Context,
+	logger *zap.Logger,
+	teamID string,
+	members []*v1alpha1.GroupMember,
+) ([]string, []UnmatchedMember, error) {
+	var (
+		userIDs   []string
+		unmatched []UnmatchedMember
+	)
+
+	for _, m := range members {
+		email := strings.ToLower(m.Email)
+
+		um := UnmatchedMember{
+			GovernorUserID: m.ID,
+			Name:           m.Name,
+			Email:          email,
+		}
+
+		if m.Status.String == v1alpha1.UserStatusPending {
+			logger.Debug("skipping pending user", zap.String("governor.user.id", m.ID), zap.String("user.email", email))
+
+			um.Reason = UnmatchedPending
+			unmatched = append(unmatched, um)
+
+			continue
+		}
+
+		u, err := r.identity.Resolve(ctx, identity.User{ID: m.ID, Email: email})
+		if err != nil {
+			logger.Info("didn't find slack user", zap.String("user.email", email), zap.Error(err))
+
+			// exit out to prevent deleting valid users
+			// only continue if user is not found (404 error)
+			if !errors.Is(err, slack.ErrSlackUserNotFound) {
+				return nil, nil, err
+			}
+
+			um.Reason = UnmatchedNoSlackAccount
+			unmatched = append(unmatched, um)
+
+			continue
+		}
+
+		userIDs = append(userIDs, u.ID)
+		r.emails.set(u.ID, email)
+
+		switch {
+		case u.Deleted:
+			um.Reason = UnmatchedDeactivated
+		case !slack.UserInWorkspace(u, teamID):
+			um.Reason = UnmatchedNotInWorkspace
+		default:
+			continue
+		}
+
+		um.SlackUserID = u.ID
+
+		logger.Info("slack user can't be used in the workspace",
+			zap.String("user.email", email),
+			zap.String("slack.user.id", u.ID),
+			zap.String("reason", string(um.Reason)),
+		)
+
+		unmatched = append(unmatched, um)
+	}
+
+	return userIDs, unmatched, nil
+}
+
+// unmatchedSlackUsers returns the ids of the slack users found for the unmatched members, that is
+// the deactivated users and the users that haven't joined the workspace
+func unmatchedSlackUsers(unmatched []UnmatchedMember) []string {
+	var ids []string
+
+	for _, um := range unmatched {
+		if um.SlackUserID != "" {
+			ids = append(ids, um.SlackUserID)
+		}
+	}
+
+	return ids
+}
+
 // teamID searches all the workspaces (teams) for the given name and returns the ID
 // or an error if the team is not found.
 func (r *Reconciler) teamIDFromName(ctx context.Context, name string) (string, error) {
@@ -554,13 +850,7 @@ func (r *Reconciler) userGroupFromName(ctx context.Context, name, teamID string,
 				zap.String("slack.workspace.id", teamID),
 			)
 
-			return &UserGroup{
-				ID:          ug.ID,
-				Name:        ug.Name,
-				Handle:      ug.Handle,
-				Description: ug.Description,
-				Users:       ug.Users,
-			}, nil
+			return toUserGroup(ug), nil
 		}
 	}
 
@@ -569,25 +859,54 @@ func (r *Reconciler) userGroupFromName(ctx context.Context, name, teamID string,
 	return nil, ErrSlackUserGroupNotFound
 }
 
-// userGroupName applies prefix to the governor group name to create a slack user group name
-func (r *Reconciler) userGroupName(groupName string) string {
-	return r.userGroupPrefix + groupName
+// userGroupFromID searches all the user groups in the workspace for the given id and
+// returns the group details or an error if the group is not found.
+func (r *Reconciler) userGroupFromID(ctx context.Context, id, teamID string, includeDisabled bool) (*UserGroup, error) {
+	if id == "" || teamID == "" {
+		return nil, ErrBadParameter
+	}
+
+	usergroups, err := r.Client.GetUserGroups(ctx, teamID, includeDisabled)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ug := range usergroups {
+		if ug.ID == id {
+			return toUserGroup(ug), nil
+		}
+	}
+
+	r.Logger.Debug("slack user group not found", zap.String("slack.usergroup.id", id), zap.String("slack.workspace.id", teamID))
+
+	return nil, ErrSlackUserGroupNotFound
 }
 
-// userGroupHandle returns the handle for the slack user group based on the governor group slug
-func (r *Reconciler) userGroupHandle(groupSlug string) string {
-	// we can just use the group slug as the handle
-	return groupSlug
+// toUserGroup returns the user group details of a slack user group
+func toUserGroup(ug slackgo.UserGroup) *UserGroup {
+	return &UserGroup{
+		ID:          ug.ID,
+		Name:        ug.Name,
+		Handle:      ug.Handle,
+		Description: ug.Description,
+		Users:       ug.Users,
+		Channels:    ug.Prefs.Channels,
+		Disabled:    ug.DateDelete != 0,
+		UpdatedBy:   ug.UpdatedBy,
+	}
 }
 
-func (r *Reconciler) userGroupReq(group *v1alpha1.Group) *slack.UserGroupReq {
-	name := r.userGroupName(group.Name)
-	handle := r.userGroupHandle(group.Slug)
+// userGroupReq returns the request to create the slack user group for the governor group
+func (r *Reconciler) userGroupReq(ctx context.Context, group *v1alpha1.Group, appID, workspace string) *slack.UserGroupReq {
+	name := r.userGroupName(group, appID, workspace)
+	handle := r.userGroupHandle(ctx, group, appID, workspace)
+	description := r.userGroupDescription(group, appID, workspace)
 
 	return &slack.UserGroupReq{
 		Name:        &name,
 		Handle:      &handle,
-		Description: &group.Description,
+		Description: &description,
+		Channels:    r.defaultChannels(ctx, group, appID, workspace),
 	}
 }
 
@@ -623,11 +942,30 @@ func equal(a, b []string) bool {
 	return true
 }
 
+// difference returns the items in a that are not in b
+func difference(a, b []string) []string {
+	exists := make(map[string]bool, len(b))
+	for _, value := range b {
+		exists[value] = true
+	}
+
+	out := []string{}
+
+	for _, value := range a {
+		if !exists[value] {
+			out = append(out, value)
+		}
+	}
+
+	return out
+}
+
 // remove removes the item from the list
 func remove(list []string, item string) []string {
 	for i, v := range list {
 		if v == item {
-			return append(list[:i], list[i+1:]...)
+			// the list is copied so the caller can still diff the old and the new list
+			return append(slices.Clone(list[:i]), list[i+1:]...)
 		}
 	}
 
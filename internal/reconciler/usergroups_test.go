@@ -1,8 +1,16 @@
 package reconciler
 
 import (
+	"context"
 	"reflect"
 	"testing"
+
+	"github.com/metal-toolbox/governor-api/pkg/api/v1alpha1"
+	slackgo "github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/identity"
+	"github.com/metal-toolbox/gov-slack-addon/internal/slack"
 )
 
 func Test_contains(t *testing.T) {
@@ -205,3 +213,99 @@ func Test_remove(t *testing.T) {
 		})
 	}
 }
+
+func Test_difference(t *testing.T) {
+	tests := []struct {
+		name string
+		a    []string
+		b    []string
+		want []string
+	}{
+		{
+			name: "some missing",
+			a:    []string{"A", "B", "C"},
+			b:    []string{"B"},
+			want: []string{"A", "C"},
+		},
+		{
+			name: "none missing",
+			a:    []string{"A", "B"},
+			b:    []string{"B", "A", "C"},
+			want: []string{},
+		},
+		{
+			name: "nil b",
+			a:    []string{"A"},
+			want: []string{"A"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := difference(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("difference() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type mockResolver struct {
+	users map[string]*slackgo.User
+}
+
+func (m *mockResolver) Name() string { return "mock" }
+
+func (m *mockResolver) Resolve(_ context.Context, u identity.User) (*slackgo.User, error) {
+	if su, ok := m.users[u.Email]; ok {
+		return su, nil
+	}
+
+	return nil, slack.ErrSlackUserNotFound
+}
+
+func TestReconciler_matchSlackUsers(t *testing.T) {
+	r := &Reconciler{
+		identity: &mockResolver{users: map[string]*slackgo.User{
+			"active@example.com":      {ID: "U1", TeamID: "T1"},
+			"deactivated@example.com": {ID: "U2", TeamID: "T1", Deleted: true},
+			"elsewhere@example.com":   {ID: "U3", Enterprise: slackgo.EnterpriseUser{Teams: []string{"T2"}}},
+		}},
+		emails: newUserEmails(),
+	}
+
+	members := []*v1alpha1.GroupMember{
+		{ID: "gov-1", Email: "active@example.com"},
+		{ID: "gov-2", Email: "deactivated@example.com"},
+		{ID: "gov-3", Email: "elsewhere@example.com"},
+		{ID: "gov-4", Email: "missing@example.com"},
+	}
+
+	userIDs, unmatched, err := r.matchSlackUsers(context.Background(), zap.NewNop(), "T1", members)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// deactivated users and users outside the workspace are reported but not removed from the user group
+	if want := []string{"U1", "U2", "U3"}; !reflect.DeepEqual(userIDs, want) {
+		t.Errorf("matchSlackUsers() user ids = %v, want %v", userIDs, want)
+	}
+
+	reasons := map[string]UnmatchedReason{}
+	for _, um := range unmatched {
+		reasons[um.GovernorUserID] = um.Reason
+	}
+
+	want := map[string]UnmatchedReason{
+		"gov-2": UnmatchedDeactivated,
+		"gov-3": UnmatchedNotInWorkspace,
+		"gov-4": UnmatchedNoSlackAccount,
+	}
+
+	if !reflect.DeepEqual(reasons, want) {
+		t.Errorf("matchSlackUsers() unmatched = %v, want %v", reasons, want)
+	}
+
+	if got := unmatchedSlackUsers(unmatched); !reflect.DeepEqual(got, []string{"U2", "U3"}) {
+		t.Errorf("unmatchedSlackUsers() = %v, want [U2 U3]", got)
+	}
+}
@@ -0,0 +1,233 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	slackgo "github.com/slack-go/slack"
+)
+
+const (
+	// DefaultWorkers is the default number of groups reconciled concurrently
+	DefaultWorkers = 8
+	// DefaultWorkspaceWorkers is the default number of groups reconciled concurrently in a workspace
+	DefaultWorkspaceWorkers = 2
+)
+
+// workerPool bounds the number of groups reconciled concurrently, globally and in each workspace.
+// When slack rate limits a workspace, its workers wait for as long as slack asked before they start
+// on another group, instead of making more requests that would be rate limited too.
+type workerPool struct {
+	global       chan struct{}
+	perWorkspace int
+
+	mu          sync.Mutex
+	workspaces  map[string]chan struct{}
+	pausedUntil map[string]time.Time
+}
+
+func newWorkerPool(global, perWorkspace int) *workerPool {
+	if global < 1 {
+		global = DefaultWorkers
+	}
+
+	if perWorkspace < 1 {
+		perWorkspace = DefaultWorkspaceWorkers
+	}
+
+	return &workerPool{
+		global:       make(chan struct{}, global),
+		perWorkspace: perWorkspace,
+		workspaces:   make(map[string]chan struct{}),
+		pausedUntil:  make(map[string]time.Time),
+	}
+}
+
+// workspace returns the worker slots of the workspace
+func (p *workerPool) workspace(name string) chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ws, ok := p.workspaces[name]
+	if !ok {
+		ws = make(chan struct{}, p.perWorkspace)
+		p.workspaces[name] = ws
+	}
+
+	return ws
+}
+
+// acquire waits for a free worker in the workspace and globally, and for the workspace to be out of
+// its rate limit pause. It returns the context error if the context is done first.
+func (p *workerPool) acquire(ctx context.Context, workspace string) error {
+	ws := p.workspace(workspace)
+
+	// the workspace slot is taken first so the workers waiting on a busy workspace don't hold the
+	// global slots the other workspaces could use
+	select {
+	case ws <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if err := p.waitRateLimit(ctx, workspace); err != nil {
+		<-ws
+		return err
+	}
+
+	select {
+	case p.global <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		<-ws
+		return ctx.Err()
+	}
+}
+
+// release frees the worker acquired in the workspace
+func (p *workerPool) release(workspace string) {
+	<-p.global
+	<-p.workspace(workspace)
+}
+
+// rateLimited pauses the workers of the workspace for as long as slack asked, if the error is a
+// rate limit error. It returns true if the workspace was paused.
+func (p *workerPool) rateLimited(workspace string, err error) bool {
+	var rl *slackgo.RateLimitedError
+	if !errors.As(err, &rl) {
+		return false
+	}
+
+	until := time.Now().Add(rl.RetryAfter)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if until.After(p.pausedUntil[workspace]) {
+		p.pausedUntil[workspace] = until
+	}
+
+	return true
+}
+
+// waitRateLimit waits until the rate limit pause of the workspace is over, or the context is done
+func (p *workerPool) waitRateLimit(ctx context.Context, workspace string) error {
+	p.mu.Lock()
+	wait := time.Until(p.pausedUntil[workspace])
+	p.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(wait)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// groupLocks makes sure a governor group isn't reconciled in two workspaces at the same time, e.g.
+// an org-wide user group must only be created once, and that the governor events and the drift
+// reverts don't overwrite the members set by a concurrent reconciliation. The lock of a group is
+// dropped once nobody holds or waits for it.
+type groupLocks struct {
+	mu    sync.Mutex
+	locks map[string]*groupLock
+}
+
+// groupLock is the lock of a governor group, along with the number of holders and waiters
+type groupLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+func newGroupLocks() *groupLocks {
+	return &groupLocks{locks: make(map[string]*groupLock)}
+}
+
+// lock locks the governor group and returns the function unlocking it
+func (g *groupLocks) lock(groupID string) func() {
+	g.mu.Lock()
+
+	l, ok := g.locks[groupID]
+	if !ok {
+		l = &groupLock{}
+		g.locks[groupID] = l
+	}
+
+	l.refs++
+
+	g.mu.Unlock()
+
+	l.mu.Lock()
+
+	return func() {
+		l.mu.Unlock()
+
+		g.mu.Lock()
+		defer g.mu.Unlock()
+
+		l.refs--
+		if l.refs == 0 {
+			delete(g.locks, groupID)
+		}
+	}
+}
+
+// heldGroupsKey is the context key of the governor groups locked by the caller
+type heldGroupsKey struct{}
+
+// lockGroup locks the governor group, unless the context already holds its lock, and returns the
+// context holding it along with the function unlocking it. The entry points lock the group so the
+// ones calling each other, such as reconcileGroup, don't deadlock.
+func (r *Reconciler) lockGroup(ctx context.Context, groupID string) (context.Context, func()) {
+	held, _ := ctx.Value(heldGroupsKey{}).(map[string]bool)
+
+	if r.groupLocks == nil || held[groupID] {
+		return ctx, func() {}
+	}
+
+	unlock := r.groupLocks.lock(groupID)
+
+	next := make(map[string]bool, len(held)+1)
+	for id := range held {
+		next[id] = true
+	}
+
+	next[groupID] = true
+
+	return context.WithValue(ctx, heldGroupsKey{}, next), unlock
+}
+
+// syncedGroups keeps track of the org-wide user groups whose members were synced during a pass
+type syncedGroups struct {
+	mu     sync.Mutex
+	groups map[string]bool
+}
+
+// claim returns true if the members of the governor group still need to be synced during the pass.
+// Org-wide user groups are shared by all the slack applications, so their members only need to be
+// synced once.
+func (s *syncedGroups) claim(groupID string, orgWide bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.groups[groupID] {
+		return false
+	}
+
+	if s.groups == nil {
+		s.groups = make(map[string]bool)
+	}
+
+	s.groups[groupID] = orgWide
+
+	return true
+}
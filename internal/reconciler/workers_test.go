@@ -0,0 +1,186 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	slackgo "github.com/slack-go/slack"
+)
+
+func Test_workerPool_limits(t *testing.T) {
+	p := newWorkerPool(3, 2)
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		global   int
+		maxAll   int
+		running  = map[string]int{}
+		maxByWks = map[string]int{}
+	)
+
+	for i := range 20 {
+		ws := []string{"ws-a", "ws-b"}[i%2]
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := p.acquire(context.Background(), ws); err != nil {
+				t.Errorf("unexpected error %v", err)
+				return
+			}
+			defer p.release(ws)
+
+			mu.Lock()
+			global++
+			running[ws]++
+			maxAll = max(maxAll, global)
+			maxByWks[ws] = max(maxByWks[ws], running[ws])
+			mu.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			mu.Lock()
+			global--
+			running[ws]--
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	if maxAll > 3 {
+		t.Errorf("expected at most 3 concurrent workers, got %d", maxAll)
+	}
+
+	for ws, n := range maxByWks {
+		if n > 2 {
+			t.Errorf("expected at most 2 concurrent workers in %s, got %d", ws, n)
+		}
+	}
+}
+
+func Test_workerPool_canceled(t *testing.T) {
+	p := newWorkerPool(1, 1)
+
+	if err := p.acquire(context.Background(), "ws-a"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := p.acquire(ctx, "ws-b"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to be exceeded waiting for a global worker, got %v", err)
+	}
+
+	p.release("ws-a")
+
+	// the workspace slot taken while waiting for the global one is released
+	if err := p.acquire(context.Background(), "ws-b"); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func Test_workerPool_rateLimited(t *testing.T) {
+	p := newWorkerPool(2, 1)
+
+	if p.rateLimited("ws-a", errors.New("boom")) { //nolint:err113
+		t.Error("expected an error that isn't a rate limit error not to pause the workspace")
+	}
+
+	if !p.rateLimited("ws-a", &slackgo.RateLimitedError{RetryAfter: 50 * time.Millisecond}) {
+		t.Fatal("expected a rate limit error to pause the workspace")
+	}
+
+	start := time.Now()
+
+	if err := p.acquire(context.Background(), "ws-b"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if time.Since(start) > 40*time.Millisecond {
+		t.Error("expected the other workspaces not to be paused")
+	}
+
+	if err := p.acquire(context.Background(), "ws-a"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if time.Since(start) < 40*time.Millisecond {
+		t.Error("expected the rate limited workspace to be paused")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	p.rateLimited("ws-c", &slackgo.RateLimitedError{RetryAfter: time.Minute})
+
+	if err := p.acquire(ctx, "ws-c"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the wait to be canceled, got %v", err)
+	}
+}
+
+func Test_syncedGroups(t *testing.T) {
+	var s syncedGroups
+
+	if !s.claim("group-a", true) || s.claim("group-a", true) {
+		t.Error("expected the members of an org-wide user group to be synced once")
+	}
+
+	if !s.claim("group-b", false) || !s.claim("group-b", false) {
+		t.Error("expected the members of a workspace user group to be synced in each workspace")
+	}
+}
+
+func Test_groupLocks(t *testing.T) {
+	g := newGroupLocks()
+
+	unlock := g.lock("group-a")
+
+	locked := make(chan struct{})
+
+	go func() {
+		defer close(locked)
+
+		g.lock("group-a")()
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("expected the group to stay locked")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	unlock()
+	<-locked
+
+	if len(g.locks) != 0 {
+		t.Errorf("expected the unused locks to be dropped, got %d", len(g.locks))
+	}
+}
+
+func TestReconciler_lockGroup(t *testing.T) {
+	r := &Reconciler{groupLocks: newGroupLocks()}
+
+	ctx, unlock := r.lockGroup(context.Background(), "group-a")
+
+	// the context holding the lock doesn't wait for it again
+	_, unlockAgain := r.lockGroup(ctx, "group-a")
+	unlockAgain()
+
+	if len(r.groupLocks.locks) != 1 {
+		t.Errorf("expected the group to stay locked, got %d locks", len(r.groupLocks.locks))
+	}
+
+	unlock()
+
+	if len(r.groupLocks.locks) != 0 {
+		t.Errorf("expected the group to be unlocked, got %d locks", len(r.groupLocks.locks))
+	}
+}
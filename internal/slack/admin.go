@@ -0,0 +1,94 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// adminResponse is the common response of the slack admin api methods
+type adminResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// AddUserGroupTeams attaches an organization wide user group to the given workspaces (teams).
+// This uses the Enterprise Grid admin api, which isn't supported by slack-go, and requires an
+// org level token with the admin.usergroups:write scope.
+func (c *Client) AddUserGroupTeams(ctx context.Context, groupID string, teamIDs []string) error {
+	if groupID == "" || len(teamIDs) == 0 {
+		return ErrBadParameter
+	}
+
+	c.logger.Debug("adding slack user group to workspaces", zap.String("slack.usergroup.id", groupID), zap.Strings("slack.workspace.ids", teamIDs))
+
+	values := url.Values{
+		"usergroup_id": {groupID},
+		"team_ids":     {strings.Join(teamIDs, ",")},
+	}
+
+	if err := c.adminRequest(ctx, "admin.usergroups.addTeams", values); err != nil {
+		switch err.Error() {
+		case SlackErrorNoSuchSubteam, SlackErrorSubteamNotFound:
+			return ErrSlackGroupNotFound
+		case SlackErrorTeamNotFound:
+			return ErrSlackWorkspaceNotFound
+		}
+
+		return apiError("add user group teams", err)
+	}
+
+	return nil
+}
+
+// adminRequest posts a form encoded request to a slack admin api method
+func (c *Client) adminRequest(ctx context.Context, method string, values url.Values) (err error) {
+	ctx, span := startSpan(ctx, c.tracer, method)
+	defer func() { endSpan(span, err) }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiURL+method, strings.NewReader(values.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+		if err != nil {
+			retryAfter = 1
+		}
+
+		return &slack.RateLimitedError{RetryAfter: time.Duration(retryAfter) * time.Second}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return slack.StatusCodeError{Code: resp.StatusCode, Status: resp.Status}
+	}
+
+	var out adminResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return err
+	}
+
+	if !out.OK {
+		return slack.SlackErrorResponse{Err: out.Error}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
+)
+
+func TestClient_AddUserGroupTeams(t *testing.T) {
+	tests := []struct {
+		name     string
+		groupID  string
+		teamIDs  []string
+		status   int
+		response string
+		wantErr  error
+	}{
+		{
+			name:     "success",
+			groupID:  "S0001",
+			teamIDs:  []string{"T0001", "T0002"},
+			status:   http.StatusOK,
+			response: `{"ok":true}`,
+		},
+		{
+			name:    "missing teams",
+			groupID: "S0001",
+			wantErr: ErrBadParameter,
+		},
+		{
+			name:     "group not found",
+			groupID:  "S0001",
+			teamIDs:  []string{"T0001"},
+			status:   http.StatusOK,
+			response: `{"ok":false,"error":"no_such_subteam"}`,
+			wantErr:  ErrSlackGroupNotFound,
+		},
+		{
+			name:     "slack error",
+			groupID:  "S0001",
+			teamIDs:  []string{"T0001"},
+			status:   http.StatusOK,
+			response: `{"ok":false,"error":"not_allowed_token_type"}`,
+			wantErr:  ErrSlackAPI,
+		},
+		{
+			name:    "server error",
+			groupID: "S0001",
+			teamIDs: []string{"T0001"},
+			status:  http.StatusInternalServerError,
+			wantErr: ErrSlackAPI,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/admin.usergroups.addTeams" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+
+				if r.Header.Get("Authorization") != "Bearer xoxp-test" {
+					t.Errorf("unexpected authorization header %q", r.Header.Get("Authorization"))
+				}
+
+				if got := r.FormValue("team_ids"); got != "T0001,T0002" && got != "T0001" {
+					t.Errorf("unexpected team_ids %q", got)
+				}
+
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer srv.Close()
+
+			c := &Client{
+				logger:     zap.NewNop(),
+				token:      "xoxp-test",
+				apiURL:     srv.URL + "/",
+				httpClient: srv.Client(),
+				tracer:     noop.NewTracerProvider().Tracer("test"),
+			}
+
+			err := c.AddUserGroupTeams(context.TODO(), tt.groupID, tt.teamIDs)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Client.AddUserGroupTeams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
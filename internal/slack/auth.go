@@ -0,0 +1,21 @@
+package slack
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// AuthUserID returns the id of the slack user (or bot user) the token belongs to
+func (c *Client) AuthUserID(ctx context.Context) (string, error) {
+	c.logger.Debug("checking slack token identity")
+
+	resp, err := c.slackService.AuthTestContext(ctx)
+	if err != nil {
+		return "", apiError("auth test", err)
+	}
+
+	c.logger.Debug("slack token identity", zap.String("slack.user.id", resp.UserID), zap.String("slack.workspace.id", resp.TeamID))
+
+	return resp.UserID, nil
+}
@@ -0,0 +1,60 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+func (m *mockSlackService) AuthTestContext(_ context.Context) (*slack.AuthTestResponse, error) {
+	if m.Error != nil {
+		return nil, m.Error
+	}
+
+	return &slack.AuthTestResponse{UserID: "U0BOT", TeamID: "T0001"}, nil
+}
+
+func TestClient_AuthUserID(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "success",
+			want: "U0BOT",
+		},
+		{
+			name:    "slack error",
+			err:     errors.New("invalid_auth"), //nolint:err113
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				logger:       zap.NewNop(),
+				slackService: &mockSlackService{Error: tt.err},
+			}
+
+			got, err := c.AuthUserID(context.TODO())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.AuthUserID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr && !errors.Is(err, ErrSlackAPI) {
+				t.Errorf("Client.AuthUserID() error = %v, want ErrSlackAPI", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Client.AuthUserID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
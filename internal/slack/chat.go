@@ -0,0 +1,57 @@
+package slack
+
+import (
+	"context"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// PostMessage posts a plain text message to a slack channel and returns the message timestamp
+func (c *Client) PostMessage(ctx context.Context, channelID, text string) (string, error) {
+	if channelID == "" || text == "" {
+		return "", ErrBadParameter
+	}
+
+	c.logger.Debug("posting slack message", zap.String("slack.channel.id", channelID))
+
+	_, ts, err := c.slackService.PostMessageContext(ctx, channelID,
+		slack.MsgOptionText(text, false),
+		slack.MsgOptionDisableLinkUnfurl(),
+	)
+	if err != nil {
+		if err.Error() == SlackErrorChannelNotFound {
+			return "", ErrSlackChannelNotFound
+		}
+
+		return "", apiError("post message", err)
+	}
+
+	c.logger.Debug("posted slack message", zap.String("slack.channel.id", channelID), zap.String("slack.message.ts", ts))
+
+	return ts, nil
+}
+
+// PostThreadReply posts a plain text reply in the thread of a slack message and returns the reply timestamp
+func (c *Client) PostThreadReply(ctx context.Context, channelID, threadTS, text string) (string, error) {
+	if channelID == "" || threadTS == "" || text == "" {
+		return "", ErrBadParameter
+	}
+
+	c.logger.Debug("posting slack thread reply", zap.String("slack.channel.id", channelID), zap.String("slack.thread.ts", threadTS))
+
+	_, ts, err := c.slackService.PostMessageContext(ctx, channelID,
+		slack.MsgOptionText(text, false),
+		slack.MsgOptionTS(threadTS),
+		slack.MsgOptionDisableLinkUnfurl(),
+	)
+	if err != nil {
+		if err.Error() == SlackErrorChannelNotFound {
+			return "", ErrSlackChannelNotFound
+		}
+
+		return "", apiError("post thread reply", err)
+	}
+
+	return ts, nil
+}
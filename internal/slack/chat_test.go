@@ -0,0 +1,128 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+func (m *mockSlackService) PostMessageContext(_ context.Context, channelID string, _ ...slack.MsgOption) (string, string, error) {
+	if m.Error != nil {
+		return "", "", m.Error
+	}
+
+	if channelID == "notfound" {
+		return "", "", errors.New("channel_not_found") //nolint:err113
+	}
+
+	return channelID, "1700000000.000100", nil
+}
+
+func TestClient_PostMessage(t *testing.T) {
+	type args struct {
+		channelID string
+		text      string
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		err     error
+		want    string
+		wantErr error
+	}{
+		{
+			name: "successful post message",
+			args: args{channelID: "C0001", text: "hello"},
+			want: "1700000000.000100",
+		},
+		{
+			name:    "empty channel id",
+			args:    args{channelID: "", text: "hello"},
+			wantErr: ErrBadParameter,
+		},
+		{
+			name:    "empty text",
+			args:    args{channelID: "C0001", text: ""},
+			wantErr: ErrBadParameter,
+		},
+		{
+			name:    "channel not found",
+			args:    args{channelID: "notfound", text: "hello"},
+			wantErr: ErrSlackChannelNotFound,
+		},
+		{
+			name:    "slack error",
+			args:    args{channelID: "C0001", text: "hello"},
+			err:     errors.New("boom"), //nolint:err113
+			wantErr: ErrSlackAPI,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				logger:       zap.NewNop(),
+				slackService: &mockSlackService{Error: tt.err},
+			}
+
+			got, err := c.PostMessage(context.TODO(), tt.args.channelID, tt.args.text)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Client.PostMessage() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("Client.PostMessage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_PostThreadReply(t *testing.T) {
+	tests := []struct {
+		name     string
+		threadTS string
+		err      error
+		want     string
+		wantErr  error
+	}{
+		{
+			name:     "successful thread reply",
+			threadTS: "1700000000.000001",
+			want:     "1700000000.000100",
+		},
+		{
+			name:    "empty thread ts",
+			wantErr: ErrBadParameter,
+		},
+		{
+			name:     "slack error",
+			threadTS: "1700000000.000001",
+			err:      errors.New("boom"), //nolint:err113
+			wantErr:  ErrSlackAPI,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				logger:       zap.NewNop(),
+				slackService: &mockSlackService{Error: tt.err},
+			}
+
+			got, err := c.PostThreadReply(context.TODO(), "C0001", tt.threadTS, "hello")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Client.PostThreadReply() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("Client.PostThreadReply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
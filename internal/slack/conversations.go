@@ -0,0 +1,98 @@
+package slack
+
+import (
+	"context"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// maxChannelInviteUsers is the maximum number of users slack accepts in a single invite request
+const maxChannelInviteUsers = 1000
+
+// GetChannelMembers returns the ids of all the members of a channel
+func (c *Client) GetChannelMembers(ctx context.Context, channelID string) ([]string, error) {
+	if channelID == "" {
+		return nil, ErrBadParameter
+	}
+
+	c.logger.Debug("getting slack channel members", zap.String("slack.channel.id", channelID))
+
+	members := []string{}
+	params := &slack.GetUsersInConversationParameters{
+		ChannelID: channelID,
+		Limit:     maxChannelInviteUsers,
+	}
+
+	for {
+		page, cursor, err := c.slackService.GetUsersInConversationContext(ctx, params)
+		if err != nil {
+			if err.Error() == SlackErrorChannelNotFound {
+				return nil, ErrSlackChannelNotFound
+			}
+
+			return nil, apiError("get channel members", err)
+		}
+
+		members = append(members, page...)
+
+		if cursor == "" {
+			break
+		}
+
+		params.Cursor = cursor
+	}
+
+	c.logger.Debug("returning slack channel members", zap.String("slack.channel.id", channelID), zap.Int("slack.channel.members.count", len(members)))
+
+	return members, nil
+}
+
+// InviteToChannel invites the users to a channel. Users that are already members are ignored by slack.
+func (c *Client) InviteToChannel(ctx context.Context, channelID string, userIDs []string) error {
+	if channelID == "" || len(userIDs) == 0 {
+		return ErrBadParameter
+	}
+
+	c.logger.Debug("inviting users to slack channel", zap.String("slack.channel.id", channelID), zap.Strings("slack.user.ids", userIDs))
+
+	for start := 0; start < len(userIDs); start += maxChannelInviteUsers {
+		end := min(start+maxChannelInviteUsers, len(userIDs))
+
+		if _, err := c.slackService.InviteUsersToConversationContext(ctx, channelID, userIDs[start:end]...); err != nil {
+			switch err.Error() {
+			case SlackErrorAlreadyInChannel:
+				continue
+			case SlackErrorChannelNotFound:
+				return ErrSlackChannelNotFound
+			}
+
+			return apiError("invite users to channel", err)
+		}
+	}
+
+	return nil
+}
+
+// KickFromChannel removes a user from a channel. Users that aren't members of the channel, as well
+// as the calling user itself, are ignored.
+func (c *Client) KickFromChannel(ctx context.Context, channelID, userID string) error {
+	if channelID == "" || userID == "" {
+		return ErrBadParameter
+	}
+
+	c.logger.Debug("removing user from slack channel", zap.String("slack.channel.id", channelID), zap.String("slack.user.id", userID))
+
+	if err := c.slackService.KickUserFromConversationContext(ctx, channelID, userID); err != nil {
+		switch err.Error() {
+		case SlackErrorNotInChannel, SlackErrorCantKickSelf:
+			return nil
+		case SlackErrorChannelNotFound:
+			return ErrSlackChannelNotFound
+		}
+
+		return apiError("kick user from channel", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,222 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+func (m *mockSlackService) GetUsersInConversationContext(_ context.Context, params *slack.GetUsersInConversationParameters) ([]string, string, error) {
+	if m.Error != nil {
+		return nil, "", m.Error
+	}
+
+	if params.ChannelID == "notfound" {
+		return nil, "", errors.New("channel_not_found") //nolint:err113
+	}
+
+	// return the members in two pages
+	if params.Cursor == "" {
+		return []string{"U0001", "U0002"}, "next", nil
+	}
+
+	return []string{"U0003"}, "", nil
+}
+
+func (m *mockSlackService) InviteUsersToConversationContext(_ context.Context, channelID string, users ...string) (*slack.Channel, error) {
+	if m.Error != nil {
+		return nil, m.Error
+	}
+
+	switch channelID {
+	case "notfound":
+		return nil, errors.New("channel_not_found") //nolint:err113
+	case "member":
+		return nil, errors.New("already_in_channel") //nolint:err113
+	}
+
+	m.invited = append(m.invited, users...)
+
+	return &slack.Channel{}, nil
+}
+
+func (m *mockSlackService) KickUserFromConversationContext(_ context.Context, channelID, _ string) error {
+	if m.Error != nil {
+		return m.Error
+	}
+
+	switch channelID {
+	case "notfound":
+		return errors.New("channel_not_found") //nolint:err113
+	case "notmember":
+		return errors.New("not_in_channel") //nolint:err113
+	}
+
+	return nil
+}
+
+func TestClient_GetChannelMembers(t *testing.T) {
+	tests := []struct {
+		name      string
+		channelID string
+		err       error
+		want      []string
+		wantErr   error
+	}{
+		{
+			name:      "successful get members",
+			channelID: "C0001",
+			want:      []string{"U0001", "U0002", "U0003"},
+		},
+		{
+			name:    "empty channel id",
+			wantErr: ErrBadParameter,
+		},
+		{
+			name:      "channel not found",
+			channelID: "notfound",
+			wantErr:   ErrSlackChannelNotFound,
+		},
+		{
+			name:      "slack error",
+			channelID: "C0001",
+			err:       errors.New("boom"), //nolint:err113
+			wantErr:   ErrSlackAPI,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				logger:       zap.NewNop(),
+				slackService: &mockSlackService{Error: tt.err},
+			}
+
+			got, err := c.GetChannelMembers(context.TODO(), tt.channelID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Client.GetChannelMembers() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Client.GetChannelMembers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_InviteToChannel(t *testing.T) {
+	tests := []struct {
+		name      string
+		channelID string
+		users     []string
+		err       error
+		wantErr   error
+	}{
+		{
+			name:      "successful invite",
+			channelID: "C0001",
+			users:     []string{"U0001", "U0002"},
+		},
+		{
+			name:      "already in channel",
+			channelID: "member",
+			users:     []string{"U0001"},
+		},
+		{
+			name:      "empty users",
+			channelID: "C0001",
+			wantErr:   ErrBadParameter,
+		},
+		{
+			name:      "channel not found",
+			channelID: "notfound",
+			users:     []string{"U0001"},
+			wantErr:   ErrSlackChannelNotFound,
+		},
+		{
+			name:      "slack error",
+			channelID: "C0001",
+			users:     []string{"U0001"},
+			err:       errors.New("boom"), //nolint:err113
+			wantErr:   ErrSlackAPI,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockSlackService{Error: tt.err}
+
+			c := &Client{
+				logger:       zap.NewNop(),
+				slackService: svc,
+			}
+
+			err := c.InviteToChannel(context.TODO(), tt.channelID, tt.users)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Client.InviteToChannel() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr == nil && tt.channelID == "C0001" && !reflect.DeepEqual(svc.invited, tt.users) {
+				t.Errorf("Client.InviteToChannel() invited = %v, want %v", svc.invited, tt.users)
+			}
+		})
+	}
+}
+
+func TestClient_KickFromChannel(t *testing.T) {
+	tests := []struct {
+		name      string
+		channelID string
+		userID    string
+		err       error
+		wantErr   error
+	}{
+		{
+			name:      "successful kick",
+			channelID: "C0001",
+			userID:    "U0001",
+		},
+		{
+			name:      "not in channel",
+			channelID: "notmember",
+			userID:    "U0001",
+		},
+		{
+			name:      "empty user id",
+			channelID: "C0001",
+			wantErr:   ErrBadParameter,
+		},
+		{
+			name:      "channel not found",
+			channelID: "notfound",
+			userID:    "U0001",
+			wantErr:   ErrSlackChannelNotFound,
+		},
+		{
+			name:      "slack error",
+			channelID: "C0001",
+			userID:    "U0001",
+			err:       errors.New("boom"), //nolint:err113
+			wantErr:   ErrSlackAPI,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				logger:       zap.NewNop(),
+				slackService: &mockSlackService{Error: tt.err},
+			}
+
+			if err := c.KickFromChannel(context.TODO(), tt.channelID, tt.userID); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Client.KickFromChannel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,14 +3,27 @@ package slack
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/slack-go/slack"
 )
 
 // list of error messages returned by the slack api
 const (
+	SlackErrorAccountInactive   = "account_inactive"
+	SlackErrorAlreadyInChannel  = "already_in_channel"
+	SlackErrorCantKickSelf      = "cant_kick_self"
+	SlackErrorChannelNotFound   = "channel_not_found"
+	SlackErrorInvalidAuth       = "invalid_auth"
 	SlackErrorNameAlreadyExists = "name_already_exists"
 	SlackErrorNoSuchSubteam     = "no_such_subteam"
+	SlackErrorNotAuthed         = "not_authed"
+	SlackErrorNotInChannel      = "not_in_channel"
 	SlackErrorSubteamNotFound   = "subteam_not_found"
 	SlackErrorTeamNotFound      = "team_not_found"
+	SlackErrorTokenExpired      = "token_expired"
+	SlackErrorTokenRevoked      = "token_revoked"
 	SlackErrorUserNotFound      = "user_not_found"
 	SlackErrorUsersNotFound     = "users_not_found"
 )
@@ -25,6 +38,9 @@ var (
 	// ErrMissingUserGroupParameter is returned when there are missing user group request parameters
 	ErrMissingUserGroupParameter = errors.New("missing required user group parameters in request")
 
+	// ErrSlackChannelNotFound is returned when the slack channel is not found
+	ErrSlackChannelNotFound = errors.New("slack channel not found")
+
 	// ErrSlackGroupAlreadyExists is returned when the slack user group already exists
 	ErrSlackGroupAlreadyExists = errors.New("slack user group already exists")
 
@@ -48,3 +64,34 @@ var (
 func apiError(op string, err error) error {
 	return fmt.Errorf("%w: %s: %w", ErrSlackAPI, op, err)
 }
+
+// authErrors are the slack api errors returned when the token can't be used anymore
+var authErrors = []string{
+	SlackErrorAccountInactive,
+	SlackErrorInvalidAuth,
+	SlackErrorNotAuthed,
+	SlackErrorTokenExpired,
+	SlackErrorTokenRevoked,
+}
+
+// IsAuthError returns true if the error is a slack api error caused by an invalid, revoked or expired token
+func IsAuthError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var resp slack.SlackErrorResponse
+	if errors.As(err, &resp) {
+		return slices.Contains(authErrors, resp.Err)
+	}
+
+	msg := err.Error()
+
+	for _, e := range authErrors {
+		if msg == e || strings.HasSuffix(msg, ": "+e) {
+			return true
+		}
+	}
+
+	return false
+}
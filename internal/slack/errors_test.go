@@ -0,0 +1,46 @@
+package slack
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestIsAuthError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name: "slack error response",
+			err:  apiError("list workspaces", slack.SlackErrorResponse{Err: SlackErrorInvalidAuth}),
+			want: true,
+		},
+		{
+			name: "plain slack error",
+			err:  apiError("get user groups", errors.New("token_revoked")), //nolint:err113
+			want: true,
+		},
+		{
+			name: "other slack error",
+			err:  apiError("get user groups", slack.SlackErrorResponse{Err: SlackErrorTeamNotFound}),
+		},
+		{
+			name: "not a slack error",
+			err:  ErrSlackUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsAuthError(tt.err); got != tt.want {
+				t.Errorf("IsAuthError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
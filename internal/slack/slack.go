@@ -2,9 +2,12 @@ package slack
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	"github.com/slack-go/slack"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 	"go.uber.org/zap"
 )
 
@@ -12,6 +15,9 @@ import (
 type Client struct {
 	logger       *zap.Logger
 	token        string
+	apiURL       string
+	httpClient   *http.Client
+	tracer       trace.Tracer
 	slackService slackService
 }
 
@@ -23,6 +29,7 @@ const (
 )
 
 type slackService interface {
+	AuthTestContext(context.Context) (*slack.AuthTestResponse, error)
 	CreateUserGroupContext(context.Context, slack.UserGroup, ...slack.CreateUserGroupOption) (slack.UserGroup, error)
 	DisableUserGroupContext(context.Context, string, ...slack.DisableUserGroupOption) (slack.UserGroup, error)
 	EnableUserGroupContext(context.Context, string, ...slack.EnableUserGroupOption) (slack.UserGroup, error)
@@ -30,7 +37,13 @@ type slackService interface {
 	GetUserGroupsContext(context.Context, ...slack.GetUserGroupsOption) ([]slack.UserGroup, error)
 	GetUserInfoContext(context.Context, string) (*slack.User, error)
 	GetUserByEmailContext(context.Context, string) (*slack.User, error)
+	GetUserProfileContext(context.Context, *slack.GetUserProfileParameters) (*slack.UserProfile, error)
+	GetUsersContext(context.Context, ...slack.GetUsersOption) ([]slack.User, error)
+	GetUsersInConversationContext(context.Context, *slack.GetUsersInConversationParameters) ([]string, string, error)
+	InviteUsersToConversationContext(context.Context, string, ...string) (*slack.Channel, error)
+	KickUserFromConversationContext(context.Context, string, string) error
 	ListTeamsContext(ctx context.Context, params slack.ListTeamsParameters) ([]slack.Team, string, error)
+	PostMessageContext(context.Context, string, ...slack.MsgOption) (string, string, error)
 	UpdateUserGroupContext(context.Context, string, ...slack.UpdateUserGroupsOption) (slack.UserGroup, error)
 	UpdateUserGroupMembersContext(context.Context, string, string, ...slack.UpdateUserGroupMembersOption) (slack.UserGroup, error)
 }
@@ -52,17 +65,37 @@ func WithLogger(l *zap.Logger) Option {
 	}
 }
 
+// WithHTTPClient sets the http client used for the requests to the slack api, e.g. to instrument them
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithTracer sets the tracer used to make a span for each slack api call
+func WithTracer(t trace.Tracer) Option {
+	return func(c *Client) {
+		c.tracer = t
+	}
+}
+
 // NewClient returns a new Slack client
 func NewClient(opts ...Option) *Client {
 	client := Client{
-		logger: zap.NewNop(),
+		logger:     zap.NewNop(),
+		apiURL:     slack.APIURL,
+		httpClient: http.DefaultClient,
+		tracer:     noop.NewTracerProvider().Tracer("slack"),
 	}
 
 	for _, opt := range opts {
 		opt(&client)
 	}
 
-	client.slackService = slack.New(client.token)
+	client.slackService = &tracedService{
+		next:   slack.New(client.token, slack.OptionHTTPClient(client.httpClient)),
+		tracer: client.tracer,
+	}
 
 	return &client
 }
@@ -12,6 +12,9 @@ type mockSlackService struct {
 	Error error
 
 	userResp      *slack.User
+	profileResp   *slack.UserProfile
+	usersResp     []slack.User
+	invited       []string
 	userGroupResp *slack.UserGroup
 }
 
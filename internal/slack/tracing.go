@@ -0,0 +1,217 @@
+package slack
+
+import (
+	"context"
+	"errors"
+
+	"github.com/slack-go/slack"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/metal-toolbox/gov-slack-addon/internal/tracing"
+)
+
+// span attribute keys of the slack api calls
+const (
+	attrMethod      = attribute.Key("slack.method")
+	attrWorkspaceID = attribute.Key("slack.workspace.id")
+	attrUserGroupID = attribute.Key("slack.usergroup.id")
+	attrChannelID   = attribute.Key("slack.channel.id")
+	attrUserID      = attribute.Key("slack.user.id")
+	attrRateLimited = attribute.Key("slack.rate_limited")
+	attrRetryAfter  = attribute.Key("slack.rate_limit.retry_after_seconds")
+)
+
+// workspaceKey is the context key of the workspace of the slack api calls
+type workspaceKey struct{}
+
+// withWorkspace records the workspace (team) the slack api calls made with the context are made in, so
+// it's added to their spans
+func withWorkspace(ctx context.Context, teamID string) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, teamID)
+}
+
+// tracedService is a slackService that makes a span for each slack api call
+type tracedService struct {
+	next   slackService
+	tracer trace.Tracer
+}
+
+// start starts the span of a call to the slack api method
+func (s *tracedService) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return startSpan(ctx, s.tracer, method, attrs...)
+}
+
+// startSpan starts the span of a call to the slack api method
+func startSpan(ctx context.Context, tracer trace.Tracer, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if teamID, ok := ctx.Value(workspaceKey{}).(string); ok && teamID != "" {
+		attrs = append(attrs, attrWorkspaceID.String(teamID))
+	}
+
+	return tracer.Start(ctx, "slack "+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(append(attrs, attrMethod.String(method))...),
+	)
+}
+
+// endSpan records the error of the slack api call on the span and ends it. When the call was rate
+// limited, the time slack asked to wait before retrying is recorded as well.
+func endSpan(span trace.Span, err error) {
+	var rateLimited *slack.RateLimitedError
+
+	if errors.As(err, &rateLimited) {
+		span.SetAttributes(attrRateLimited.Bool(true), attrRetryAfter.Float64(rateLimited.RetryAfter.Seconds()))
+	}
+
+	tracing.End(span, err)
+}
+
+func (s *tracedService) AuthTestContext(ctx context.Context) (resp *slack.AuthTestResponse, err error) {
+	ctx, span := s.start(ctx, "auth.test")
+	defer func() { endSpan(span, err) }()
+
+	return s.next.AuthTestContext(ctx)
+}
+
+func (s *tracedService) CreateUserGroupContext(
+	ctx context.Context,
+	ug slack.UserGroup,
+	opts ...slack.CreateUserGroupOption,
+) (out slack.UserGroup, err error) {
+	ctx, span := s.start(ctx, "usergroups.create")
+	defer func() { endSpan(span, err) }()
+
+	return s.next.CreateUserGroupContext(ctx, ug, opts...)
+}
+
+func (s *tracedService) DisableUserGroupContext(
+	ctx context.Context,
+	groupID string,
+	opts ...slack.DisableUserGroupOption,
+) (out slack.UserGroup, err error) {
+	ctx, span := s.start(ctx, "usergroups.disable", attrUserGroupID.String(groupID))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.DisableUserGroupContext(ctx, groupID, opts...)
+}
+
+func (s *tracedService) EnableUserGroupContext(
+	ctx context.Context,
+	groupID string,
+	opts ...slack.EnableUserGroupOption,
+) (out slack.UserGroup, err error) {
+	ctx, span := s.start(ctx, "usergroups.enable", attrUserGroupID.String(groupID))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.EnableUserGroupContext(ctx, groupID, opts...)
+}
+
+func (s *tracedService) GetUserGroupMembersContext(
+	ctx context.Context,
+	groupID string,
+	opts ...slack.GetUserGroupMembersOption,
+) (out []string, err error) {
+	ctx, span := s.start(ctx, "usergroups.users.list", attrUserGroupID.String(groupID))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.GetUserGroupMembersContext(ctx, groupID, opts...)
+}
+
+func (s *tracedService) GetUserGroupsContext(ctx context.Context, opts ...slack.GetUserGroupsOption) (out []slack.UserGroup, err error) {
+	ctx, span := s.start(ctx, "usergroups.list")
+	defer func() { endSpan(span, err) }()
+
+	return s.next.GetUserGroupsContext(ctx, opts...)
+}
+
+func (s *tracedService) GetUserInfoContext(ctx context.Context, id string) (out *slack.User, err error) {
+	ctx, span := s.start(ctx, "users.info", attrUserID.String(id))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.GetUserInfoContext(ctx, id)
+}
+
+func (s *tracedService) GetUserByEmailContext(ctx context.Context, email string) (out *slack.User, err error) {
+	// the email isn't recorded, spans shouldn't hold personal data
+	ctx, span := s.start(ctx, "users.lookupByEmail")
+	defer func() { endSpan(span, err) }()
+
+	return s.next.GetUserByEmailContext(ctx, email)
+}
+
+func (s *tracedService) GetUserProfileContext(
+	ctx context.Context,
+	params *slack.GetUserProfileParameters,
+) (out *slack.UserProfile, err error) {
+	ctx, span := s.start(ctx, "users.profile.get", attrUserID.String(params.UserID))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.GetUserProfileContext(ctx, params)
+}
+
+func (s *tracedService) GetUsersContext(ctx context.Context, opts ...slack.GetUsersOption) (out []slack.User, err error) {
+	ctx, span := s.start(ctx, "users.list")
+	defer func() { endSpan(span, err) }()
+
+	return s.next.GetUsersContext(ctx, opts...)
+}
+
+func (s *tracedService) GetUsersInConversationContext(
+	ctx context.Context,
+	params *slack.GetUsersInConversationParameters,
+) (out []string, cursor string, err error) {
+	ctx, span := s.start(ctx, "conversations.members", attrChannelID.String(params.ChannelID))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.GetUsersInConversationContext(ctx, params)
+}
+
+func (s *tracedService) InviteUsersToConversationContext(ctx context.Context, channelID string, users ...string) (out *slack.Channel, err error) {
+	ctx, span := s.start(ctx, "conversations.invite", attrChannelID.String(channelID))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.InviteUsersToConversationContext(ctx, channelID, users...)
+}
+
+func (s *tracedService) KickUserFromConversationContext(ctx context.Context, channelID, user string) (err error) {
+	ctx, span := s.start(ctx, "conversations.kick", attrChannelID.String(channelID), attrUserID.String(user))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.KickUserFromConversationContext(ctx, channelID, user)
+}
+
+func (s *tracedService) ListTeamsContext(ctx context.Context, params slack.ListTeamsParameters) (out []slack.Team, cursor string, err error) {
+	ctx, span := s.start(ctx, "auth.teams.list")
+	defer func() { endSpan(span, err) }()
+
+	return s.next.ListTeamsContext(ctx, params)
+}
+
+func (s *tracedService) PostMessageContext(ctx context.Context, channelID string, opts ...slack.MsgOption) (ch, ts string, err error) {
+	ctx, span := s.start(ctx, "chat.postMessage", attrChannelID.String(channelID))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.PostMessageContext(ctx, channelID, opts...)
+}
+
+func (s *tracedService) UpdateUserGroupContext(
+	ctx context.Context,
+	groupID string,
+	opts ...slack.UpdateUserGroupsOption,
+) (out slack.UserGroup, err error) {
+	ctx, span := s.start(ctx, "usergroups.update", attrUserGroupID.String(groupID))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.UpdateUserGroupContext(ctx, groupID, opts...)
+}
+
+func (s *tracedService) UpdateUserGroupMembersContext(
+	ctx context.Context,
+	groupID, members string,
+	opts ...slack.UpdateUserGroupMembersOption,
+) (out slack.UserGroup, err error) {
+	ctx, span := s.start(ctx, "usergroups.users.update", attrUserGroupID.String(groupID))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.UpdateUserGroupMembersContext(ctx, groupID, members, opts...)
+}
@@ -0,0 +1,123 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
+)
+
+// recordedSpan is a span that records its name, attributes and status
+type recordedSpan struct {
+	noop.Span
+
+	name   string
+	attrs  map[attribute.Key]attribute.Value
+	status codes.Code
+	ended  bool
+}
+
+func (s *recordedSpan) SetAttributes(kv ...attribute.KeyValue) {
+	for _, a := range kv {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *recordedSpan) SetStatus(c codes.Code, _ string) {
+	s.status = c
+}
+
+func (s *recordedSpan) End(...trace.SpanEndOption) {
+	s.ended = true
+}
+
+// recordingTracer is a tracer that records the spans it starts
+type recordingTracer struct {
+	noop.Tracer
+
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	span := &recordedSpan{name: name, attrs: map[attribute.Key]attribute.Value{}}
+	cfg := trace.NewSpanStartConfig(opts...)
+	span.SetAttributes(cfg.Attributes()...)
+
+	t.spans = append(t.spans, span)
+
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+func TestTracedService(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantStatus     codes.Code
+		wantRetryAfter float64
+	}{
+		{
+			name:       "success",
+			wantStatus: codes.Unset,
+		},
+		{
+			name:       "error",
+			err:        errors.New(SlackErrorNoSuchSubteam), //nolint:err113
+			wantStatus: codes.Error,
+		},
+		{
+			name:           "rate limited",
+			err:            &slack.RateLimitedError{RetryAfter: 30 * time.Second},
+			wantStatus:     codes.Error,
+			wantRetryAfter: 30,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer := &recordingTracer{}
+
+			c := &Client{
+				logger: zap.NewNop(),
+				slackService: &tracedService{
+					next:   &mockSlackService{Error: tt.err, userGroupResp: &slack.UserGroup{ID: "S0001"}},
+					tracer: tracer,
+				},
+			}
+
+			_, _ = c.UpdateUserGroupMembers(context.TODO(), "S0001", "T0001", []string{"U0001"})
+
+			if len(tracer.spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(tracer.spans))
+			}
+
+			span := tracer.spans[0]
+
+			if span.name != "slack usergroups.users.update" || !span.ended {
+				t.Errorf("unexpected span %q, ended %t", span.name, span.ended)
+			}
+
+			if got := span.attrs[attrWorkspaceID].AsString(); got != "T0001" {
+				t.Errorf("expected workspace attribute T0001, got %q", got)
+			}
+
+			if got := span.attrs[attrUserGroupID].AsString(); got != "S0001" {
+				t.Errorf("expected user group attribute S0001, got %q", got)
+			}
+
+			if span.status != tt.wantStatus {
+				t.Errorf("expected span status %v, got %v", tt.wantStatus, span.status)
+			}
+
+			if got := span.attrs[attrRetryAfter].AsFloat64(); got != tt.wantRetryAfter {
+				t.Errorf("expected retry after %v, got %v", tt.wantRetryAfter, got)
+			}
+		})
+	}
+}
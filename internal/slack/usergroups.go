@@ -13,10 +13,15 @@ type UserGroupReq struct {
 	Name        *string
 	Handle      *string
 	Description *string
+	// Channels are the default channels of the user group, the default channels are left
+	// unchanged if nil and removed if empty
+	Channels *[]string
 }
 
 // CreateUserGroup creates a new user group in a workspace
 func (c *Client) CreateUserGroup(ctx context.Context, teamID string, userGroup *UserGroupReq) (*slack.UserGroup, error) {
+	ctx = withWorkspace(ctx, teamID)
+
 	if teamID == "" {
 		return nil, ErrBadParameter
 	}
@@ -40,6 +45,10 @@ func (c *Client) CreateUserGroup(ctx context.Context, teamID string, userGroup *
 		ugReq.Description = *userGroup.Description
 	}
 
+	if userGroup.Channels != nil {
+		ugReq.Prefs.Channels = *userGroup.Channels
+	}
+
 	ug, err := c.slackService.CreateUserGroupContext(ctx, ugReq)
 	if err != nil {
 		if err.Error() == SlackErrorNameAlreadyExists {
@@ -56,6 +65,8 @@ func (c *Client) CreateUserGroup(ctx context.Context, teamID string, userGroup *
 
 // DisableUserGroup disables a user group. Slack does not support deleting a group.
 func (c *Client) DisableUserGroup(ctx context.Context, groupID, teamID string) (*slack.UserGroup, error) {
+	ctx = withWorkspace(ctx, teamID)
+
 	if groupID == "" || teamID == "" {
 		return nil, ErrBadParameter
 	}
@@ -83,6 +94,8 @@ func (c *Client) DisableUserGroup(ctx context.Context, groupID, teamID string) (
 
 // EnableUserGroup enables a disabled user group
 func (c *Client) EnableUserGroup(ctx context.Context, groupID, teamID string) (*slack.UserGroup, error) {
+	ctx = withWorkspace(ctx, teamID)
+
 	if groupID == "" || teamID == "" {
 		return nil, ErrBadParameter
 	}
@@ -110,6 +123,8 @@ func (c *Client) EnableUserGroup(ctx context.Context, groupID, teamID string) (*
 
 // GetUserGroups gets all user groups in a workspace (given the workspace/team id)
 func (c *Client) GetUserGroups(ctx context.Context, teamID string, includeDisabled bool) ([]slack.UserGroup, error) {
+	ctx = withWorkspace(ctx, teamID)
+
 	if teamID == "" {
 		return nil, ErrBadParameter
 	}
@@ -139,6 +154,8 @@ func (c *Client) GetUserGroups(ctx context.Context, teamID string, includeDisabl
 
 // GetUserGroupMembers returns the members of a user group
 func (c *Client) GetUserGroupMembers(ctx context.Context, groupID, teamID string, includeDisabled bool) ([]string, error) {
+	ctx = withWorkspace(ctx, teamID)
+
 	if groupID == "" || teamID == "" {
 		return nil, ErrBadParameter
 	}
@@ -170,11 +187,13 @@ func (c *Client) GetUserGroupMembers(ctx context.Context, groupID, teamID string
 
 // UpdateUserGroup updates one or more parameters of a user group
 func (c *Client) UpdateUserGroup(ctx context.Context, groupID, teamID string, userGroup UserGroupReq) (*slack.UserGroup, error) {
+	ctx = withWorkspace(ctx, teamID)
+
 	if groupID == "" || teamID == "" {
 		return nil, ErrBadParameter
 	}
 
-	if userGroup.Name == nil && userGroup.Handle == nil && userGroup.Description == nil {
+	if userGroup.Name == nil && userGroup.Handle == nil && userGroup.Description == nil && userGroup.Channels == nil {
 		return nil, ErrMissingUserGroupParameter
 	}
 
@@ -200,6 +219,10 @@ func (c *Client) UpdateUserGroup(ctx context.Context, groupID, teamID string, us
 		opts = append(opts, slack.UpdateUserGroupsOptionDescription(userGroup.Description))
 	}
 
+	if userGroup.Channels != nil {
+		opts = append(opts, slack.UpdateUserGroupsOptionChannels(*userGroup.Channels))
+	}
+
 	ug, err := c.slackService.UpdateUserGroupContext(ctx, groupID, opts...)
 	if err != nil {
 		return nil, apiError("update user group", err)
@@ -213,6 +236,8 @@ func (c *Client) UpdateUserGroup(ctx context.Context, groupID, teamID string, us
 // UpdateUserGroupMembers updates the members of a user group. You cannot pass an empty members list as the
 // Slack API doesn't allow removing all members of a group.
 func (c *Client) UpdateUserGroupMembers(ctx context.Context, groupID, teamID string, members []string) (*slack.UserGroup, error) {
+	ctx = withWorkspace(ctx, teamID)
+
 	if groupID == "" || teamID == "" {
 		return nil, ErrBadParameter
 	}
@@ -26,6 +26,7 @@ func (m *mockSlackService) CreateUserGroupContext(_ context.Context, ug slack.Us
 		Name:        ug.Name,
 		Handle:      ug.Handle,
 		Description: ug.Description,
+		Prefs:       ug.Prefs,
 	}, nil
 }
 
@@ -92,13 +93,22 @@ func (m *mockSlackService) UpdateUserGroupContext(_ context.Context, groupID str
 		opt(params)
 	}
 
-	return slack.UserGroup{
-		ID:          groupID,
-		TeamID:      params.TeamID,
-		Name:        params.Name,
-		Handle:      params.Handle,
-		Description: *params.Description,
-	}, nil
+	ug := slack.UserGroup{
+		ID:     groupID,
+		TeamID: params.TeamID,
+		Name:   params.Name,
+		Handle: params.Handle,
+	}
+
+	if params.Description != nil {
+		ug.Description = *params.Description
+	}
+
+	if params.Channels != nil {
+		ug.Prefs.Channels = *params.Channels
+	}
+
+	return ug, nil
 }
 
 func (m *mockSlackService) UpdateUserGroupMembersContext(_ context.Context, groupID, members string, opts ...slack.UpdateUserGroupMembersOption) (slack.UserGroup, error) {
@@ -155,6 +165,24 @@ func TestClient_CreateUserGroup(t *testing.T) {
 			},
 			want: testResp,
 		},
+		{
+			name: "successful create user group with default channels",
+			args: args{
+				teamID: "T0123",
+				userGroup: &UserGroupReq{
+					Name:     stringPtr("Group 1"),
+					Handle:   stringPtr("group1"),
+					Channels: &[]string{"C0001"},
+				},
+			},
+			want: &slack.UserGroup{
+				ID:     "G0001",
+				TeamID: "T0123",
+				Name:   "Group 1",
+				Handle: "group1",
+				Prefs:  slack.UserGroupPrefs{Channels: []string{"C0001"}},
+			},
+		},
 		{
 			name:    "empty team id",
 			args:    args{teamID: ""},
@@ -557,6 +585,21 @@ func TestClient_UpdateUserGroup(t *testing.T) {
 			},
 			want: testResp,
 		},
+		{
+			name: "successful update default channels",
+			args: args{
+				groupID: "G0001",
+				teamID:  "T0123",
+				userGroup: UserGroupReq{
+					Channels: &[]string{"C0001", "C0002"},
+				},
+			},
+			want: &slack.UserGroup{
+				ID:     "G0001",
+				TeamID: "T0123",
+				Prefs:  slack.UserGroupPrefs{Channels: []string{"C0001", "C0002"}},
+			},
+		},
 		{
 			name:    "empty group id",
 			args:    args{groupID: "", teamID: "T0123"},
@@ -84,3 +84,60 @@ func (c *Client) GetUserByEmail(ctx context.Context, email string) (*slack.User,
 
 	return user, nil
 }
+
+// GetUserProfile gets the profile of a slack user by id, including the custom profile fields that
+// aren't returned when listing users
+func (c *Client) GetUserProfile(ctx context.Context, id string) (*slack.UserProfile, error) {
+	if id == "" {
+		return nil, ErrBadParameter
+	}
+
+	c.logger.Debug("getting slack user profile", zap.String("user.id", id))
+
+	profile, err := c.slackService.GetUserProfileContext(ctx, &slack.GetUserProfileParameters{UserID: id})
+	if err != nil {
+		if err.Error() == SlackErrorUserNotFound {
+			return nil, ErrSlackUserNotFound
+		}
+
+		return nil, apiError("get user profile", err)
+	}
+
+	return profile, nil
+}
+
+// ListUsers returns all the users in the slack organization
+func (c *Client) ListUsers(ctx context.Context) ([]slack.User, error) {
+	c.logger.Debug("listing slack users")
+
+	users, err := c.slackService.GetUsersContext(ctx)
+	if err != nil {
+		return nil, apiError("list users", err)
+	}
+
+	c.logger.Debug("returning slack users", zap.Int("slack.users.count", len(users)))
+
+	return users, nil
+}
+
+// UserInWorkspace returns true if the given slack user is a member of the workspace (team).
+// On Enterprise Grid users exist at the organization level and list the workspaces they
+// have joined; if that list isn't returned by slack we assume the user is a member. Passing
+// the organization id checks the user is a member of the organization.
+func UserInWorkspace(user *slack.User, teamID string) bool {
+	if user == nil || teamID == "" {
+		return false
+	}
+
+	if user.TeamID == teamID || user.Enterprise.EnterpriseID == teamID || len(user.Enterprise.Teams) == 0 {
+		return true
+	}
+
+	for _, t := range user.Enterprise.Teams {
+		if t == teamID {
+			return true
+		}
+	}
+
+	return false
+}
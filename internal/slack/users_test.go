@@ -34,6 +34,26 @@ func (m *mockSlackService) GetUserByEmailContext(_ context.Context, id string) (
 	return m.userResp, nil
 }
 
+func (m *mockSlackService) GetUserProfileContext(_ context.Context, params *slack.GetUserProfileParameters) (*slack.UserProfile, error) {
+	if m.Error != nil {
+		return nil, m.Error
+	}
+
+	if params.UserID == "notfound" {
+		return nil, errors.New("user_not_found") //nolint:err113
+	}
+
+	return m.profileResp, nil
+}
+
+func (m *mockSlackService) GetUsersContext(_ context.Context, _ ...slack.GetUsersOption) ([]slack.User, error) {
+	if m.Error != nil {
+		return nil, m.Error
+	}
+
+	return m.usersResp, nil
+}
+
 func TestClient_GetUser(t *testing.T) {
 	type args struct {
 		id string
@@ -163,3 +183,175 @@ func TestClient_GetUserByEmail(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_GetUserProfile(t *testing.T) {
+	profile := &slack.UserProfile{Email: "user1@example.com"}
+
+	tests := []struct {
+		name    string
+		id      string
+		err     error
+		want    *slack.UserProfile
+		wantErr error
+	}{
+		{
+			name: "successful profile by id",
+			id:   "U0001",
+			want: profile,
+		},
+		{
+			name:    "empty id",
+			wantErr: ErrBadParameter,
+		},
+		{
+			name:    "user not found",
+			id:      "notfound",
+			wantErr: ErrSlackUserNotFound,
+		},
+		{
+			name:    "slack error",
+			id:      "U0001",
+			err:     errors.New("boom"), //nolint:err113
+			wantErr: ErrSlackAPI,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				logger:       zap.NewNop(),
+				slackService: &mockSlackService{Error: tt.err, profileResp: profile},
+			}
+
+			got, err := c.GetUserProfile(context.TODO(), tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Client.GetUserProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Client.GetUserProfile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserInWorkspace(t *testing.T) {
+	type args struct {
+		user   *slack.User
+		teamID string
+	}
+
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{
+			name: "user home workspace",
+			args: args{user: &slack.User{ID: "U0001", TeamID: "T0001"}, teamID: "T0001"},
+			want: true,
+		},
+		{
+			name: "grid user in workspace",
+			args: args{
+				user: &slack.User{
+					ID:         "U0001",
+					TeamID:     "E0001",
+					Enterprise: slack.EnterpriseUser{Teams: []string{"T0001", "T0002"}},
+				},
+				teamID: "T0002",
+			},
+			want: true,
+		},
+		{
+			name: "grid user not in workspace",
+			args: args{
+				user: &slack.User{
+					ID:         "U0001",
+					TeamID:     "E0001",
+					Enterprise: slack.EnterpriseUser{Teams: []string{"T0001"}},
+				},
+				teamID: "T0002",
+			},
+			want: false,
+		},
+		{
+			name: "grid user in organization",
+			args: args{
+				user: &slack.User{
+					ID:         "U0001",
+					TeamID:     "T0001",
+					Enterprise: slack.EnterpriseUser{EnterpriseID: "E0001", Teams: []string{"T0001"}},
+				},
+				teamID: "E0001",
+			},
+			want: true,
+		},
+		{
+			name: "no workspace list",
+			args: args{user: &slack.User{ID: "U0001", TeamID: "E0001"}, teamID: "T0002"},
+			want: true,
+		},
+		{
+			name: "nil user",
+			args: args{teamID: "T0001"},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UserInWorkspace(tt.args.user, tt.args.teamID); got != tt.want {
+				t.Errorf("UserInWorkspace() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_ListUsers(t *testing.T) {
+	testResp := []slack.User{
+		{ID: "U0001", Name: "User 1"},
+		{ID: "U0002", Name: "User 2"},
+	}
+
+	tests := []struct {
+		name    string
+		err     error
+		resp    []slack.User
+		want    []slack.User
+		wantErr bool
+	}{
+		{
+			name: "successful list users",
+			resp: testResp,
+			want: testResp,
+		},
+		{
+			name:    "slack error",
+			err:     errors.New("boom"), //nolint:err113
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				logger: zap.NewNop(),
+				slackService: &mockSlackService{
+					Error:     tt.err,
+					usersResp: tt.resp,
+				},
+			}
+
+			got, err := c.ListUsers(context.TODO())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.ListUsers() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Client.ListUsers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
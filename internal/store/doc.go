@@ -0,0 +1,4 @@
+// Package store keeps the addon state that can't be derived from slack or governor, such as the
+// slack user group bound to each governor group. State is kept in a NATS JetStream KV bucket, or
+// in memory when no bucket is configured.
+package store
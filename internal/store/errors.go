@@ -0,0 +1,11 @@
+package store
+
+import "errors"
+
+var (
+	// ErrBadParameter is returned when bad parameters are passed to the store
+	ErrBadParameter = errors.New("bad parameters in request")
+
+	// ErrNotFound is returned when the requested record doesn't exist
+	ErrNotFound = errors.New("record not found")
+)
@@ -0,0 +1,350 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+)
+
+// KeyValue is a store backed by a NATS JetStream key-value bucket, so the state is shared by
+// all the addon replicas and survives restarts. The mappings are indexed by slack user group id.
+type KeyValue struct {
+	kv nats.KeyValue
+
+	// indexMu guards indexed, set once the mappings written before the user group index existed
+	// have been indexed
+	indexMu sync.Mutex
+	indexed bool
+}
+
+// mappingRef is the value of a user group index entry, naming the mapping of the user group
+type mappingRef struct {
+	GovernorGroupID string `json:"governor_group_id"`
+	TeamID          string `json:"team_id"`
+}
+
+// KeyValue implements the Store interface
+var _ Store = (*KeyValue)(nil)
+
+// NewKeyValue returns a store using the given key-value bucket
+func NewKeyValue(kv nats.KeyValue) *KeyValue {
+	return &KeyValue{kv: kv}
+}
+
+// GetMapping returns the user group mapping of the governor group in the slack team
+func (s *KeyValue) GetMapping(ctx context.Context, groupID, teamID string) (*UserGroupMapping, error) {
+	if groupID == "" || teamID == "" {
+		return nil, ErrBadParameter
+	}
+
+	m := &UserGroupMapping{}
+	if err := s.get(ctx, mappingKey(groupID, teamID), m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// PutMapping creates or replaces a user group mapping
+func (s *KeyValue) PutMapping(ctx context.Context, m *UserGroupMapping) error {
+	if m == nil || m.GovernorGroupID == "" || m.TeamID == "" {
+		return ErrBadParameter
+	}
+
+	if err := s.put(ctx, mappingKey(m.GovernorGroupID, m.TeamID), m); err != nil {
+		return err
+	}
+
+	if m.UserGroupID == "" {
+		return nil
+	}
+
+	return s.put(ctx, userGroupKey(m.UserGroupID), &mappingRef{GovernorGroupID: m.GovernorGroupID, TeamID: m.TeamID})
+}
+
+// DeleteMapping removes the user group mapping of the governor group in the slack team
+func (s *KeyValue) DeleteMapping(ctx context.Context, groupID, teamID string) error {
+	if groupID == "" || teamID == "" {
+		return ErrBadParameter
+	}
+
+	m, err := s.GetMapping(ctx, groupID, teamID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	if err := s.delete(ctx, mappingKey(groupID, teamID)); err != nil {
+		return err
+	}
+
+	if m == nil || m.UserGroupID == "" {
+		return nil
+	}
+
+	return s.delete(ctx, userGroupKey(m.UserGroupID))
+}
+
+// ListMappings returns all the user group mappings, sorted by key
+func (s *KeyValue) ListMappings(ctx context.Context) ([]*UserGroupMapping, error) {
+	keys, err := s.keys(ctx, mappingPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*UserGroupMapping, 0, len(keys))
+
+	for _, k := range keys {
+		m := &UserGroupMapping{}
+
+		if err := s.get(ctx, k, m); err != nil {
+			// the key may have been deleted since it was listed
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		out = append(out, m)
+	}
+
+	return out, nil
+}
+
+// GetMappingByUserGroup returns the mapping of the slack user group, found with the user group index.
+// The index entry is only used if the mapping still points to the user group.
+func (s *KeyValue) GetMappingByUserGroup(ctx context.Context, userGroupID string) (*UserGroupMapping, error) {
+	if userGroupID == "" {
+		return nil, ErrBadParameter
+	}
+
+	if err := s.indexMappings(ctx); err != nil {
+		return nil, err
+	}
+
+	ref := &mappingRef{}
+	if err := s.get(ctx, userGroupKey(userGroupID), ref); err != nil {
+		return nil, err
+	}
+
+	m, err := s.GetMapping(ctx, ref.GovernorGroupID, ref.TeamID)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.UserGroupID != userGroupID {
+		return nil, ErrNotFound
+	}
+
+	return m, nil
+}
+
+// indexMappings adds the mappings written before the user group index existed to the index. It's
+// done once, on the first lookup by user group.
+func (s *KeyValue) indexMappings(ctx context.Context) error {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	if s.indexed {
+		return nil
+	}
+
+	mappings, err := s.ListMappings(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range mappings {
+		if m.UserGroupID == "" {
+			continue
+		}
+
+		ref := &mappingRef{}
+
+		err := s.get(ctx, userGroupKey(m.UserGroupID), ref)
+		if err == nil && ref.GovernorGroupID == m.GovernorGroupID && ref.TeamID == m.TeamID {
+			continue
+		}
+
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+
+		if err := s.put(ctx, userGroupKey(m.UserGroupID), &mappingRef{GovernorGroupID: m.GovernorGroupID, TeamID: m.TeamID}); err != nil {
+			return err
+		}
+	}
+
+	s.indexed = true
+
+	return nil
+}
+
+// GetRetirement returns the retirement record of the user group in the slack team
+func (s *KeyValue) GetRetirement(ctx context.Context, teamID, userGroupID string) (*Retirement, error) {
+	if teamID == "" || userGroupID == "" {
+		return nil, ErrBadParameter
+	}
+
+	r := &Retirement{}
+	if err := s.get(ctx, retirementKey(teamID, userGroupID), r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// PutRetirement creates or replaces a retirement record
+func (s *KeyValue) PutRetirement(ctx context.Context, r *Retirement) error {
+	if r == nil || r.TeamID == "" || r.UserGroupID == "" {
+		return ErrBadParameter
+	}
+
+	return s.put(ctx, retirementKey(r.TeamID, r.UserGroupID), r)
+}
+
+// ListRetirements returns all the retirement records, sorted by key
+func (s *KeyValue) ListRetirements(ctx context.Context) ([]*Retirement, error) {
+	keys, err := s.keys(ctx, retirementPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*Retirement, 0, len(keys))
+
+	for _, k := range keys {
+		r := &Retirement{}
+
+		if err := s.get(ctx, k, r); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		out = append(out, r)
+	}
+
+	return out, nil
+}
+
+// PutMembershipRequest creates or replaces a membership request record
+func (s *KeyValue) PutMembershipRequest(ctx context.Context, r *MembershipRequest) error {
+	if r == nil || r.ID == "" {
+		return ErrBadParameter
+	}
+
+	return s.put(ctx, requestKey(r.ID), r)
+}
+
+// DeleteMembershipRequest removes a membership request record
+func (s *KeyValue) DeleteMembershipRequest(ctx context.Context, id string) error {
+	if id == "" {
+		return ErrBadParameter
+	}
+
+	return s.delete(ctx, requestKey(id))
+}
+
+// ListMembershipRequests returns all the membership request records, sorted by key
+func (s *KeyValue) ListMembershipRequests(ctx context.Context) ([]*MembershipRequest, error) {
+	keys, err := s.keys(ctx, requestPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*MembershipRequest, 0, len(keys))
+
+	for _, k := range keys {
+		r := &MembershipRequest{}
+
+		if err := s.get(ctx, k, r); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		out = append(out, r)
+	}
+
+	return out, nil
+}
+
+// get decodes the json value of the key into v
+func (s *KeyValue) get(ctx context.Context, key string, v any) error {
+	entry, err := s.kv.Get(key)
+	if err != nil {
+		if errors.Is(err, nats.ErrKeyNotFound) {
+			return ErrNotFound
+		}
+
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(entry.Value(), v)
+}
+
+// put stores the json encoding of v under the key
+func (s *KeyValue) put(ctx context.Context, key string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, err = s.kv.Put(key, b)
+
+	return err
+}
+
+// delete removes the key, it's not an error if it doesn't exist
+func (s *KeyValue) delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := s.kv.Delete(key); err != nil && !errors.Is(err, nats.ErrKeyNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+// keys returns the sorted keys with the given prefix
+func (s *KeyValue) keys(ctx context.Context, prefix string) ([]string, error) {
+	all, err := s.kv.Keys(nats.Context(ctx))
+	if err != nil {
+		if errors.Is(err, nats.ErrNoKeysFound) {
+			return []string{}, nil
+		}
+
+		return nil, err
+	}
+
+	keys := []string{}
+
+	for _, k := range all {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
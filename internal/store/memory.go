@@ -0,0 +1,215 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Memory is an in-memory store, its state is lost on restart
+type Memory struct {
+	mu          sync.RWMutex
+	mappings    map[string]UserGroupMapping
+	retirements map[string]Retirement
+	requests    map[string]MembershipRequest
+}
+
+// Memory implements the Store interface
+var _ Store = (*Memory)(nil)
+
+// NewMemory returns an empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{
+		mappings:    make(map[string]UserGroupMapping),
+		retirements: make(map[string]Retirement),
+		requests:    make(map[string]MembershipRequest),
+	}
+}
+
+// GetMapping returns the user group mapping of the governor group in the slack team
+func (s *Memory) GetMapping(_ context.Context, groupID, teamID string) (*UserGroupMapping, error) {
+	if groupID == "" || teamID == "" {
+		return nil, ErrBadParameter
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	m, ok := s.mappings[mappingKey(groupID, teamID)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &m, nil
+}
+
+// PutMapping creates or replaces a user group mapping
+func (s *Memory) PutMapping(_ context.Context, m *UserGroupMapping) error {
+	if m == nil || m.GovernorGroupID == "" || m.TeamID == "" {
+		return ErrBadParameter
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.mappings[mappingKey(m.GovernorGroupID, m.TeamID)] = *m
+
+	return nil
+}
+
+// DeleteMapping removes the user group mapping of the governor group in the slack team
+func (s *Memory) DeleteMapping(_ context.Context, groupID, teamID string) error {
+	if groupID == "" || teamID == "" {
+		return ErrBadParameter
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.mappings, mappingKey(groupID, teamID))
+
+	return nil
+}
+
+// ListMappings returns all the user group mappings, sorted by key
+func (s *Memory) ListMappings(_ context.Context) ([]*UserGroupMapping, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]string, 0, len(s.mappings))
+	for k := range s.mappings {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	out := make([]*UserGroupMapping, 0, len(keys))
+
+	for _, k := range keys {
+		m := s.mappings[k]
+		out = append(out, &m)
+	}
+
+	return out, nil
+}
+
+// GetMappingByUserGroup returns the mapping of the slack user group
+func (s *Memory) GetMappingByUserGroup(_ context.Context, userGroupID string) (*UserGroupMapping, error) {
+	if userGroupID == "" {
+		return nil, ErrBadParameter
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, m := range s.mappings {
+		if m.UserGroupID == userGroupID {
+			return &m, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+// GetRetirement returns the retirement record of the user group in the slack team
+func (s *Memory) GetRetirement(_ context.Context, teamID, userGroupID string) (*Retirement, error) {
+	if teamID == "" || userGroupID == "" {
+		return nil, ErrBadParameter
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, ok := s.retirements[retirementKey(teamID, userGroupID)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &r, nil
+}
+
+// PutRetirement creates or replaces a retirement record
+func (s *Memory) PutRetirement(_ context.Context, r *Retirement) error {
+	if r == nil || r.TeamID == "" || r.UserGroupID == "" {
+		return ErrBadParameter
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.retirements[retirementKey(r.TeamID, r.UserGroupID)] = *r
+
+	return nil
+}
+
+// ListRetirements returns all the retirement records, sorted by key
+func (s *Memory) ListRetirements(_ context.Context) ([]*Retirement, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]string, 0, len(s.retirements))
+	for k := range s.retirements {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	out := make([]*Retirement, 0, len(keys))
+
+	for _, k := range keys {
+		r := s.retirements[k]
+		out = append(out, &r)
+	}
+
+	return out, nil
+}
+
+// PutMembershipRequest creates or replaces a membership request record
+func (s *Memory) PutMembershipRequest(_ context.Context, r *MembershipRequest) error {
+	if r == nil || r.ID == "" {
+		return ErrBadParameter
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests[requestKey(r.ID)] = *r
+
+	return nil
+}
+
+// DeleteMembershipRequest removes a membership request record
+func (s *Memory) DeleteMembershipRequest(_ context.Context, id string) error {
+	if id == "" {
+		return ErrBadParameter
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.requests, requestKey(id))
+
+	return nil
+}
+
+// ListMembershipRequests returns all the membership request records, sorted by key
+func (s *Memory) ListMembershipRequests(_ context.Context) ([]*MembershipRequest, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]string, 0, len(s.requests))
+	for k := range s.requests {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	out := make([]*MembershipRequest, 0, len(keys))
+
+	for _, k := range keys {
+		r := s.requests[k]
+		out = append(out, &r)
+	}
+
+	return out, nil
+}
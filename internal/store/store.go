@@ -0,0 +1,98 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	mappingPrefix    = "mapping."
+	userGroupPrefix  = "usergroup."
+	retirementPrefix = "retirement."
+	requestPrefix    = "request."
+)
+
+// UserGroupMapping binds a governor group to the slack user group managed for it in a slack team
+// (a workspace, or the organization in org-wide mode)
+type UserGroupMapping struct {
+	GovernorGroupID string    `json:"governor_group_id"`
+	GovernorAppID   string    `json:"governor_app_id"`
+	TeamID          string    `json:"team_id"`
+	UserGroupID     string    `json:"usergroup_id"`
+	Name            string    `json:"name"`
+	Handle          string    `json:"handle"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Retirement records a slack user group retired by the addon when its governor group was unlinked
+// or deleted, since slack doesn't allow deleting user groups
+type Retirement struct {
+	TeamID          string     `json:"team_id"`
+	Workspace       string     `json:"workspace"`
+	UserGroupID     string     `json:"usergroup_id"`
+	GovernorGroupID string     `json:"governor_group_id"`
+	GovernorAppID   string     `json:"governor_app_id"`
+	OriginalName    string     `json:"original_name"`
+	OriginalHandle  string     `json:"original_handle"`
+	RetiredAt       time.Time  `json:"retired_at"`
+	PurgedAt        *time.Time `json:"purged_at,omitempty"`
+}
+
+// MembershipRequest records a governor group membership request filed from slack, so the user can
+// be notified when it's approved or denied
+type MembershipRequest struct {
+	ID              string    `json:"id"`
+	GovernorGroupID string    `json:"governor_group_id"`
+	GovernorUserID  string    `json:"governor_user_id"`
+	SlackUserID     string    `json:"slack_user_id"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// Store persists the addon state
+type Store interface {
+	// GetMapping returns the user group mapping of the governor group in the slack team, or
+	// ErrNotFound if there's none
+	GetMapping(ctx context.Context, groupID, teamID string) (*UserGroupMapping, error)
+	// PutMapping creates or replaces a user group mapping
+	PutMapping(ctx context.Context, m *UserGroupMapping) error
+	// DeleteMapping removes the user group mapping of the governor group in the slack team
+	DeleteMapping(ctx context.Context, groupID, teamID string) error
+	// ListMappings returns all the user group mappings
+	ListMappings(ctx context.Context) ([]*UserGroupMapping, error)
+	// GetMappingByUserGroup returns the mapping of the slack user group, or ErrNotFound if the user
+	// group isn't managed. User group ids are unique across the organization.
+	GetMappingByUserGroup(ctx context.Context, userGroupID string) (*UserGroupMapping, error)
+	// GetRetirement returns the retirement record of the user group in the slack team, or
+	// ErrNotFound if there's none
+	GetRetirement(ctx context.Context, teamID, userGroupID string) (*Retirement, error)
+	// PutRetirement creates or replaces a retirement record
+	PutRetirement(ctx context.Context, r *Retirement) error
+	// ListRetirements returns all the retirement records
+	ListRetirements(ctx context.Context) ([]*Retirement, error)
+	// PutMembershipRequest creates or replaces a membership request record
+	PutMembershipRequest(ctx context.Context, r *MembershipRequest) error
+	// DeleteMembershipRequest removes a membership request record
+	DeleteMembershipRequest(ctx context.Context, id string) error
+	// ListMembershipRequests returns all the membership request records
+	ListMembershipRequests(ctx context.Context) ([]*MembershipRequest, error)
+}
+
+// mappingKey returns the key of the mapping of a governor group in a slack team
+func mappingKey(groupID, teamID string) string {
+	return mappingPrefix + groupID + "." + teamID
+}
+
+// userGroupKey returns the key of the index entry of a slack user group, naming its mapping
+func userGroupKey(userGroupID string) string {
+	return userGroupPrefix + userGroupID
+}
+
+// retirementKey returns the key of the retirement record of a user group in a slack team
+func retirementKey(teamID, userGroupID string) string {
+	return retirementPrefix + teamID + "." + userGroupID
+}
+
+// requestKey returns the key of a membership request record
+func requestKey(id string) string {
+	return requestPrefix + id
+}
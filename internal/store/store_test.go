@@ -0,0 +1,285 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	natsserver "github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+)
+
+var jetstream nats.JetStreamContext
+
+func TestMain(m *testing.M) {
+	natsSrv, err := natsserver.NewServer(&natsserver.Options{
+		Host:      "127.0.0.1",
+		Port:      natsserver.RANDOM_PORT,
+		Debug:     false,
+		JetStream: true,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	defer natsSrv.Shutdown()
+
+	if err := natsserver.Run(natsSrv); err != nil {
+		panic(err)
+	}
+
+	nc, err := nats.Connect(natsSrv.ClientURL())
+	if err != nil {
+		panic(err)
+	}
+
+	jetstream, err = nc.JetStream()
+	if err != nil {
+		panic(err)
+	}
+
+	m.Run()
+}
+
+func testStores(t *testing.T) map[string]Store {
+	t.Helper()
+
+	kv, err := jetstream.CreateKeyValue(&nats.KeyValueConfig{Bucket: "test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+	if err != nil {
+		t.Fatalf("failed to create kv bucket: %v", err)
+	}
+
+	return map[string]Store{
+		"memory":   NewMemory(),
+		"keyvalue": NewKeyValue(kv),
+	}
+}
+
+func TestStore_Mappings(t *testing.T) {
+	ctx := context.TODO()
+
+	for name, s := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			if _, err := s.GetMapping(ctx, "group-1", "T0001"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("expected ErrNotFound, got %v", err)
+			}
+
+			mappings, err := s.ListMappings(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(mappings) != 0 {
+				t.Fatalf("expected no mappings, got %d", len(mappings))
+			}
+
+			if err := s.PutMapping(ctx, &UserGroupMapping{GovernorGroupID: "group-1"}); !errors.Is(err, ErrBadParameter) {
+				t.Fatalf("expected ErrBadParameter, got %v", err)
+			}
+
+			for _, m := range []*UserGroupMapping{
+				{GovernorGroupID: "group-2", TeamID: "T0001", UserGroupID: "S0002", Handle: "group-2"},
+				{GovernorGroupID: "group-1", TeamID: "T0001", UserGroupID: "S0001", Handle: "group-1"},
+			} {
+				if err := s.PutMapping(ctx, m); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			got, err := s.GetMapping(ctx, "group-1", "T0001")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got.UserGroupID != "S0001" || got.Handle != "group-1" {
+				t.Errorf("unexpected mapping %+v", got)
+			}
+
+			mappings, err = s.ListMappings(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(mappings) != 2 || mappings[0].GovernorGroupID != "group-1" {
+				t.Errorf("unexpected mappings %+v", mappings)
+			}
+
+			byUserGroup, err := s.GetMappingByUserGroup(ctx, "S0002")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if byUserGroup.GovernorGroupID != "group-2" {
+				t.Errorf("unexpected mapping %+v", byUserGroup)
+			}
+
+			if _, err := s.GetMappingByUserGroup(ctx, "S0003"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound for an unmanaged user group, got %v", err)
+			}
+
+			// the previous user group isn't found once the mapping points to another one
+			if err := s.PutMapping(ctx, &UserGroupMapping{GovernorGroupID: "group-2", TeamID: "T0001", UserGroupID: "S0004"}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if _, err := s.GetMappingByUserGroup(ctx, "S0002"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound for the previous user group, got %v", err)
+			}
+
+			if err := s.DeleteMapping(ctx, "group-1", "T0001"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := s.DeleteMapping(ctx, "group-1", "T0001"); err != nil {
+				t.Fatalf("unexpected error deleting missing mapping: %v", err)
+			}
+
+			if _, err := s.GetMapping(ctx, "group-1", "T0001"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound, got %v", err)
+			}
+
+			if _, err := s.GetMappingByUserGroup(ctx, "S0001"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound for the deleted mapping, got %v", err)
+			}
+		})
+	}
+}
+
+func TestKeyValue_indexMappings(t *testing.T) {
+	ctx := context.TODO()
+
+	kv, err := jetstream.CreateKeyValue(&nats.KeyValueConfig{Bucket: "test-index-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+	if err != nil {
+		t.Fatalf("failed to create kv bucket: %v", err)
+	}
+
+	s := NewKeyValue(kv)
+
+	// a mapping written before the user group index existed
+	if err := s.put(ctx, mappingKey("group-1", "T0001"), &UserGroupMapping{GovernorGroupID: "group-1", TeamID: "T0001", UserGroupID: "S0001"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := s.GetMappingByUserGroup(ctx, "S0001")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got.GovernorGroupID != "group-1" {
+		t.Errorf("unexpected mapping %+v", got)
+	}
+}
+
+func TestStore_Retirements(t *testing.T) {
+	ctx := context.TODO()
+	retiredAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for name, s := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			if _, err := s.GetRetirement(ctx, "T0001", "S0001"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("expected ErrNotFound, got %v", err)
+			}
+
+			if err := s.PutRetirement(ctx, &Retirement{TeamID: "T0001"}); !errors.Is(err, ErrBadParameter) {
+				t.Fatalf("expected ErrBadParameter, got %v", err)
+			}
+
+			// mappings and retirements don't show up in each other's listings
+			if err := s.PutMapping(ctx, &UserGroupMapping{GovernorGroupID: "group-3", TeamID: "T0001", UserGroupID: "S0003"}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, r := range []*Retirement{
+				{TeamID: "T0001", UserGroupID: "S0002", GovernorGroupID: "group-2", OriginalName: "[Governor] Two", RetiredAt: retiredAt},
+				{TeamID: "T0001", UserGroupID: "S0001", GovernorGroupID: "group-1", OriginalName: "[Governor] One", RetiredAt: retiredAt},
+			} {
+				if err := s.PutRetirement(ctx, r); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			got, err := s.GetRetirement(ctx, "T0001", "S0001")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got.GovernorGroupID != "group-1" || got.OriginalName != "[Governor] One" || !got.RetiredAt.Equal(retiredAt) || got.PurgedAt != nil {
+				t.Errorf("unexpected retirement %+v", got)
+			}
+
+			purgedAt := retiredAt.Add(time.Hour)
+			got.PurgedAt = &purgedAt
+
+			if err := s.PutRetirement(ctx, got); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			retirements, err := s.ListRetirements(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(retirements) != 2 || retirements[0].UserGroupID != "S0001" || retirements[0].PurgedAt == nil || !retirements[0].PurgedAt.Equal(purgedAt) {
+				t.Errorf("unexpected retirements %+v", retirements)
+			}
+
+			mappings, err := s.ListMappings(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(mappings) != 1 {
+				t.Errorf("expected 1 mapping, got %d", len(mappings))
+			}
+		})
+	}
+}
+
+func TestStore_MembershipRequests(t *testing.T) {
+	ctx := context.TODO()
+
+	for name, s := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := s.PutMembershipRequest(ctx, &MembershipRequest{GovernorGroupID: "group-1"}); !errors.Is(err, ErrBadParameter) {
+				t.Fatalf("expected ErrBadParameter, got %v", err)
+			}
+
+			for _, r := range []*MembershipRequest{
+				{ID: "req-2", GovernorGroupID: "group-1", GovernorUserID: "user-2", SlackUserID: "U0002"},
+				{ID: "req-1", GovernorGroupID: "group-1", GovernorUserID: "user-1", SlackUserID: "U0001"},
+			} {
+				if err := s.PutMembershipRequest(ctx, r); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			requests, err := s.ListMembershipRequests(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(requests) != 2 || requests[0].ID != "req-1" || requests[0].SlackUserID != "U0001" {
+				t.Errorf("unexpected requests %+v", requests)
+			}
+
+			if err := s.DeleteMembershipRequest(ctx, "req-1"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := s.DeleteMembershipRequest(ctx, "req-1"); err != nil {
+				t.Fatalf("unexpected error deleting missing request: %v", err)
+			}
+
+			requests, err = s.ListMembershipRequests(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(requests) != 1 || requests[0].ID != "req-2" {
+				t.Errorf("unexpected requests %+v", requests)
+			}
+		})
+	}
+}
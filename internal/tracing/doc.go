@@ -0,0 +1,3 @@
+// Package tracing links the opentelemetry traces of the addon to its log lines and records the
+// outcome of the operations on their spans
+package tracing
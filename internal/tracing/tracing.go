@@ -0,0 +1,51 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// log field keys of the trace of a log line, they match the audit event metadata keys
+const (
+	// TraceIDKey is the id of the trace the log line was written in
+	TraceIDKey = "trace_id"
+	// SpanIDKey is the id of the span the log line was written in
+	SpanIDKey = "span_id"
+)
+
+// LogFields returns the log fields linking a log line to the trace of the context, there's none if
+// the context isn't traced
+func LogFields(ctx context.Context) []zap.Field {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+
+	return []zap.Field{
+		zap.String(TraceIDKey, sc.TraceID().String()),
+		zap.String(SpanIDKey, sc.SpanID().String()),
+	}
+}
+
+// Logger returns the logger with the fields of the trace of the context
+func Logger(ctx context.Context, l *zap.Logger) *zap.Logger {
+	fields := LogFields(ctx)
+	if len(fields) == 0 {
+		return l
+	}
+
+	return l.With(fields...)
+}
+
+// End records the error of the operation on the span, if any, and ends the span
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
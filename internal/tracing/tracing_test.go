@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogger(t *testing.T) {
+	if fields := LogFields(context.Background()); len(fields) != 0 {
+		t.Errorf("expected no log fields for an untraced context, got %v", fields)
+	}
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x01},
+		SpanID:  trace.SpanID{0x02},
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	core, logs := observer.New(zapcore.DebugLevel)
+
+	Logger(ctx, zap.New(core)).Info("traced")
+	Logger(context.Background(), zap.New(core)).Info("untraced")
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 log entries, got %d", len(entries))
+	}
+
+	traced := entries[0].ContextMap()
+
+	if traced[TraceIDKey] != sc.TraceID().String() {
+		t.Errorf("expected trace id %s, got %v", sc.TraceID(), traced[TraceIDKey])
+	}
+
+	if traced[SpanIDKey] != sc.SpanID().String() {
+		t.Errorf("expected span id %s, got %v", sc.SpanID(), traced[SpanIDKey])
+	}
+
+	if _, ok := entries[1].ContextMap()[TraceIDKey]; ok {
+		t.Error("expected no trace id for an untraced context")
+	}
+}